@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/csv"
 	"fmt"
 	"github.com/incognitochain/bridge-eth/common/base58"
 	"github.com/incognitochain/go-incognito-sdk-v2/common"
@@ -10,36 +9,64 @@ import (
 	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
 	"github.com/urfave/cli/v2"
 	"log"
-	"math"
-	"os"
-	"sort"
-	"strings"
-	"time"
 )
 
 func checkBalance(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	keySet, err := getWatchOnlyKeySet(c)
+	if err != nil {
+		return err
+	}
+	if keySet != nil {
+		return checkWatchOnlyBalance(c, keySet)
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
-	balance, err := cfg.incClient.GetBalance(privateKey, tokenIDStr)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	var balance uint64
+	if ok, err := tryDaemon("balance", newAccountParams(privateKey, tokenIDStr), &balance); ok {
+		if err != nil {
+			return err
+		}
+		return printBalance(balance, tokenIDStr)
+	}
+
+	balance, err = cfg.incClient.GetBalance(privateKey, tokenIDStr)
 	if err != nil {
 		return newAppError(GetBalanceError, err)
 	}
 
-	return jsonPrintWithKey("Balance", balance)
+	return printBalance(balance, tokenIDStr)
+}
+
+// printBalance prints the balance of a token, both in the smallest unit of the token and with its decimals.
+func printBalance(balance uint64, tokenIDStr string) error {
+	return printResult(map[string]interface{}{
+		"Balance": balance,
+		"Amount":  formatAmount(balance, getTokenDecimals(tokenIDStr)),
+		"Symbol":  getTokenSymbol(tokenIDStr),
+	})
 }
 
 func getAllBalanceV2(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	keySet, err := getWatchOnlyKeySet(c)
+	if err != nil {
+		return err
+	}
+	if keySet != nil {
+		return getWatchOnlyAllBalances(keySet)
+	}
+
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	balances, err := cfg.incClient.GetAllBalancesV2(privateKey)
@@ -47,13 +74,13 @@ func getAllBalanceV2(c *cli.Context) error {
 		return newAppError(GetAllBalancesError, err)
 	}
 
-	return jsonPrint(balances)
+	return printResult(balances)
 }
 
 func keyInfo(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	info, err := incclient.GetAccountInfoFromPrivateKey(privateKey)
@@ -61,18 +88,18 @@ func keyInfo(c *cli.Context) error {
 		return newAppError(GetAccountInfoError, err)
 	}
 
-	return jsonPrint(info)
+	return printResult(info)
 }
 
 func consolidateUTXOs(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	version := c.Int(versionFlag)
@@ -85,58 +112,20 @@ func consolidateUTXOs(c *cli.Context) error {
 		return newAppError(NumThreadsError)
 	}
 
-	fmt.Printf("CONSOLIDATING tokenID %v, version %v, numThreads %v\n", tokenIDStr, version, numThreads)
-
-	txList, err := cfg.incClient.Consolidate(privateKey, tokenIDStr, int8(version), numThreads)
+	fee, err := getCustomTxFee(c)
 	if err != nil {
-		return newAppError(ConsolidateAccountError, err)
-	}
-	fmt.Println("CONSOLIDATING FINISHED!!")
-
-	return jsonPrintWithKey("TxList", txList)
-}
-
-func checkUTXOs(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+		return err
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
-	}
+	log.Printf("CONSOLIDATING tokenID %v, version %v, numThreads %v\n", tokenIDStr, version, numThreads)
 
-	unSpentCoins, idxList, err := cfg.incClient.GetUnspentOutputCoins(privateKey, tokenIDStr, 0)
+	txList, err := runConsolidation(privateKey, tokenIDStr, int8(version), numThreads, fee)
 	if err != nil {
-		return newAppError(GetUnspentOutputCoinsError, err)
-	}
-
-	numUTXOsV1 := 0
-	numUTXOsV2 := 0
-	balanceV1 := uint64(0)
-	balanceV2 := uint64(0)
-
-	for i, utxo := range unSpentCoins {
-		if utxo.GetVersion() == 1 {
-			numUTXOsV1++
-			balanceV1 += utxo.GetValue()
-		} else {
-			numUTXOsV2++
-			balanceV2 += utxo.GetValue()
-		}
-
-		fmt.Printf("idx %v, version %v, pubKey %v, keyImage %v, value %v\n",
-			idxList[i].Uint64(), utxo.GetVersion(),
-			base58.Base58Check{}.Encode(utxo.GetPublicKey().ToBytesS(), 0),
-			base58.Base58Check{}.Encode(utxo.GetKeyImage().ToBytesS(), 0),
-			utxo.GetValue())
+		return newAppError(ConsolidateAccountError, err)
 	}
+	log.Println("CONSOLIDATING FINISHED!!")
 
-	fmt.Printf("#numUTXOsV1 %v, #numUTXOsV2 %v\n", numUTXOsV1, numUTXOsV2)
-	fmt.Printf("balanceV1 %v, balanceV2 %v, totalBalance %v\n", balanceV1, balanceV2, balanceV1+balanceV2)
-
-	return nil
+	return printTxListResult(txList)
 }
 
 func getOutCoins(c *cli.Context) error {
@@ -155,9 +144,9 @@ func getOutCoins(c *cli.Context) error {
 		return newAppError(InvalidReadonlyKeyError)
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	outCoinKey := new(rpc.OutCoinKey)
@@ -170,85 +159,56 @@ func getOutCoins(c *cli.Context) error {
 		return newAppError(GetOutputCoinsError, err)
 	}
 
-	v1Count := 0
-	v2Count := 0
+	type outCoinInfo struct {
+		Index      int64
+		Version    int8
+		Encrypted  bool
+		PubKey     string
+		Commitment string
+	}
+	res := struct {
+		OutCoins    []outCoinInfo
+		NumOutCoins int
+		NumV1       int
+		NumV2       int
+	}{OutCoins: make([]outCoinInfo, 0), NumOutCoins: len(outCoins)}
+
 	for i, outCoin := range outCoins {
 		if outCoin.GetVersion() == 1 {
-			v1Count += 1
+			res.NumV1 += 1
 		} else {
-			v2Count += 1
+			res.NumV2 += 1
 		}
 
-		fmt.Printf("idx %v, ver %v, encrypted %v, pubKey %v, cmtStr %v\n",
-			idxList[i].Int64(),
-			outCoin.GetVersion(),
-			outCoin.IsEncrypted(),
-			base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), 0x00),
-			base58.Base58Check{}.Encode(outCoin.GetCommitment().ToBytesS(), 0x00))
+		res.OutCoins = append(res.OutCoins, outCoinInfo{
+			Index:      idxList[i].Int64(),
+			Version:    int8(outCoin.GetVersion()),
+			Encrypted:  outCoin.IsEncrypted(),
+			PubKey:     base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), 0x00),
+			Commitment: base58.Base58Check{}.Encode(outCoin.GetCommitment().ToBytesS(), 0x00),
+		})
 	}
 
-	fmt.Printf("#OutCoins: %v, #v1: %v, #v2: %v\n", len(outCoins), v1Count, v2Count)
-
-	return nil
+	return printResult(res)
 }
 
 func getHistory(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	keySet, err := getWatchOnlyKeySet(c)
+	if err != nil {
+		return err
 	}
-
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	if keySet != nil {
+		return getWatchOnlyHistory(c, keySet)
 	}
 
-	numThreads := c.Int(numThreadsFlag)
-	if numThreads == 0 {
-		return newAppError(NumThreadsError)
-	}
-
-	csvFile := c.String("csvFile")
-
-	historyProcessor := incclient.NewTxHistoryProcessor(cfg.incClient, numThreads)
-
-	h, err := historyProcessor.GetTokenHistory(privateKey, tokenIDStr)
+	privateKey, err := getPrivateKey(c)
 	if err != nil {
-		return newAppError(GetHistoryError, err)
+		return err
 	}
 
-	if len(csvFile) > 0 {
-		err = incclient.SaveTxHistory(h, csvFile)
-		if err != nil {
-			return newAppError(SaveHistoryError, err)
-		}
-	} else {
-		totalIn := uint64(0)
-		fmt.Printf("#TxIns %v\n", len(h.TxInList))
-		for _, txIn := range h.TxInList {
-			totalIn += txIn.GetAmount()
-			fmt.Println(txIn.String())
-		}
-		fmt.Printf("END TxIns\n\n")
-
-		totalOut := uint64(0)
-		fmt.Printf("#TxOuts %v\n", len(h.TxOutList))
-		for _, txOut := range h.TxOutList {
-			totalOut += txOut.GetAmount()
-			fmt.Println(txOut.String())
-		}
-		fmt.Printf("END TxOuts\n")
-
-		fmt.Printf("TotalIn: %v, TotalOut: %v\n", totalIn, totalOut)
-	}
-
-	return nil
-}
-
-func financialExport(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	numThreads := c.Int(numThreadsFlag)
@@ -256,135 +216,68 @@ func financialExport(c *cli.Context) error {
 		return newAppError(NumThreadsError)
 	}
 
-	csvFile := c.String(csvFileFlag)
-	if len(csvFile) == 0 {
-		csvFile = incclient.DefaultTxHistory
+	r, err := getHistoryRange(c)
+	if err != nil {
+		return newAppError(InvalidDateRangeError, err)
 	}
 
-	historyProcessor := incclient.NewTxHistoryProcessor(cfg.incClient, numThreads)
+	csvFile := c.String("csvFile")
 
-	historyMap, err := historyProcessor.GetAllHistory(privateKey)
+	h, err := getTokenHistory(privateKey, tokenIDStr, numThreads, r, c.Bool(resyncFlag))
 	if err != nil {
-		return newAppError(GetHistoryError, err)
+		return err
 	}
 
-	history := new(incclient.TxHistory)
-	history.TxInList = make([]incclient.TxIn, 0)
-	history.TxOutList = make([]incclient.TxOut, 0)
-	for tokenID, h := range historyMap {
-		if tokenID == common.ConfidentialAssetID.String() || tokenID == common.PRVIDStr {
-			continue
+	if len(csvFile) > 0 {
+		err = incclient.SaveTxHistory(h, csvFile)
+		if err != nil {
+			return newAppError(SaveHistoryError, err)
 		}
 
-		history.TxInList = append(history.TxInList, h.TxInList...)
-		history.TxOutList = append(history.TxOutList, h.TxOutList...)
-	}
-
-	if historyMap[common.PRVIDStr] != nil {
-		history.TxInList = append(history.TxInList, historyMap[common.PRVIDStr].TxInList...)
-
+		return nil
 	}
 
-	for _, txOut := range historyMap[common.PRVIDStr].TxOutList {
-		if txOut.Amount == 0 {
-			continue
-		}
-		history.TxOutList = append(history.TxOutList, txOut)
+	totalIn := uint64(0)
+	for _, txIn := range h.TxInList {
+		totalIn += txIn.GetAmount()
 	}
-
-	//fmt.Println(historyMap[common.PRVIDStr].TxOutList)
-
-	sort.Slice(history.TxInList, func(i, j int) bool {
-		return history.TxInList[i].LockTime > history.TxInList[j].LockTime
-	})
-	sort.Slice(history.TxOutList, func(i, j int) bool {
-		return history.TxOutList[i].LockTime > history.TxOutList[j].LockTime
-	})
-
-	f, err := os.OpenFile(csvFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return newAppError(UnexpectedError, fmt.Errorf("cannot open file %v: %v", csvFile, err))
+	totalOut := uint64(0)
+	for _, txOut := range h.TxOutList {
+		totalOut += txOut.GetAmount()
 	}
-	defer func() {
-		err := f.Close()
-		if err != nil {
-			fmt.Println(err)
-		}
-	}()
-
-	w := csv.NewWriter(f)
-	defer w.Flush()
-
-	_ = f.Truncate(0)
 
-	log.Println("Building up the history file...")
-
-	var dateTimeFormat = "2006/01/02 15:04:05"
-	historyPattern := []string{"Date", "TxHash", "Received Quantity", "Received Currency", "Sent Quantity", "Sent Currency", "Fee Amount", "Fee Currency", "Tag"}
-	err = w.Write(historyPattern)
-	if err != nil {
-		return newAppError(SaveHistoryError, err)
-	}
+	return printResult(struct {
+		TxIns    []incclient.TxIn
+		TxOuts   []incclient.TxOut
+		TotalIn  uint64
+		TotalOut uint64
+	}{h.TxInList, h.TxOutList, totalIn, totalOut})
+}
 
-	writtenData := make(map[string]bool)
-	for _, txIn := range history.TxInList {
-		if writtenData[common.HashH([]byte(txIn.String())).String()] {
-			continue
-		} else {
-			writtenData[common.HashH([]byte(txIn.String())).String()] = true
-		}
-		toBeWritten := make([]string, 0)
-		toBeWritten = append(toBeWritten, time.Unix(txIn.GetLockTime(), 0).Format(dateTimeFormat))
-		toBeWritten = append(toBeWritten, txIn.TxHash)
-		toBeWritten = append(toBeWritten, fmt.Sprintf("%v", float64(txIn.Amount)/math.Pow10(getTokenDecimals(txIn.TokenID))))
-		toBeWritten = append(toBeWritten, getTokenName(txIn.TokenID))
-		toBeWritten = append(toBeWritten, "")
-		toBeWritten = append(toBeWritten, "")
-		toBeWritten = append(toBeWritten, "")
-		toBeWritten = append(toBeWritten, "")
-		toBeWritten = append(toBeWritten, txIn.Note)
-
-		err = w.Write(toBeWritten)
-		if err != nil {
-			return newAppError(SaveHistoryError, fmt.Errorf("write txHash %v error: %v", txIn.GetTxHash(), err))
+// getTokenHistory returns the history of a private key w.r.t a tokenID within a range, from the daemon if the account
+// is registered to it, or from the local history store after syncing it.
+func getTokenHistory(privateKey, tokenIDStr string, numThreads int, r historyRange,
+	resync bool) (*incclient.TxHistory, error) {
+	if !resync {
+		var daemonHistory rawTxHistory
+		if ok, err := tryDaemon("history", newAccountParams(privateKey, tokenIDStr), &daemonHistory); ok {
+			if err != nil {
+				return nil, err
+			}
+			h, err := daemonHistory.toTxHistory()
+			if err != nil {
+				return nil, newAppError(DaemonError, err)
+			}
+			return filterTxHistory(h, r), nil
 		}
 	}
 
-	for _, txOut := range history.TxOutList {
-		if writtenData[common.HashH([]byte(txOut.String())).String()] {
-			continue
-		} else {
-			writtenData[common.HashH([]byte(txOut.String())).String()] = true
-		}
-		fee := txOut.PRVFee
-		tokenFee := common.PRVIDStr
-		if fee == 0 {
-			fee = txOut.TokenFee
-			tokenFee = txOut.TokenID
-		}
-		if fee == 0 {
-			tokenFee = ""
-		}
-
-		toBeWritten := make([]string, 0)
-		toBeWritten = append(toBeWritten, time.Unix(txOut.GetLockTime(), 0).Format(dateTimeFormat))
-		toBeWritten = append(toBeWritten, txOut.TxHash)
-		toBeWritten = append(toBeWritten, "")
-		toBeWritten = append(toBeWritten, "")
-		toBeWritten = append(toBeWritten, fmt.Sprintf("%v", float64(txOut.Amount)/math.Pow10(getTokenDecimals(txOut.TokenID))))
-		toBeWritten = append(toBeWritten, getTokenName(txOut.TokenID))
-		toBeWritten = append(toBeWritten, fmt.Sprintf("%v", float64(fee)/math.Pow10(getTokenDecimals(tokenFee))))
-		toBeWritten = append(toBeWritten, getTokenName(tokenFee))
-		toBeWritten = append(toBeWritten, txOut.Note)
-
-		err = w.Write(toBeWritten)
-		if err != nil {
-			return newAppError(SaveHistoryError, fmt.Errorf("write txHash %v error: %v", txOut.GetTxHash(), err))
-		}
+	histories, err := getSyncedHistories(privateKey, []string{tokenIDStr}, numThreads, r, resync)
+	if err != nil {
+		return nil, newAppError(GetHistoryError, err)
 	}
-	log.Printf("Report written to file `%v`\n", csvFile)
 
-	return nil
+	return histories[tokenIDStr], nil
 }
 
 type accountInfo struct {
@@ -398,9 +291,9 @@ type masterKeyInfo struct {
 }
 
 func genKeySet(c *cli.Context) error {
-	w, mnemonic, err := wallet.NewMasterKey()
+	w, mnemonic, err := newMasterKey(c)
 	if err != nil {
-		return newAppError(GenerateMasterKeyError, err)
+		return err
 	}
 
 	numShards := c.Int(numShardsFlag)
@@ -450,15 +343,13 @@ func genKeySet(c *cli.Context) error {
 
 		index++
 	}
-	return jsonPrint(masterKeyInfo{Mnemonic: mnemonic, Accounts: accounts})
+	return printResult(masterKeyInfo{Mnemonic: mnemonic, Accounts: accounts})
 }
 
 func importMnemonic(c *cli.Context) error {
-	mnemonic := c.String(mnemonicFlag)
-	mnemonic = strings.Replace(mnemonic, "-", " ", -1)
-	w, err := wallet.NewMasterKeyFromMnemonic(mnemonic)
+	w, err := getMasterKey(c)
 	if err != nil {
-		return newAppError(ImportMnemonicError)
+		return err
 	}
 
 	numShards := c.Int(numShardsFlag)
@@ -508,7 +399,7 @@ func importMnemonic(c *cli.Context) error {
 
 		index++
 	}
-	return jsonPrint(accounts)
+	return printResult(accounts)
 }
 
 func submitKey(c *cli.Context) error {
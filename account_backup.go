@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// backupShareVersion is the base58 version byte of a backup share.
+	backupShareVersion = 0x01
+
+	// backupDigestLen is the length of the digest appended to the secret before splitting, to detect a wrong
+	// reconstruction.
+	backupDigestLen = 4
+
+	// backupShareHeaderLen is the length of the header of a share: setID (2 bytes), threshold and x-coordinate.
+	backupShareHeaderLen = 4
+)
+
+// gfExp and gfLog are the exponent and logarithm tables of GF(2^8) with the AES polynomial x^8 + x^4 + x^3 + x + 1
+// and the generator 3.
+var gfExp, gfLog = func() ([510]byte, [256]byte) {
+	var exp [510]byte
+	var log [256]byte
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+		// multiply by the generator 3 = x + 1
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	return exp, log
+}()
+
+// gfMul multiplies two elements of GF(2^8).
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv divides a by a non-zero b in GF(2^8).
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// backupShare is a share of a secret split with the Shamir's secret sharing scheme.
+type backupShare struct {
+	SetID     uint16
+	Threshold byte
+	X         byte
+	Y         []byte
+}
+
+// encode serializes a share into a base58-check string.
+func (s *backupShare) encode() string {
+	data := []byte{byte(s.SetID >> 8), byte(s.SetID), s.Threshold, s.X}
+	data = append(data, s.Y...)
+
+	return base58.Base58Check{}.Encode(data, backupShareVersion)
+}
+
+// decodeBackupShare parses a share encoded by backupShare.encode, checking its checksum.
+func decodeBackupShare(encoded string) (*backupShare, error) {
+	data, version, err := base58.Base58Check{}.Decode(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("share %v is corrupted: %v", encoded, err)
+	}
+	if version != backupShareVersion || len(data) <= backupShareHeaderLen {
+		return nil, fmt.Errorf("%v is not a backup share", encoded)
+	}
+
+	return &backupShare{
+		SetID:     uint16(data[0])<<8 | uint16(data[1]),
+		Threshold: data[2],
+		X:         data[3],
+		Y:         data[backupShareHeaderLen:],
+	}, nil
+}
+
+// splitSecret splits a secret into n shares, any threshold of which can reconstruct it. A digest of the secret is
+// shared along with it so that a wrong reconstruction is detected.
+func splitSecret(secret []byte, threshold, n int) ([]*backupShare, error) {
+	if threshold < 1 || threshold > n || n > 255 {
+		return nil, fmt.Errorf("expected 1 <= threshold <= shares <= 255, got threshold %v and %v shares", threshold, n)
+	}
+
+	digest := sha256.Sum256(secret)
+	payload := append(append([]byte{}, secret...), digest[:backupDigestLen]...)
+
+	setID := make([]byte, 2)
+	if _, err := rand.Read(setID); err != nil {
+		return nil, err
+	}
+	shares := make([]*backupShare, n)
+	for i := range shares {
+		shares[i] = &backupShare{
+			SetID:     uint16(setID[0])<<8 | uint16(setID[1]),
+			Threshold: byte(threshold),
+			X:         byte(i + 1),
+			Y:         make([]byte, len(payload)),
+		}
+	}
+
+	// each byte of the payload is the constant term of a random polynomial of degree threshold-1
+	coefficients := make([]byte, threshold)
+	for j, b := range payload {
+		coefficients[0] = b
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			// Horner's method
+			var y byte
+			for k := threshold - 1; k >= 0; k-- {
+				y = gfMul(y, share.X) ^ coefficients[k]
+			}
+			share.Y[j] = y
+		}
+	}
+
+	return shares, nil
+}
+
+// combineShares reconstructs a secret from its shares, and checks the result against the digest shared with it.
+func combineShares(shares []*backupShare) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, fmt.Errorf("no share given")
+	}
+
+	first := shares[0]
+	seen := make(map[byte]bool)
+	for _, share := range shares {
+		if share.SetID != first.SetID {
+			return nil, fmt.Errorf("share #%v belongs to another backup", share.X)
+		}
+		if share.Threshold != first.Threshold || len(share.Y) != len(first.Y) || share.X == 0 {
+			return nil, fmt.Errorf("share #%v is inconsistent with the others", share.X)
+		}
+		if seen[share.X] {
+			return nil, fmt.Errorf("share #%v is given twice", share.X)
+		}
+		seen[share.X] = true
+	}
+	if len(shares) < int(first.Threshold) {
+		return nil, fmt.Errorf("expected at least %v shares, got %v", first.Threshold, len(shares))
+	}
+	shares = shares[:first.Threshold]
+
+	// Lagrange interpolation at x = 0
+	payload := make([]byte, len(first.Y))
+	for i, si := range shares {
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = gfMul(basis, gfDiv(sj.X, sj.X^si.X))
+			}
+		}
+		for k := range payload {
+			payload[k] ^= gfMul(basis, si.Y[k])
+		}
+	}
+
+	if len(payload) <= backupDigestLen {
+		return nil, fmt.Errorf("invalid share length")
+	}
+	secret := payload[:len(payload)-backupDigestLen]
+	digest := sha256.Sum256(secret)
+	if !bytes.Equal(digest[:backupDigestLen], payload[len(payload)-backupDigestLen:]) {
+		return nil, fmt.Errorf("the reconstructed secret does not match its digest: a share is wrong")
+	}
+
+	return secret, nil
+}
+
+// backupSplit splits the entropy of a mnemonic into shares.
+func backupSplit(c *cli.Context) error {
+	mnemonic := normalizeMnemonic(c.String(mnemonicFlag))
+	if err := validateMnemonic(mnemonic); err != nil {
+		return newAppError(InvalidMnemonicError, err)
+	}
+	entropy, err := bip39.EntropyFromMnemonic(mnemonic)
+	if err != nil {
+		return newAppError(InvalidMnemonicError, err)
+	}
+
+	shares, err := splitSecret(entropy, c.Int(thresholdFlag), c.Int(numSharesFlag))
+	if err != nil {
+		return newAppError(UserInputError, err)
+	}
+
+	encodedShares := make([]string, 0)
+	for _, share := range shares {
+		encodedShares = append(encodedShares, share.encode())
+	}
+	return printResult(map[string]interface{}{
+		"Threshold": c.Int(thresholdFlag),
+		"Shares":    encodedShares,
+	})
+}
+
+// backupCombine reconstructs a mnemonic from its shares.
+func backupCombine(c *cli.Context) error {
+	shares := make([]*backupShare, 0)
+	for _, encoded := range c.StringSlice(shareFlag) {
+		share, err := decodeBackupShare(encoded)
+		if err != nil {
+			return newAppError(InvalidBackupShareError, err)
+		}
+		shares = append(shares, share)
+	}
+
+	entropy, err := combineShares(shares)
+	if err != nil {
+		return newAppError(InvalidBackupShareError, err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return newAppError(InvalidBackupShareError, err)
+	}
+
+	// print the first account so that the result can be compared with the expected wallet
+	w, err := newMasterKeyFromMnemonic(mnemonic, c.String(bip39PassphraseFlag))
+	if err != nil {
+		return err
+	}
+	childKey, err := w.DeriveChild(1)
+	if err != nil {
+		return newAppError(DeriveChildError, err)
+	}
+	privateKey := childKey.Base58CheckSerialize(wallet.PrivateKeyType)
+
+	return printResult(map[string]interface{}{
+		"Mnemonic":            mnemonic,
+		"FirstPaymentAddress": incclient.PrivateKeyToPaymentAddress(privateKey, -1),
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitCombineSecret(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	shares, err := splitSecret(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// every subset of 3 shares reconstructs the secret
+	for i := 0; i < 5; i++ {
+		for j := i + 1; j < 5; j++ {
+			for k := j + 1; k < 5; k++ {
+				res, err := combineShares([]*backupShare{shares[k], shares[i], shares[j]})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(res, secret) {
+					t.Fatalf("shares %v, %v, %v: expect %x, got %x", i, j, k, secret, res)
+				}
+			}
+		}
+	}
+
+	// encoding round trip
+	decoded, err := decodeBackupShare(shares[0].encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.SetID != shares[0].SetID || decoded.X != shares[0].X || !bytes.Equal(decoded.Y, shares[0].Y) {
+		t.Fatalf("expect %v, got %v", shares[0], decoded)
+	}
+
+	if _, err = combineShares(shares[:2]); err == nil {
+		t.Fatalf("expect an error with fewer shares than the threshold")
+	}
+
+	// a wrong share is detected
+	wrong := *shares[1]
+	wrong.Y = append([]byte{}, wrong.Y...)
+	wrong.Y[0] ^= 1
+	if _, err = combineShares([]*backupShare{shares[0], &wrong, shares[2]}); err == nil {
+		t.Fatalf("expect an error with a wrong share")
+	}
+
+	// shares of another backup are detected
+	others, err := splitSecret(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	others[1].SetID = shares[0].SetID + 1
+	if _, err = combineShares([]*backupShare{shares[0], others[1], shares[2]}); err == nil {
+		t.Fatalf("expect an error with a share of another backup")
+	}
+
+	// a corrupted encoded share is detected
+	encoded := []byte(shares[0].encode())
+	if encoded[5] == 'a' {
+		encoded[5] = 'b'
+	} else {
+		encoded[5] = 'a'
+	}
+	if _, err = decodeBackupShare(string(encoded)); err == nil {
+		t.Fatalf("expect an error with a corrupted share")
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// keyStatusNotSubmitted and keyStatusIndexing are the OTA key submission statuses returned by the full-node.
+	keyStatusNotSubmitted = 0
+	keyStatusIndexing     = 1
+
+	// indexingCheckInterval is the delay between two checks of an OTA key being indexed by the full-node.
+	indexingCheckInterval = 10 * time.Second
+)
+
+// discoveredAccount is an account found by the discovery, with the number of output coins it has received.
+type discoveredAccount struct {
+	accountInfo
+	NumPRVOutCoins   int
+	NumTokenOutCoins int
+}
+
+// discoverAccounts walks the derivation indexes of a mnemonic and reports every index that has ever received coins.
+// The scan stops after gapLimit consecutive indexes without any output coin.
+func discoverAccounts(c *cli.Context) error {
+	w, err := getMasterKey(c)
+	if err != nil {
+		return err
+	}
+
+	numShards := c.Int(numShardsFlag)
+	if numShards == 0 {
+		return newAppError(InvalidNumberShardsError)
+	}
+	common.MaxShardNumber = numShards
+
+	gapLimit := c.Int(gapLimitFlag)
+	if gapLimit <= 0 {
+		return newAppError(UserInputError, fmt.Errorf("gapLimit must be positive"))
+	}
+	accessToken := c.String(accessTokenFlag)
+	indexingTimeout := c.Duration(indexingTimeoutFlag)
+	if indexingTimeout <= 0 {
+		return newAppError(UserInputError, fmt.Errorf("indexingTimeout must be positive"))
+	}
+
+	accounts := make([]*discoveredAccount, 0)
+	gap := 0
+	index := 1
+	for ; gap < gapLimit; index++ {
+		childKey, err := w.DeriveChild(uint32(index))
+		if err != nil {
+			return newAppError(DeriveChildError, err)
+		}
+		privateKey := childKey.Base58CheckSerialize(wallet.PrivateKeyType)
+		info, err := incclient.GetAccountInfoFromPrivateKey(privateKey)
+		if err != nil {
+			return newAppError(GetAccountInfoError, err)
+		}
+
+		err = submitKeyForDiscovery(info.OTAPrivateKey, accessToken, indexingTimeout)
+		if err != nil {
+			if isAppError(err, KeyIndexingTimeoutError) {
+				log.Printf("Index %v: run the command again once the full-node has indexed the OTA key\n", index)
+				return err
+			}
+			return newAppError(SubmitKeyError, fmt.Errorf("index %v: %v", index, err))
+		}
+
+		outCoinKey, err := incclient.NewOutCoinKeyFromPrivateKey(privateKey)
+		if err != nil {
+			return newAppError(GetAccountInfoError, err)
+		}
+		prvCoins, _, err := cfg.incClient.GetOutputCoins(outCoinKey, common.PRVIDStr, 0)
+		if err != nil {
+			return newAppError(GetOutputCoinsError, fmt.Errorf("index %v: %v", index, err))
+		}
+		tokenCoins, _, err := cfg.incClient.GetOutputCoins(outCoinKey, common.ConfidentialAssetID.String(), 0)
+		if err != nil {
+			return newAppError(GetOutputCoinsError, fmt.Errorf("index %v: %v", index, err))
+		}
+
+		if len(prvCoins) == 0 && len(tokenCoins) == 0 {
+			gap++
+			log.Printf("Index %v: unused (%v/%v)\n", index, gap, gapLimit)
+			continue
+		}
+		log.Printf("Index %v: %v PRV and %v token output coins\n", index, len(prvCoins), len(tokenCoins))
+		accounts = append(accounts, &discoveredAccount{
+			accountInfo:      accountInfo{Index: index, KeyInfo: info},
+			NumPRVOutCoins:   len(prvCoins),
+			NumTokenOutCoins: len(tokenCoins),
+		})
+		gap = 0
+	}
+
+	return printResult(struct {
+		LastScannedIndex int
+		Accounts         []*discoveredAccount
+	}{index - 1, accounts})
+}
+
+// submitKeyForDiscovery submits an OTA key to the full-node if it has not been submitted yet, and waits until the
+// full-node has finished indexing it so that all of its past output coins can be retrieved. It returns a
+// KeyIndexingTimeoutError if the key is still being indexed after the timeout.
+func submitKeyForDiscovery(otaKey, accessToken string, timeout time.Duration) error {
+	status, err := cfg.incClient.GetKeySubmissionStatus(otaKey)
+	if err != nil {
+		return err
+	}
+	if status == keyStatusNotSubmitted {
+		if accessToken != "" {
+			err = cfg.incClient.AuthorizedSubmitKey(otaKey, accessToken, 0, false)
+		} else {
+			err = cfg.incClient.SubmitKey(otaKey)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	start := time.Now()
+	for {
+		status, err = cfg.incClient.GetKeySubmissionStatus(otaKey)
+		if err != nil {
+			return err
+		}
+		if status != keyStatusIndexing {
+			return nil
+		}
+		if time.Since(start) > timeout {
+			return newAppError(KeyIndexingTimeoutError, fmt.Errorf("the OTA key is still being indexed after %v", timeout))
+		}
+		log.Println("Waiting for the full-node to index the OTA key...")
+		time.Sleep(indexingCheckInterval)
+	}
+}
@@ -0,0 +1,828 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
+	metadataPdexv3 "github.com/incognitochain/go-incognito-sdk-v2/metadata/pdexv3"
+	"github.com/urfave/cli/v2"
+)
+
+// Profiles (i.e, file layouts) of the financial export.
+const (
+	defaultExportProfile = "default"
+	koinlyProfile        = "koinly"
+	coinTrackingProfile  = "cointracking"
+	beancountProfile     = "beancount"
+	jsonExportProfile    = "json"
+)
+
+// exportProfiles lists the supported values of the exportProfile flag.
+var exportProfiles = []string{defaultExportProfile, koinlyProfile, coinTrackingProfile, beancountProfile, jsonExportProfile}
+
+// Types of the exported transactions.
+const (
+	receiveTx    = "receive"
+	sendTx       = "send"
+	tradeTx      = "trade"
+	refundTx     = "refund"
+	shieldTx     = "shield"
+	unshieldTx   = "unshield"
+	liquidityTx  = "liquidity"
+	stakingTx    = "staking"
+	rewardTx     = "reward"
+	conversionTx = "conversion"
+	feeTx        = "fee"
+)
+
+// exportTxTypes classifies the transactions by their metadata type. Transactions not listed are plain transfers.
+var exportTxTypes = map[int]string{
+	metadata.PDETradeRequestMeta:                   tradeTx,
+	metadata.PDETradeResponseMeta:                  tradeTx,
+	metadata.PDECrossPoolTradeRequestMeta:          tradeTx,
+	metadata.PDECrossPoolTradeResponseMeta:         tradeTx,
+	metadataCommon.Pdexv3TradeRequestMeta:          tradeTx,
+	metadataCommon.Pdexv3TradeResponseMeta:         tradeTx,
+	metadataCommon.Pdexv3AddOrderRequestMeta:       tradeTx,
+	metadataCommon.Pdexv3AddOrderResponseMeta:      tradeTx,
+	metadataCommon.Pdexv3WithdrawOrderRequestMeta:  tradeTx,
+	metadataCommon.Pdexv3WithdrawOrderResponseMeta: tradeTx,
+
+	metadata.IssuingRequestMeta:                           shieldTx,
+	metadata.IssuingResponseMeta:                          shieldTx,
+	metadata.IssuingETHRequestMeta:                        shieldTx,
+	metadata.IssuingETHResponseMeta:                       shieldTx,
+	metadata.IssuingBSCRequestMeta:                        shieldTx,
+	metadata.IssuingBSCResponseMeta:                       shieldTx,
+	metadata.IssuingPLGRequestMeta:                        shieldTx,
+	metadata.IssuingPLGResponseMeta:                       shieldTx,
+	metadataCommon.IssuingFantomRequestMeta:               shieldTx,
+	metadataCommon.IssuingFantomResponseMeta:              shieldTx,
+	metadata.IssuingPRVERC20RequestMeta:                   shieldTx,
+	metadata.IssuingPRVERC20ResponseMeta:                  shieldTx,
+	metadata.IssuingPRVBEP20RequestMeta:                   shieldTx,
+	metadata.IssuingPRVBEP20ResponseMeta:                  shieldTx,
+	metadata.PortalV4ShieldingRequestMeta:                 shieldTx,
+	metadata.PortalV4ShieldingResponseMeta:                shieldTx,
+	metadata.BurningRequestMeta:                           unshieldTx,
+	metadata.BurningRequestMetaV2:                         unshieldTx,
+	metadata.ContractingRequestMeta:                       unshieldTx,
+	metadata.BurningPBSCRequestMeta:                       unshieldTx,
+	metadata.BurningPLGRequestMeta:                        unshieldTx,
+	metadataCommon.BurningFantomRequestMeta:               unshieldTx,
+	metadataCommon.BurningPRVERC20RequestMeta:             unshieldTx,
+	metadataCommon.BurningPRVBEP20RequestMeta:             unshieldTx,
+	metadata.BurningForDepositToSCRequestMeta:             unshieldTx,
+	metadata.BurningForDepositToSCRequestMetaV2:           unshieldTx,
+	metadata.BurningPBSCForDepositToSCRequestMeta:         unshieldTx,
+	metadata.BurningPLGForDepositToSCRequestMeta:          unshieldTx,
+	metadataCommon.BurningFantomForDepositToSCRequestMeta: unshieldTx,
+	metadata.PortalV4UnshieldingRequestMeta:               unshieldTx,
+	metadata.PortalV4UnshieldingResponseMeta:              unshieldTx,
+
+	metadata.PDEContributionMeta:                       liquidityTx,
+	metadata.PDEPRVRequiredContributionRequestMeta:     liquidityTx,
+	metadata.PDEContributionResponseMeta:               liquidityTx,
+	metadata.PDEWithdrawalRequestMeta:                  liquidityTx,
+	metadata.PDEWithdrawalResponseMeta:                 liquidityTx,
+	metadataCommon.Pdexv3AddLiquidityRequestMeta:       liquidityTx,
+	metadataCommon.Pdexv3AddLiquidityResponseMeta:      liquidityTx,
+	metadataCommon.Pdexv3WithdrawLiquidityRequestMeta:  liquidityTx,
+	metadataCommon.Pdexv3WithdrawLiquidityResponseMeta: liquidityTx,
+
+	metadata.ShardStakingMeta:                  stakingTx,
+	metadata.BeaconStakingMeta:                 stakingTx,
+	metadata.UnStakingMeta:                     stakingTx,
+	metadata.ReturnStakingMeta:                 stakingTx,
+	metadataCommon.Pdexv3StakingRequestMeta:    stakingTx,
+	metadataCommon.Pdexv3StakingResponseMeta:   stakingTx,
+	metadataCommon.Pdexv3UnstakingRequestMeta:  stakingTx,
+	metadataCommon.Pdexv3UnstakingResponseMeta: stakingTx,
+
+	metadata.WithDrawRewardRequestMeta:                     rewardTx,
+	metadata.WithDrawRewardResponseMeta:                    rewardTx,
+	metadata.PDEFeeWithdrawalRequestMeta:                   rewardTx,
+	metadata.PDEFeeWithdrawalResponseMeta:                  rewardTx,
+	metadataCommon.Pdexv3WithdrawLPFeeRequestMeta:          rewardTx,
+	metadataCommon.Pdexv3WithdrawLPFeeResponseMeta:         rewardTx,
+	metadataCommon.Pdexv3WithdrawProtocolFeeRequestMeta:    rewardTx,
+	metadataCommon.Pdexv3WithdrawProtocolFeeResponseMeta:   rewardTx,
+	metadataCommon.Pdexv3WithdrawStakingRewardRequestMeta:  rewardTx,
+	metadataCommon.Pdexv3WithdrawStakingRewardResponseMeta: rewardTx,
+}
+
+// exportAmount is an amount of a token, formatted as an exact decimal number.
+type exportAmount struct {
+	TokenID  string
+	Currency string
+	Amount   string
+}
+
+func newExportAmount(tokenIDStr string, value uint64) *exportAmount {
+	return &exportAmount{
+		TokenID:  tokenIDStr,
+		Currency: getTokenSymbol(tokenIDStr),
+		Amount:   formatAmount(value, getTokenDecimals(tokenIDStr)),
+	}
+}
+
+// exportRecord is a line of the financial export: a transfer, or a trade pairing a request with its response.
+type exportRecord struct {
+	Time       time.Time
+	TxHash     string
+	Type       string
+	Received   *exportAmount `json:",omitempty"`
+	Sent       *exportAmount `json:",omitempty"`
+	Fee        *exportAmount `json:",omitempty"`
+	FiatValue  string        `json:",omitempty"`
+	Note       string
+	ResponseTx string `json:",omitempty"`
+}
+
+// getTxType returns the type of a transaction from its metadata and note.
+func getTxType(md metadata.Metadata, note string, isIn bool) string {
+	if note == "Conversion" {
+		return conversionTx
+	}
+	if md != nil {
+		if txType, ok := exportTxTypes[md.GetType()]; ok {
+			return txType
+		}
+	}
+	if isIn {
+		return receiveTx
+	}
+
+	return sendTx
+}
+
+// getTradeRequestTxID returns the hash of the request of a trade response, if the metadata is one.
+func getTradeRequestTxID(md metadata.Metadata) string {
+	switch md := md.(type) {
+	case *metadata.PDETradeResponse:
+		return md.RequestedTxID.String()
+	case *metadata.PDECrossPoolTradeResponse:
+		return md.RequestedTxID.String()
+	case *metadataPdexv3.TradeResponse:
+		return md.RequestTxID.String()
+	}
+
+	return ""
+}
+
+// getTxOutFee returns the fee of a TxOut.
+func getTxOutFee(txOut incclient.TxOut) *exportAmount {
+	switch {
+	case txOut.PRVFee != 0:
+		return newExportAmount(common.PRVIDStr, txOut.PRVFee)
+	case txOut.TokenFee != 0:
+		return newExportAmount(txOut.TokenID, txOut.TokenFee)
+	}
+
+	return nil
+}
+
+// buildExportRecords turns a history into export records, sorted by time. A trade response is merged with its request
+// into a single trade record when both are in the history. The fee of a transaction is only reported once, on its
+// first out-going record; a PRV TxOut only paying the fee is kept as a fee record when it is the only TxOut of its
+// transaction.
+func buildExportRecords(h *incclient.TxHistory) []exportRecord {
+	numTxOuts := make(map[string]int)
+	tradeRequests := make(map[string]int)
+	for i, txOut := range h.TxOutList {
+		numTxOuts[txOut.TxHash]++
+		if getTxType(txOut.Metadata, txOut.Note, false) == tradeTx && txOut.Amount != 0 {
+			tradeRequests[txOut.TxHash] = i
+		}
+	}
+
+	written := make(map[string]bool)
+	feePaid := make(map[string]bool)
+	merged := make(map[int]bool)
+	res := make([]exportRecord, 0)
+	for _, txIn := range h.TxInList {
+		key := fmt.Sprintf("i/%v/%v", txIn.TokenID, txIn.TxHash)
+		if written[key] {
+			continue
+		}
+		written[key] = true
+
+		record := exportRecord{
+			Time:     time.Unix(txIn.LockTime, 0),
+			TxHash:   txIn.TxHash,
+			Type:     getTxType(txIn.Metadata, txIn.Note, true),
+			Received: newExportAmount(txIn.TokenID, txIn.Amount),
+			Note:     txIn.Note,
+		}
+		if requestTxID := getTradeRequestTxID(txIn.Metadata); requestTxID != "" {
+			i, ok := tradeRequests[requestTxID]
+			switch {
+			case ok && !merged[i] && h.TxOutList[i].TokenID != txIn.TokenID:
+				txOut := h.TxOutList[i]
+				merged[i] = true
+				record.TxHash, record.ResponseTx = txOut.TxHash, txIn.TxHash
+				record.Sent = newExportAmount(txOut.TokenID, txOut.Amount)
+				if !feePaid[txOut.TxHash] {
+					record.Fee = getTxOutFee(txOut)
+					feePaid[txOut.TxHash] = true
+				}
+			case ok && h.TxOutList[i].TokenID == txIn.TokenID:
+				record.Type = refundTx
+			}
+		}
+		res = append(res, record)
+	}
+
+	for i, txOut := range h.TxOutList {
+		key := fmt.Sprintf("o/%v/%v", txOut.TokenID, txOut.TxHash)
+		if merged[i] || written[key] {
+			continue
+		}
+		written[key] = true
+
+		record := exportRecord{
+			Time:   time.Unix(txOut.LockTime, 0),
+			TxHash: txOut.TxHash,
+			Type:   getTxType(txOut.Metadata, txOut.Note, false),
+			Note:   txOut.Note,
+		}
+		if txOut.Amount != 0 {
+			record.Sent = newExportAmount(txOut.TokenID, txOut.Amount)
+		} else if numTxOuts[txOut.TxHash] > 1 {
+			continue
+		} else {
+			record.Type = feeTx
+		}
+		if !feePaid[txOut.TxHash] {
+			record.Fee = getTxOutFee(txOut)
+			feePaid[txOut.TxHash] = true
+		}
+		if record.Sent == nil && record.Fee == nil {
+			continue
+		}
+		res = append(res, record)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if !res[i].Time.Equal(res[j].Time) {
+			return res[i].Time.Before(res[j].Time)
+		}
+		return res[i].TxHash < res[j].TxHash
+	})
+
+	return res
+}
+
+// datedPrice is the fiat price of a token on a day.
+type datedPrice struct {
+	date  string
+	price *big.Rat
+}
+
+// exportPrices holds the fiat prices of the tokens by day, by tokenID.
+type exportPrices struct {
+	currency string
+	prices   map[string][]datedPrice
+}
+
+// loadExportPrices reads a price CSV file whose lines are `Date,Token,Price` (an optional header line is skipped),
+// where the date is YYYY-MM-DD, the token is one of the given tokens given as in resolveTokenID (a tokenID, a symbol,
+// or SYMBOL:NETWORK if several of the tokens share the symbol), and the price is the decimal price of one token in
+// the fiat currency.
+func loadExportPrices(file, currency string, tokenIDs []string) (*exportPrices, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+
+	tokenIDsByKey := make(map[string][]string)
+	for _, tokenID := range tokenIDs {
+		for _, key := range exportTokenKeys(tokenID) {
+			tokenIDsByKey[key] = append(tokenIDsByKey[key], tokenID)
+		}
+	}
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = 3
+	r.TrimLeadingSpace = true
+	lines, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &exportPrices{currency: currency, prices: make(map[string][]datedPrice)}
+	unknownTokens := make(map[string]bool)
+	for i, line := range lines {
+		if i == 0 && strings.EqualFold(line[0], "date") {
+			continue
+		}
+		if _, err := time.Parse(historyDateLayout, line[0]); err != nil {
+			return nil, fmt.Errorf("line %v: expected a date as %v, got %q", i+1, historyDateLayout, line[0])
+		}
+		price, ok := new(big.Rat).SetString(line[2])
+		if !ok || price.Sign() < 0 {
+			return nil, fmt.Errorf("line %v: invalid price %q", i+1, line[2])
+		}
+		tokenID, err := resolveExportToken(line[1], tokenIDsByKey[strings.ToUpper(line[1])])
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", i+1, err)
+		}
+		if tokenID == "" {
+			unknownTokens[line[1]] = true
+			continue
+		}
+		res.prices[tokenID] = append(res.prices[tokenID], datedPrice{date: line[0], price: price})
+	}
+	for token := range unknownTokens {
+		log.Printf("Ignoring the prices of %v, not in the history\n", token)
+	}
+	for _, prices := range res.prices {
+		sort.SliceStable(prices, func(i, j int) bool { return prices[i].date < prices[j].date })
+	}
+
+	return res, nil
+}
+
+// exportTokenKeys returns the upper-case keys by which a token is given in a price file: its tokenID, its symbol, and
+// SYMBOL:NETWORK if it has a network.
+func exportTokenKeys(tokenID string) []string {
+	res := []string{strings.ToUpper(tokenID)}
+	symbol := getTokenSymbol(tokenID)
+	if symbol == tokenID {
+		return res
+	}
+	res = append(res, strings.ToUpper(symbol))
+	if network := getTokenRegistry()[tokenID].Network; network != "" {
+		res = append(res, strings.ToUpper(symbol+":"+network))
+	}
+
+	return res
+}
+
+// resolveExportToken returns the tokenID of a token of a price file among the candidate tokens matching it, or an
+// empty string if there is none. As in resolveTokenID, a symbol shared by several tokens resolves to the unified
+// token (the one without a network) if there is exactly one, and is ambiguous otherwise.
+func resolveExportToken(token string, candidates []string) (string, error) {
+	switch len(candidates) {
+	case 0:
+		return "", nil
+	case 1:
+		return candidates[0], nil
+	}
+
+	noNetwork := make([]string, 0)
+	descriptions := make([]string, 0)
+	for _, tokenID := range candidates {
+		network := getTokenRegistry()[tokenID].Network
+		if network == "" {
+			noNetwork = append(noNetwork, tokenID)
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%v:%v (%v)", getTokenSymbol(tokenID), network, tokenID))
+	}
+	if len(noNetwork) == 1 {
+		return noNetwork[0], nil
+	}
+	sort.Strings(descriptions)
+
+	return "", fmt.Errorf("token %q is ambiguous in the history, use one of %v", token, strings.Join(descriptions, ", "))
+}
+
+// getValue returns the fiat value of an amount at a given time, using the latest price on or before that day. It
+// returns nil if there is no such price.
+func (p *exportPrices) getValue(a *exportAmount, t time.Time) *big.Rat {
+	if a == nil {
+		return nil
+	}
+	prices := p.prices[a.TokenID]
+	date := t.Format(historyDateLayout)
+	i := sort.Search(len(prices), func(i int) bool { return prices[i].date > date })
+	if i == 0 {
+		return nil
+	}
+
+	amount, _ := new(big.Rat).SetString(a.Amount)
+	return amount.Mul(amount, prices[i-1].price)
+}
+
+// setFiatValues sets the fiat value of the records: that of the received amount, or else of the sent amount, or else
+// of the fee.
+func (p *exportPrices) setFiatValues(records []exportRecord) {
+	for i, record := range records {
+		for _, a := range []*exportAmount{record.Received, record.Sent, record.Fee} {
+			if value := p.getValue(a, record.Time); value != nil {
+				records[i].FiatValue = value.FloatString(2)
+				break
+			}
+		}
+	}
+}
+
+// warnUnknownExportTokens warns about the tokens of a history that are not verified tokens of the token registry:
+// their amounts cannot be divided by their decimals, and they are written with their tokenIDs.
+func warnUnknownExportTokens(historyMap map[string]*incclient.TxHistory) {
+	unknownTokens := make([]string, 0)
+	registry := getTokenRegistry()
+	for tokenID, h := range historyMap {
+		if tokenID == common.ConfidentialAssetID.String() || len(h.TxInList)+len(h.TxOutList) == 0 {
+			continue
+		}
+		if tokenInfo, ok := registry[tokenID]; !ok || !tokenInfo.Verified {
+			unknownTokens = append(unknownTokens, tokenID)
+		}
+	}
+	if len(unknownTokens) == 0 {
+		return
+	}
+
+	sort.Strings(unknownTokens)
+	log.Printf("WARNING: %v token(s) of the history are not verified tokens of the token list, their amounts are "+
+		"written in their smallest unit and they are named by their tokenIDs: %v\n", len(unknownTokens),
+		strings.Join(unknownTokens, ", "))
+	log.Println("Run `token refresh` to update the token list, then export again")
+}
+
+func financialExport(c *cli.Context) error {
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	numThreads := c.Int(numThreadsFlag)
+	if numThreads == 0 {
+		return newAppError(NumThreadsError)
+	}
+
+	profile := c.String(exportProfileFlag)
+	isSupported := false
+	for _, p := range exportProfiles {
+		isSupported = isSupported || p == profile
+	}
+	if !isSupported {
+		return newAppError(UserInputError, fmt.Errorf("expected %v in %v, got %q", exportProfileFlag, exportProfiles, profile))
+	}
+
+	outFile := c.String(csvFileFlag)
+	if len(outFile) == 0 {
+		outFile = incclient.DefaultTxHistory
+	}
+	if !c.IsSet(csvFileFlag) && (profile == jsonExportProfile || profile == beancountProfile) {
+		outFile = strings.TrimSuffix(outFile, filepath.Ext(outFile)) + "." + profile
+	}
+
+	fiatCurrency := strings.ToUpper(c.String(fiatCurrencyFlag))
+	if c.String(priceFileFlag) != "" && fiatCurrency == "" {
+		return newAppError(UserInputError, fmt.Errorf("%v must not be empty", fiatCurrencyFlag))
+	}
+
+	r, err := getHistoryRange(c)
+	if err != nil {
+		return newAppError(InvalidDateRangeError, err)
+	}
+
+	historyMap, err := getSyncedHistories(privateKey, nil, numThreads, r, c.Bool(resyncFlag))
+	if err != nil {
+		return newAppError(GetHistoryError, err)
+	}
+
+	history := new(incclient.TxHistory)
+	history.TxInList = make([]incclient.TxIn, 0)
+	history.TxOutList = make([]incclient.TxOut, 0)
+	tokenIDs := make([]string, 0)
+	for tokenID, h := range historyMap {
+		if tokenID == common.ConfidentialAssetID.String() {
+			continue
+		}
+		tokenIDs = append(tokenIDs, tokenID)
+		history.TxInList = append(history.TxInList, h.TxInList...)
+		history.TxOutList = append(history.TxOutList, h.TxOutList...)
+	}
+	warnUnknownExportTokens(historyMap)
+
+	log.Println("Building up the history file...")
+	records := buildExportRecords(history)
+
+	if priceFile := c.String(priceFileFlag); priceFile != "" {
+		prices, err := loadExportPrices(priceFile, fiatCurrency, tokenIDs)
+		if err != nil {
+			return newAppError(UserInputError, fmt.Errorf("cannot load the prices from %v: %v", priceFile, err))
+		}
+		prices.setFiatValues(records)
+	} else {
+		fiatCurrency = ""
+	}
+
+	if err = writeExportRecords(outFile, profile, records, fiatCurrency); err != nil {
+		return newAppError(SaveHistoryError, err)
+	}
+	log.Printf("Report written to file `%v`\n", outFile)
+
+	return nil
+}
+
+// writeExportRecords writes the records to a file in the layout of a profile. The fiat currency is empty if no fiat
+// values were computed.
+func writeExportRecords(file, profile string, records []exportRecord, fiatCurrency string) error {
+	switch profile {
+	case jsonExportProfile:
+		return writeJSONFile(file, struct {
+			FiatCurrency string `json:",omitempty"`
+			Records      []exportRecord
+		}{fiatCurrency, records})
+	case beancountProfile:
+		return writeBeancount(file, records, fiatCurrency)
+	}
+
+	var header []string
+	var rowOf func(exportRecord) []string
+	switch profile {
+	case koinlyProfile:
+		header = []string{"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency", "Fee Amount",
+			"Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description", "TxHash"}
+		rowOf = func(record exportRecord) []string { return koinlyRow(record, fiatCurrency) }
+	case coinTrackingProfile:
+		header = []string{"Type", "Buy Amount", "Buy Currency", "Sell Amount", "Sell Currency", "Fee", "Fee Currency",
+			"Exchange", "Trade-Group", "Comment", "Date", "Tx-ID"}
+		rowOf = coinTrackingRow
+	default:
+		header = []string{"Date", "TxHash", "Received Quantity", "Received Currency", "Sent Quantity", "Sent Currency",
+			"Fee Amount", "Fee Currency", "Tag", "Type"}
+		if fiatCurrency != "" {
+			header = append(header, fmt.Sprintf("Value (%v)", fiatCurrency))
+		}
+		rowOf = func(record exportRecord) []string { return defaultExportRow(record, fiatCurrency != "") }
+	}
+
+	f, err := os.OpenFile(file, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open file %v: %v", file, err)
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+
+	w := csv.NewWriter(f)
+	if err = w.Write(header); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err = w.Write(rowOf(record)); err != nil {
+			return fmt.Errorf("write txHash %v error: %v", record.TxHash, err)
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+// amountColumns returns the amount and currency columns of an amount, empty if it is nil.
+func amountColumns(a *exportAmount) []string {
+	if a == nil {
+		return []string{"", ""}
+	}
+
+	return []string{a.Amount, a.Currency}
+}
+
+// defaultExportRow returns the row of a record in the historical layout of the financial export, where the tokens
+// are shown by name.
+func defaultExportRow(record exportRecord, withValue bool) []string {
+	res := []string{record.Time.Format("2006/01/02 15:04:05"), record.TxHash}
+	for _, a := range []*exportAmount{record.Received, record.Sent, record.Fee} {
+		if a == nil {
+			res = append(res, "", "")
+		} else {
+			res = append(res, a.Amount, getTokenName(a.TokenID))
+		}
+	}
+	res = append(res, record.Note, record.Type)
+	if withValue {
+		res = append(res, record.FiatValue)
+	}
+
+	return res
+}
+
+// koinlyRow returns the row of a record in the Koinly universal format.
+func koinlyRow(record exportRecord, fiatCurrency string) []string {
+	res := []string{record.Time.UTC().Format("2006-01-02 15:04:05 UTC")}
+	res = append(res, amountColumns(record.Sent)...)
+	res = append(res, amountColumns(record.Received)...)
+	res = append(res, amountColumns(record.Fee)...)
+	if record.FiatValue != "" {
+		res = append(res, record.FiatValue, fiatCurrency)
+	} else {
+		res = append(res, "", "")
+	}
+
+	label := ""
+	if record.Type == rewardTx && record.Received != nil {
+		label = "reward"
+	}
+	description := strings.TrimSpace(fmt.Sprintf("%v %v", record.Type, record.Note))
+
+	return append(res, label, description, record.TxHash)
+}
+
+// coinTrackingTypes maps the types of the records to the CoinTracking types, by direction.
+var coinTrackingTypes = map[string][2]string{
+	tradeTx:  {"Trade", "Trade"},
+	rewardTx: {"Reward / Bonus", "Withdrawal"},
+	feeTx:    {"Other Fee", "Other Fee"},
+}
+
+// coinTrackingRow returns the row of a record in the CoinTracking CSV import format.
+func coinTrackingRow(record exportRecord) []string {
+	txType := "Withdrawal"
+	if record.Received != nil {
+		txType = "Deposit"
+	}
+	if types, ok := coinTrackingTypes[record.Type]; ok {
+		txType = types[1]
+		if record.Received != nil {
+			txType = types[0]
+		}
+	}
+
+	res := []string{txType}
+	res = append(res, amountColumns(record.Received)...)
+	res = append(res, amountColumns(record.Sent)...)
+	res = append(res, amountColumns(record.Fee)...)
+	comment := strings.TrimSpace(fmt.Sprintf("%v %v", record.Type, record.Note))
+
+	return append(res, "Incognito", "", comment, record.Time.UTC().Format("2006-01-02 15:04:05"), record.TxHash)
+}
+
+// beancountInvalidChars matches the characters not allowed in the beancount commodities and account names.
+var beancountInvalidChars = regexp.MustCompile(`[^A-Z0-9-]`)
+
+// beancountCommodity returns the beancount commodity of a token: its sanitized symbol, suffixed with its network if
+// other verified tokens share the symbol (and with its tokenID if one of them also shares the network), or a name
+// derived from its tokenID if it has no known symbol.
+func beancountCommodity(tokenIDStr string) string {
+	symbol := getTokenSymbol(tokenIDStr)
+	if symbol == tokenIDStr {
+		return sanitizeBeancountCommodity("TOKEN-"+tokenIDStr[:8], "")
+	}
+
+	network := getTokenRegistry()[tokenIDStr].Network
+	suffix := ""
+	for tokenID, tokenInfo := range getTokenRegistry() {
+		if tokenID == tokenIDStr || !tokenInfo.Verified || !strings.EqualFold(tokenInfo.Symbol, symbol) {
+			continue
+		}
+		if !strings.EqualFold(tokenInfo.Network, network) {
+			if suffix == "" && network != "" {
+				suffix = network
+			}
+			continue
+		}
+		suffix = network + "-" + tokenIDStr[:8]
+		break
+	}
+
+	return sanitizeBeancountCommodity(symbol, suffix)
+}
+
+// sanitizeBeancountCommodity returns a valid beancount commodity made of a name and a suffix, truncating the name so
+// that the suffix is kept.
+func sanitizeBeancountCommodity(name, suffix string) string {
+	sanitize := func(s string) string {
+		return strings.Trim(beancountInvalidChars.ReplaceAllString(strings.ToUpper(s), "-"), "-")
+	}
+	res, suffix := sanitize(name), sanitize(suffix)
+	if res == "" || res[0] < 'A' || res[0] > 'Z' {
+		res = "T" + res
+	}
+	if suffix != "" {
+		suffix = "-" + suffix
+	}
+	if len(res)+len(suffix) > 24 {
+		res = strings.TrimRight(res[:24-len(suffix)], "-")
+	}
+
+	return res + suffix
+}
+
+// beancountCounterAccounts holds the account balancing the records of each type.
+var beancountCounterAccounts = map[string]string{
+	tradeTx:     "Equity:Incognito:Trades",
+	refundTx:    "Equity:Incognito:Trades",
+	liquidityTx: "Assets:Incognito:Liquidity",
+	stakingTx:   "Assets:Incognito:Staking",
+	rewardTx:    "Income:Incognito:Rewards",
+}
+
+// writeBeancount writes the records as a beancount ledger, one transaction per record.
+func writeBeancount(file string, records []exportRecord, fiatCurrency string) error {
+	const feeAccount = "Expenses:Incognito:Fees"
+	const transferAccount = "Equity:Incognito:Transfers"
+
+	commodities := make(map[string]string)
+	commodity := func(tokenID string) string {
+		if _, ok := commodities[tokenID]; !ok {
+			commodities[tokenID] = beancountCommodity(tokenID)
+		}
+		return commodities[tokenID]
+	}
+	assetAccount := func(a *exportAmount) string { return "Assets:Incognito:" + commodity(a.TokenID) }
+	posting := func(account string, a *exportAmount, negative bool) string {
+		sign := ""
+		if negative {
+			sign = "-"
+		}
+		return fmt.Sprintf("  %-48v %v%v %v\n", account, sign, a.Amount, commodity(a.TokenID))
+	}
+
+	accounts := make(map[string]bool)
+	var entries strings.Builder
+	for _, record := range records {
+		entries.WriteString(fmt.Sprintf("\n%v * %v %v\n", record.Time.Format(historyDateLayout),
+			strconv.Quote(record.Type), strconv.Quote(record.Note)))
+		entries.WriteString(fmt.Sprintf("  txhash: %v\n", strconv.Quote(record.TxHash)))
+		if record.FiatValue != "" {
+			entries.WriteString(fmt.Sprintf("  value: %v\n", strconv.Quote(record.FiatValue+" "+fiatCurrency)))
+		}
+
+		usedAccounts := make([]string, 0)
+		if record.Received != nil {
+			entries.WriteString(posting(assetAccount(record.Received), record.Received, false))
+			usedAccounts = append(usedAccounts, assetAccount(record.Received))
+		}
+		if record.Sent != nil {
+			entries.WriteString(posting(assetAccount(record.Sent), record.Sent, true))
+			usedAccounts = append(usedAccounts, assetAccount(record.Sent))
+		}
+		if record.Fee != nil {
+			entries.WriteString(posting(feeAccount, record.Fee, false))
+			entries.WriteString(posting(assetAccount(record.Fee), record.Fee, true))
+			usedAccounts = append(usedAccounts, feeAccount, assetAccount(record.Fee))
+		}
+		if record.Received != nil || record.Sent != nil {
+			counterAccount, ok := beancountCounterAccounts[record.Type]
+			if !ok {
+				counterAccount = transferAccount
+			}
+			entries.WriteString("  " + counterAccount + "\n")
+			usedAccounts = append(usedAccounts, counterAccount)
+		}
+		for _, account := range usedAccounts {
+			accounts[account] = true
+		}
+	}
+
+	f, err := os.OpenFile(file, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("cannot open file %v: %v", file, err)
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+
+	w := bufio.NewWriter(f)
+	if fiatCurrency != "" {
+		_, _ = fmt.Fprintf(w, "option \"operating_currency\" %v\n\n", strconv.Quote(fiatCurrency))
+	}
+	openDate := "1970-01-01"
+	if len(records) != 0 {
+		openDate = records[0].Time.Format(historyDateLayout)
+	}
+	sortedAccounts := make([]string, 0)
+	for account := range accounts {
+		sortedAccounts = append(sortedAccounts, account)
+	}
+	sort.Strings(sortedAccounts)
+	for _, account := range sortedAccounts {
+		_, _ = fmt.Fprintf(w, "%v open %v\n", openDate, account)
+	}
+	if _, err = w.WriteString(entries.String()); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
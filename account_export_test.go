@@ -0,0 +1,213 @@
+package main
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
+	metadataPdexv3 "github.com/incognitochain/go-incognito-sdk-v2/metadata/pdexv3"
+)
+
+func TestFormatAmount(t *testing.T) {
+	testCases := []struct {
+		amount   uint64
+		decimals int
+		expected string
+	}{
+		{0, 9, "0"},
+		{1, 9, "0.000000001"},
+		{1234500, 6, "1.2345"},
+		{1000000000, 9, "1"},
+		{18446744073709551615, 9, "18446744073.709551615"},
+		{42, 0, "42"},
+	}
+
+	for _, tc := range testCases {
+		if res := formatAmount(tc.amount, tc.decimals); res != tc.expected {
+			t.Fatalf("formatAmount(%v, %v): expect %v, got %v", tc.amount, tc.decimals, tc.expected, res)
+		}
+	}
+}
+
+func TestBuildExportRecords(t *testing.T) {
+	tokenID := strings.Repeat("ab", 32)
+	tradeRequest := &metadataPdexv3.TradeRequest{}
+	tradeRequest.Type = metadataCommon.Pdexv3TradeRequestMeta
+	requestHash, _ := common.Hash{}.NewHashFromStr(strings.Repeat("01", 32))
+	tradeResponse := &metadataPdexv3.TradeResponse{RequestTxID: *requestHash}
+	tradeResponse.Type = metadataCommon.Pdexv3TradeResponseMeta
+
+	h := &incclient.TxHistory{
+		TxInList: []incclient.TxIn{
+			{LockTime: 300, TxHash: "response", TokenID: tokenID, Amount: 50, Metadata: tradeResponse},
+			{LockTime: 100, TxHash: "in", TokenID: common.PRVIDStr, Amount: 2000000000},
+		},
+		TxOutList: []incclient.TxOut{
+			{LockTime: 200, TxHash: requestHash.String(), TokenID: common.PRVIDStr, Amount: 1000000000, PRVFee: 100,
+				Metadata: tradeRequest},
+			{LockTime: 400, TxHash: "token", TokenID: tokenID, Amount: 10, PRVFee: 100},
+			{LockTime: 400, TxHash: "token", TokenID: common.PRVIDStr, Amount: 0, PRVFee: 100},
+			{LockTime: 500, TxHash: "feeOnly", TokenID: common.PRVIDStr, Amount: 0, PRVFee: 100},
+		},
+	}
+
+	records := buildExportRecords(h)
+	if len(records) != 4 {
+		t.Fatalf("expect 4 records, got %+v", records)
+	}
+	if records[0].TxHash != "in" || records[0].Type != receiveTx || records[0].Received.Amount != "2" {
+		t.Fatalf("unexpected first record %+v", records[0])
+	}
+	trade := records[1]
+	if trade.Type != tradeTx || trade.TxHash != requestHash.String() || trade.ResponseTx != "response" ||
+		trade.Sent.Amount != "1" || trade.Received.TokenID != tokenID || trade.Fee.Amount != "0.0000001" {
+		t.Fatalf("unexpected trade record %+v", trade)
+	}
+	if records[2].TxHash != "token" || records[2].Type != sendTx || records[2].Fee == nil {
+		t.Fatalf("unexpected token record %+v", records[2])
+	}
+	if records[3].TxHash != "feeOnly" || records[3].Type != feeTx || records[3].Sent != nil || records[3].Fee == nil {
+		t.Fatalf("unexpected fee record %+v", records[3])
+	}
+}
+
+func TestExportPrices(t *testing.T) {
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	priceFile := filepath.Join(dir, "prices.csv")
+	data := "Date,Token,Price\n2024-01-01,prv,0.5\n2024-01-03,PRV,0.25\n2024-01-01,BTC,40000\n"
+	if err = ioutil.WriteFile(priceFile, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	prices, err := loadExportPrices(priceFile, "USD", []string{common.PRVIDStr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 12, 0, 0, 0, time.Local) }
+	records := []exportRecord{
+		{Time: day(2), Received: newExportAmount(common.PRVIDStr, 3000000000)},
+		{Time: day(5), Sent: newExportAmount(common.PRVIDStr, 1000000000)},
+		{Time: time.Date(2023, 12, 31, 12, 0, 0, 0, time.Local), Sent: newExportAmount(common.PRVIDStr, 1)},
+	}
+	prices.setFiatValues(records)
+	if records[0].FiatValue != "1.50" || records[1].FiatValue != "0.25" || records[2].FiatValue != "" {
+		t.Fatalf("unexpected fiat values %v, %v, %v", records[0].FiatValue, records[1].FiatValue, records[2].FiatValue)
+	}
+
+	csvFile := filepath.Join(dir, "koinly.csv")
+	if err = writeExportRecords(csvFile, koinlyProfile, records, "USD"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(csvFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 4 || lines[1][3] != "3" || lines[1][4] != "PRV" || lines[1][7] != "1.50" || lines[1][8] != "USD" {
+		t.Fatalf("unexpected Koinly file %v", lines)
+	}
+
+	if err = ioutil.WriteFile(priceFile, []byte("2024-01-01,PRV,abc\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loadExportPrices(priceFile, "USD", []string{common.PRVIDStr}); err == nil {
+		t.Fatal("expect an error for an invalid price")
+	}
+}
+
+func TestExportPricesSharedSymbol(t *testing.T) {
+	usdt := strings.Repeat("01", 32)
+	usdtETH := strings.Repeat("02", 32)
+	usdtBSC := strings.Repeat("03", 32)
+	defer setTestTokenRegistry(
+		TokenInfo{TokenID: usdt, Symbol: "USDT", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: usdtETH, Symbol: "USDT", Network: "ETH", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: usdtBSC, Symbol: "USDT", Network: "BSC", Verified: true, PDecimals: 6},
+	)()
+
+	dir, err := ioutil.TempDir("", "export")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	priceFile := filepath.Join(dir, "prices.csv")
+
+	testCases := []struct {
+		data     string
+		tokenIDs []string
+		expected string
+		isValid  bool
+	}{
+		{"2024-01-01,USDT,1\n", []string{usdtETH, usdtBSC}, "", false},
+		{"2024-01-01,usdt:bsc,1\n", []string{usdtETH, usdtBSC}, usdtBSC, true},
+		{"2024-01-01," + usdtETH + ",1\n", []string{usdtETH, usdtBSC}, usdtETH, true},
+		{"2024-01-01,USDT,1\n", []string{usdt, usdtETH, usdtBSC}, usdt, true},
+		{"2024-01-01,USDT,1\n", []string{usdtBSC}, usdtBSC, true},
+	}
+
+	for _, tc := range testCases {
+		if err = ioutil.WriteFile(priceFile, []byte(tc.data), 0600); err != nil {
+			t.Fatal(err)
+		}
+		prices, err := loadExportPrices(priceFile, "USD", tc.tokenIDs)
+		if tc.isValid != (err == nil) {
+			t.Fatalf("loadExportPrices(%q): expect valid %v, got %v", tc.data, tc.isValid, err)
+		}
+		if err != nil {
+			continue
+		}
+		if len(prices.prices) != 1 || len(prices.prices[tc.expected]) != 1 {
+			t.Fatalf("loadExportPrices(%q): expect the price of %v, got %v", tc.data, tc.expected, prices.prices)
+		}
+	}
+}
+
+func TestBeancountCommodity(t *testing.T) {
+	if res := beancountCommodity(common.PRVIDStr); res != "PRV" {
+		t.Fatalf("expect PRV, got %v", res)
+	}
+	if res := beancountCommodity(strings.Repeat("ab", 32)); res != "TOKEN-ABABABAB" {
+		t.Fatalf("expect TOKEN-ABABABAB, got %v", res)
+	}
+
+	usdt := strings.Repeat("01", 32)
+	usdtETH := strings.Repeat("02", 32)
+	usdtBSC := strings.Repeat("03", 32)
+	usdtBSC2 := strings.Repeat("04", 32)
+	btc := strings.Repeat("05", 32)
+	defer setTestTokenRegistry(
+		TokenInfo{TokenID: usdt, Symbol: "USDT", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: usdtETH, Symbol: "USDT", Network: "ETH", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: usdtBSC, Symbol: "USDT", Network: "BSC", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: usdtBSC2, Symbol: "usdt", Network: "BSC", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: btc, Symbol: "BTC", Network: "BTC", Verified: true, PDecimals: 9},
+	)()
+	expected := map[string]string{
+		usdt:     "USDT",
+		usdtETH:  "USDT-ETH",
+		usdtBSC:  "USDT-BSC-03030303",
+		usdtBSC2: "USDT-BSC-04040404",
+		btc:      "BTC",
+	}
+	for tokenID, commodity := range expected {
+		if res := beancountCommodity(tokenID); res != commodity {
+			t.Fatalf("beancountCommodity(%v): expect %v, got %v", tokenID, commodity, res)
+		}
+	}
+}
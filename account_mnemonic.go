@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/urfave/cli/v2"
+)
+
+// supportedMnemonicLengths lists the supported numbers of words of a BIP39 mnemonic.
+var supportedMnemonicLengths = []int{12, 15, 18, 21, 24}
+
+// isSupportedMnemonicLength checks if a mnemonic of numWords words is supported.
+func isSupportedMnemonicLength(numWords int) bool {
+	for _, l := range supportedMnemonicLengths {
+		if numWords == l {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeMnemonic splits a mnemonic whose words are separated by spaces or "-" and re-joins them with single spaces.
+func normalizeMnemonic(mnemonic string) string {
+	mnemonic = strings.Replace(mnemonic, "-", " ", -1)
+
+	return strings.ToLower(strings.Join(strings.Fields(mnemonic), " "))
+}
+
+// validateMnemonic checks the number of words, each word against the BIP39 English wordlist and the checksum of a
+// normalized mnemonic, and returns an error telling what is wrong.
+func validateMnemonic(mnemonic string) error {
+	words := strings.Fields(mnemonic)
+	if !isSupportedMnemonicLength(len(words)) {
+		return fmt.Errorf("expected %v words, got %v", supportedMnemonicLengths, len(words))
+	}
+
+	for i, word := range words {
+		if _, ok := bip39.GetWordIndex(word); ok {
+			continue
+		}
+		msg := fmt.Sprintf("word #%v %q is not in the BIP39 English wordlist", i+1, word)
+		if suggestions := suggestMnemonicWords(word); len(suggestions) != 0 {
+			msg += fmt.Sprintf(" (did you mean %v?)", strings.Join(suggestions, ", "))
+		}
+		return errors.New(msg)
+	}
+
+	if _, err := bip39.EntropyFromMnemonic(mnemonic); err != nil {
+		if err == bip39.ErrChecksumIncorrect {
+			return fmt.Errorf("checksum mismatch: a word is wrong or the words are not in the right order")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// suggestMnemonicWords returns the words of the BIP39 English wordlist sharing the longest prefix (of at least 2
+// characters) with the given word. BIP39 words are uniquely identified by their first 4 characters.
+func suggestMnemonicWords(word string) []string {
+	for l := 4; l >= 2; l-- {
+		if len(word) < l {
+			continue
+		}
+		res := make([]string, 0)
+		for _, w := range bip39.GetWordList() {
+			if strings.HasPrefix(w, word[:l]) {
+				res = append(res, w)
+			}
+		}
+		if len(res) != 0 {
+			if len(res) > 5 {
+				res = res[:5]
+			}
+			return res
+		}
+	}
+
+	return nil
+}
+
+// newMasterKeyFromMnemonic validates a mnemonic and returns its master key, derived with an optional BIP39 passphrase.
+func newMasterKeyFromMnemonic(mnemonic, passphrase string) (*wallet.KeyWallet, error) {
+	mnemonic = normalizeMnemonic(mnemonic)
+	if err := validateMnemonic(mnemonic); err != nil {
+		return nil, newAppError(InvalidMnemonicError, err)
+	}
+
+	w, err := wallet.NewMasterKeyFromSeed(bip39.NewSeed(mnemonic, passphrase))
+	if err != nil {
+		return nil, newAppError(ImportMnemonicError, err)
+	}
+
+	return w, nil
+}
+
+// getMasterKey returns the master key of the mnemonic and BIP39 passphrase given by the flags of a command.
+func getMasterKey(c *cli.Context) (*wallet.KeyWallet, error) {
+	return newMasterKeyFromMnemonic(c.String(mnemonicFlag), c.String(bip39PassphraseFlag))
+}
+
+// newMasterKey generates a new mnemonic of the number of words given by the numWords flag, and returns it together
+// with its master key derived with the BIP39 passphrase of the command (if any).
+func newMasterKey(c *cli.Context) (*wallet.KeyWallet, string, error) {
+	numWords := c.Int(numWordsFlag)
+	if !isSupportedMnemonicLength(numWords) {
+		return nil, "", newAppError(UserInputError, fmt.Errorf("expected numWords in %v, got %v", supportedMnemonicLengths, numWords))
+	}
+
+	// each word encodes 11 bits, 1 bit out of 33 being the checksum
+	entropy, err := bip39.NewEntropy(numWords * 32 / 3)
+	if err != nil {
+		return nil, "", newAppError(GenerateMasterKeyError, err)
+	}
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, "", newAppError(GenerateMasterKeyError, err)
+	}
+	w, err := wallet.NewMasterKeyFromSeed(bip39.NewSeed(mnemonic, c.String(bip39PassphraseFlag)))
+	if err != nil {
+		return nil, "", newAppError(GenerateMasterKeyError, err)
+	}
+
+	return w, mnemonic, nil
+}
+
+// verifyMnemonic checks a mnemonic backup offline and prints the payment addresses of its first accounts.
+func verifyMnemonic(c *cli.Context) error {
+	w, err := getMasterKey(c)
+	if err != nil {
+		return err
+	}
+
+	numAccounts := c.Int(numAccountsFlag)
+	type derivedAccount struct {
+		Index          int
+		PaymentAddress string
+	}
+	accounts := make([]derivedAccount, 0)
+	for index := 1; index <= numAccounts; index++ {
+		childKey, err := w.DeriveChild(uint32(index))
+		if err != nil {
+			return newAppError(DeriveChildError, err)
+		}
+		privateKey := childKey.Base58CheckSerialize(wallet.PrivateKeyType)
+		accounts = append(accounts, derivedAccount{index, incclient.PrivateKeyToPaymentAddress(privateKey, -1)})
+	}
+
+	return printResult(map[string]interface{}{
+		"Valid":         true,
+		"NumWords":      len(strings.Fields(normalizeMnemonic(c.String(mnemonicFlag)))),
+		"HasPassphrase": c.String(bip39PassphraseFlag) != "",
+		"Accounts":      accounts,
+	})
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestValidateMnemonic(t *testing.T) {
+	testCases := []struct {
+		mnemonic string
+		errMsg   string
+	}{
+		{"artist decline pepper spend good enemy caught sister sure opinion hundred lake", ""},
+		{"artist-decline-pepper-spend-good-enemy-caught-sister-sure-opinion-hundred-lake", ""},
+		{"Artist  decline pepper spend good enemy caught sister sure opinion hundred lake ", ""},
+		{"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art", ""},
+		{"artist decline pepper spend good", "expected"},
+		{"artist decline pepper spend good enemy caught sistr sure opinion hundred lake", "word #8"},
+		{"artist decline pepper spend good enemy caught sister sure opinion lake hundred", "checksum"},
+	}
+
+	for _, tc := range testCases {
+		err := validateMnemonic(normalizeMnemonic(tc.mnemonic))
+		if tc.errMsg == "" && err != nil {
+			t.Fatalf("expect %q to be valid, got %v", tc.mnemonic, err)
+		}
+		if tc.errMsg != "" && (err == nil || !strings.Contains(err.Error(), tc.errMsg)) {
+			t.Fatalf("expect an error containing %q for %q, got %v", tc.errMsg, tc.mnemonic, err)
+		}
+	}
+}
+
+func TestNewMasterKeyFromMnemonic(t *testing.T) {
+	mnemonic := "artist decline pepper spend good enemy caught sister sure opinion hundred lake"
+	expected, err := wallet.NewMasterKeyFromMnemonic(mnemonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without a passphrase, the master key must be the same as the one of the SDK
+	w, err := newMasterKeyFromMnemonic(strings.Replace(mnemonic, " ", "-", -1), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Base58CheckSerialize(wallet.PrivateKeyType) != expected.Base58CheckSerialize(wallet.PrivateKeyType) {
+		t.Fatalf("expect the same master key as the SDK")
+	}
+
+	w, err = newMasterKeyFromMnemonic(mnemonic, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Base58CheckSerialize(wallet.PrivateKeyType) == expected.Base58CheckSerialize(wallet.PrivateKeyType) {
+		t.Fatalf("expect a passphrase to change the master key")
+	}
+}
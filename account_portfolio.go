@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/urfave/cli/v2"
+)
+
+// portfolioHolding is an amount of a token in a portfolio.
+type portfolioHolding struct {
+	TokenID  string
+	Name     string
+	Amount   uint64
+	Decimals int
+	Balance  string
+}
+
+// portfolioLPShare is a pDEX liquidity share of an account.
+type portfolioLPShare struct {
+	PoolID    string
+	NFTID     string
+	Share     uint64
+	PoolValue []portfolioHolding
+	LPReward  []portfolioHolding
+}
+
+// portfolioAccount holds the holdings of a derived account.
+type portfolioAccount struct {
+	Index          int
+	PaymentAddress string
+	Balances       []portfolioHolding
+	LPShares       []portfolioLPShare
+	Rewards        []portfolioHolding
+	Errors         []string `json:",omitempty"`
+
+	balanceErr error
+}
+
+// portfolioTotal holds the aggregated holdings of all accounts.
+type portfolioTotal struct {
+	Balances []portfolioHolding
+	LPValues []portfolioHolding
+	Rewards  []portfolioHolding
+	Holdings []portfolioHolding
+}
+
+// getPortfolio prints the holdings of the accounts derived from a mnemonic, and their aggregation.
+func getPortfolio(c *cli.Context) error {
+	w, err := getMasterKey(c)
+	if err != nil {
+		return err
+	}
+
+	numAccounts := c.Int(numAccountsFlag)
+	if numAccounts <= 0 {
+		return newAppError(UserInputError, fmt.Errorf("numAccounts must be positive"))
+	}
+
+	numThreads := c.Int(numThreadsFlag)
+	if numThreads <= 0 {
+		return newAppError(NumThreadsError)
+	}
+
+	privateKeys := make([]string, 0)
+	for index := 1; index <= numAccounts; index++ {
+		childKey, err := w.DeriveChild(uint32(index))
+		if err != nil {
+			return newAppError(DeriveChildError, err)
+		}
+		privateKeys = append(privateKeys, childKey.Base58CheckSerialize(wallet.PrivateKeyType))
+	}
+
+	poolPairs := &lazyPoolPairs{}
+	accounts := make([]*portfolioAccount, numAccounts)
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < numThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				accounts[index] = getPortfolioAccount(index+1, privateKeys[index], poolPairs)
+				log.Printf("Account %v: done\n", index+1)
+			}
+		}()
+	}
+	for index := range privateKeys {
+		indices <- index
+	}
+	close(indices)
+	wg.Wait()
+
+	// nothing to report if the balances of every account could not be retrieved
+	failed := 0
+	for _, account := range accounts {
+		if account.balanceErr != nil {
+			failed++
+		}
+	}
+	if failed == numAccounts {
+		return newAppError(GetPortfolioError, accounts[0].balanceErr)
+	}
+
+	return printResult(struct {
+		Accounts []*portfolioAccount
+		Total    portfolioTotal
+	}{accounts, aggregatePortfolio(accounts)})
+}
+
+// lazyPoolPairs retrieves the pDEX pool pairs once, when the first account holding an NFT needs them.
+type lazyPoolPairs struct {
+	once      sync.Once
+	poolPairs map[string]*jsonresult.Pdexv3PoolPairState
+	err       error
+}
+
+// get returns the pDEX pool pairs.
+func (p *lazyPoolPairs) get() (map[string]*jsonresult.Pdexv3PoolPairState, error) {
+	p.once.Do(func() {
+		p.poolPairs, p.err = cfg.incClient.GetAllPdexPoolPairs(0)
+	})
+
+	return p.poolPairs, p.err
+}
+
+// getPortfolioAccount retrieves the balances, pDEX LP shares and committee rewards of an account. Errors are
+// recorded in the result so that one failing account does not prevent the others from being reported.
+func getPortfolioAccount(index int, privateKey string, poolPairs *lazyPoolPairs) *portfolioAccount {
+	res := &portfolioAccount{
+		Index:          index,
+		PaymentAddress: incclient.PrivateKeyToPaymentAddress(privateKey, -1),
+		Balances:       make([]portfolioHolding, 0),
+		LPShares:       make([]portfolioLPShare, 0),
+		Rewards:        make([]portfolioHolding, 0),
+	}
+
+	balances, err := cfg.incClient.GetAllBalancesV2(privateKey)
+	if err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("balances: %v", err))
+		res.balanceErr = err
+	}
+	res.Balances = newPortfolioHoldings(balances)
+
+	// NFTs are tokens, an account without token balances has no LP share.
+	hasTokens := false
+	for tokenID := range balances {
+		if tokenID != common.PRVIDStr {
+			hasTokens = true
+			break
+		}
+	}
+	if hasTokens {
+		lpShares, err := getPortfolioLPShares(privateKey, poolPairs)
+		if err != nil {
+			res.Errors = append(res.Errors, fmt.Sprintf("LP shares: %v", err))
+		}
+		res.LPShares = lpShares
+	}
+
+	rewards, err := cfg.incClient.GetRewardAmount(res.PaymentAddress)
+	if err != nil {
+		res.Errors = append(res.Errors, fmt.Sprintf("rewards: %v", err))
+	}
+	res.Rewards = newPortfolioHoldings(rewards)
+
+	return res
+}
+
+// getPortfolioLPShares returns the pDEX LP shares of the NFTs of an account, with their estimated values.
+func getPortfolioLPShares(privateKey string, poolPairs *lazyPoolPairs) ([]portfolioLPShare, error) {
+	res := make([]portfolioLPShare, 0)
+	nftIDs, err := cfg.incClient.GetMyNFTs(privateKey)
+	if err != nil || len(nftIDs) == 0 {
+		return res, err
+	}
+
+	allPoolPairs, err := poolPairs.get()
+	if err != nil {
+		return res, err
+	}
+	for _, nftID := range nftIDs {
+		for poolID, poolPair := range allPoolPairs {
+			share, ok := poolPair.Shares[nftID]
+			if !ok || share.Amount == 0 {
+				continue
+			}
+			lpValue, err := cfg.incClient.GetEstimatedLPValue(0, poolID, nftID)
+			if err != nil {
+				return res, err
+			}
+			res = append(res, portfolioLPShare{
+				PoolID:    poolID,
+				NFTID:     nftID,
+				Share:     share.Amount,
+				PoolValue: newPortfolioHoldings(lpValue.PoolValue),
+				LPReward:  newPortfolioHoldings(lpValue.LPReward),
+			})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].PoolID < res[j].PoolID || (res[i].PoolID == res[j].PoolID && res[i].NFTID < res[j].NFTID)
+	})
+
+	return res, nil
+}
+
+// aggregatePortfolio sums up the holdings of all accounts.
+func aggregatePortfolio(accounts []*portfolioAccount) portfolioTotal {
+	balances := make(map[string]uint64)
+	lpValues := make(map[string]uint64)
+	rewards := make(map[string]uint64)
+	all := make(map[string]uint64)
+	for _, account := range accounts {
+		for _, h := range account.Balances {
+			balances[h.TokenID] += h.Amount
+			all[h.TokenID] += h.Amount
+		}
+		for _, lpShare := range account.LPShares {
+			for _, h := range append(lpShare.PoolValue, lpShare.LPReward...) {
+				lpValues[h.TokenID] += h.Amount
+				all[h.TokenID] += h.Amount
+			}
+		}
+		for _, h := range account.Rewards {
+			rewards[h.TokenID] += h.Amount
+			all[h.TokenID] += h.Amount
+		}
+	}
+
+	return portfolioTotal{
+		Balances: newPortfolioHoldings(balances),
+		LPValues: newPortfolioHoldings(lpValues),
+		Rewards:  newPortfolioHoldings(rewards),
+		Holdings: newPortfolioHoldings(all),
+	}
+}
+
+// newPortfolioHoldings converts a map from tokenIDs to amounts into a list of non-zero holdings sorted by name.
+func newPortfolioHoldings(amounts map[string]uint64) []portfolioHolding {
+	res := make([]portfolioHolding, 0)
+	for tokenID, amount := range amounts {
+		if amount == 0 {
+			continue
+		}
+		decimals := getTokenDecimals(tokenID)
+		res = append(res, portfolioHolding{
+			TokenID:  tokenID,
+			Name:     getTokenName(tokenID),
+			Amount:   amount,
+			Decimals: decimals,
+			Balance:  formatAmount(amount, decimals),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].TokenID < res[j].TokenID
+	})
+
+	return res
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+)
+
+func TestAggregatePortfolio(t *testing.T) {
+	tokenID := "0000000000000000000000000000000000000000000000000000000000000115"
+	accounts := []*portfolioAccount{
+		{
+			Balances: newPortfolioHoldings(map[string]uint64{common.PRVIDStr: 1e9, tokenID: 0}),
+			LPShares: []portfolioLPShare{{
+				PoolValue: newPortfolioHoldings(map[string]uint64{common.PRVIDStr: 5e8, tokenID: 100}),
+				LPReward:  newPortfolioHoldings(map[string]uint64{tokenID: 10}),
+			}},
+		},
+		{
+			Balances: newPortfolioHoldings(map[string]uint64{tokenID: 50}),
+			Rewards:  newPortfolioHoldings(map[string]uint64{common.PRVIDStr: 2e8}),
+		},
+	}
+
+	total := aggregatePortfolio(accounts)
+	expected := map[string]uint64{common.PRVIDStr: 17e8, tokenID: 160}
+	if len(total.Holdings) != len(expected) {
+		t.Fatalf("expect %v holdings, got %v", len(expected), total.Holdings)
+	}
+	for _, h := range total.Holdings {
+		if h.Amount != expected[h.TokenID] {
+			t.Fatalf("expect %v of %v, got %v", expected[h.TokenID], h.TokenID, h.Amount)
+		}
+	}
+	if len(total.LPValues) != 2 || len(total.Rewards) != 1 || len(total.Balances) != 2 {
+		t.Fatalf("unexpected total %+v", total)
+	}
+	if total.Holdings[0].Name != "0000000000000000000000000000000000000000000000000000000000000115" ||
+		total.Holdings[1].Name != "PRV" || total.Holdings[1].Balance != "1.7" {
+		t.Fatalf("unexpected holdings %+v", total.Holdings)
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/urfave/cli/v2"
+)
+
+// utxoSortOrders lists the supported values of the sortBy flag; a leading "-" sorts in descending order.
+var utxoSortOrders = []string{"index", "-index", "value", "-value"}
+
+// keyImageBatchSize is the maximum number of key images checked by a single request to the full-node.
+const keyImageBatchSize = 100
+
+func checkUTXOs(c *cli.Context) error {
+	keySet, err := getWatchOnlyKeySet(c)
+	if err != nil {
+		return err
+	}
+	if keySet != nil {
+		return checkWatchOnlyUTXOs(c, keySet)
+	}
+
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	shardID := incclient.GetShardIDFromPrivateKey(privateKey)
+	var utxos []utxoInfo
+	if ok, err := tryDaemon("utxos", newAccountParams(privateKey, tokenIDStr), &utxos); ok {
+		if err != nil {
+			return err
+		}
+		return inspectUTXOInfos(c, utxos, shardID, tokenIDStr)
+	}
+
+	unSpentCoins, idxList, err := cfg.incClient.GetUnspentOutputCoins(privateKey, tokenIDStr, 0)
+	if err != nil {
+		return newAppError(GetUnspentOutputCoinsError, err)
+	}
+	indices := make([]uint64, 0)
+	for _, idx := range idxList {
+		indices = append(indices, idx.Uint64())
+	}
+
+	return inspectUTXOs(c, unSpentCoins, indices, shardID, tokenIDStr)
+}
+
+// utxoInfo describes a UTXO in the result of the `utxo` command.
+type utxoInfo struct {
+	Index    uint64
+	Version  int8
+	PubKey   string
+	KeyImage string
+	Value    uint64
+	Dust     bool  `json:",omitempty"`
+	Spent    *bool `json:",omitempty"`
+
+	// SpentUnknown is true for a UTXO of a watch-only account whose key image has not been imported: whether it is
+	// spent cannot be checked.
+	SpentUnknown bool `json:",omitempty"`
+}
+
+// utxoBucket counts the UTXOs whose value is in [MinValue, MaxValue).
+type utxoBucket struct {
+	MinValue uint64
+	MaxValue uint64
+	NumUTXOs int
+	Balance  uint64
+}
+
+// utxoListResult is the result of the `utxo` command.
+type utxoListResult struct {
+	UTXOs         []utxoInfo
+	NumUTXOsV1    int
+	NumUTXOsV2    int
+	BalanceV1     uint64
+	BalanceV2     uint64
+	TotalBalance  uint64
+	NumDustUTXOs  int          `json:",omitempty"`
+	DustBalance   uint64       `json:",omitempty"`
+	NumSpentUTXOs int          `json:",omitempty"`
+	Histogram     []utxoBucket `json:",omitempty"`
+
+	// Unverified is true if the spent status of some UTXOs is unknown (see utxoInfo.SpentUnknown): the balances may
+	// then include spent UTXOs.
+	Unverified           bool   `json:",omitempty"`
+	NumSpentUnknownUTXOs int    `json:",omitempty"`
+	SpentUnknownBalance  uint64 `json:",omitempty"`
+}
+
+// utxoFilter selects UTXOs by version (0: any version) and value (maxValue 0: no upper bound).
+type utxoFilter struct {
+	version  int8
+	minValue uint64
+	maxValue uint64
+}
+
+// match checks if a UTXO passes the filter.
+func (f utxoFilter) match(utxo utxoInfo) bool {
+	if f.version != 0 && utxo.Version != f.version {
+		return false
+	}
+	if utxo.Value < f.minValue {
+		return false
+	}
+	return f.maxValue == 0 || utxo.Value <= f.maxValue
+}
+
+// newUTXOListResult summarizes a list of UTXOs and their indices. The key image of a UTXO is left empty if unknown.
+func newUTXOListResult(utxos []coin.PlainCoin, indices []uint64) utxoListResult {
+	res := utxoListResult{UTXOs: make([]utxoInfo, 0)}
+	for i, utxo := range utxos {
+		keyImage := ""
+		if utxo.GetKeyImage() != nil {
+			keyImage = base58.Base58Check{}.Encode(utxo.GetKeyImage().ToBytesS(), 0)
+		}
+		res.UTXOs = append(res.UTXOs, utxoInfo{
+			Index:    indices[i],
+			Version:  int8(utxo.GetVersion()),
+			PubKey:   base58.Base58Check{}.Encode(utxo.GetPublicKey().ToBytesS(), 0),
+			KeyImage: keyImage,
+			Value:    utxo.GetValue(),
+		})
+	}
+	res.summarize(0)
+
+	return res
+}
+
+// summarize re-computes the counters of a result from its UTXOs, flagging those whose value is below dustThreshold.
+func (res *utxoListResult) summarize(dustThreshold uint64) {
+	res.NumUTXOsV1, res.NumUTXOsV2, res.BalanceV1, res.BalanceV2 = 0, 0, 0, 0
+	res.NumDustUTXOs, res.DustBalance, res.NumSpentUTXOs = 0, 0, 0
+	res.NumSpentUnknownUTXOs, res.SpentUnknownBalance = 0, 0
+	for i := range res.UTXOs {
+		utxo := &res.UTXOs[i]
+		if utxo.Version == 1 {
+			res.NumUTXOsV1++
+			res.BalanceV1 += utxo.Value
+		} else {
+			res.NumUTXOsV2++
+			res.BalanceV2 += utxo.Value
+		}
+		utxo.Dust = utxo.Value < dustThreshold
+		if utxo.Dust {
+			res.NumDustUTXOs++
+			res.DustBalance += utxo.Value
+		}
+		if utxo.Spent != nil && *utxo.Spent {
+			res.NumSpentUTXOs++
+		}
+		if utxo.SpentUnknown {
+			res.NumSpentUnknownUTXOs++
+			res.SpentUnknownBalance += utxo.Value
+		}
+	}
+	res.TotalBalance = res.BalanceV1 + res.BalanceV2
+	res.Unverified = res.NumSpentUnknownUTXOs > 0
+}
+
+// filterUTXOs returns the UTXOs passing a filter.
+func filterUTXOs(utxos []utxoInfo, filter utxoFilter) []utxoInfo {
+	res := make([]utxoInfo, 0)
+	for _, utxo := range utxos {
+		if filter.match(utxo) {
+			res = append(res, utxo)
+		}
+	}
+
+	return res
+}
+
+// sortUTXOs sorts UTXOs in one of the utxoSortOrders.
+func sortUTXOs(utxos []utxoInfo, sortBy string) error {
+	desc := strings.HasPrefix(sortBy, "-")
+	var less func(i, j int) bool
+	switch strings.TrimPrefix(sortBy, "-") {
+	case "index":
+		less = func(i, j int) bool { return utxos[i].Index < utxos[j].Index }
+	case "value":
+		less = func(i, j int) bool {
+			if utxos[i].Value != utxos[j].Value {
+				return utxos[i].Value < utxos[j].Value
+			}
+			return utxos[i].Index < utxos[j].Index
+		}
+	default:
+		return fmt.Errorf("expected sortBy in %v, got %q", utxoSortOrders, sortBy)
+	}
+
+	if desc {
+		sort.SliceStable(utxos, func(i, j int) bool { return less(j, i) })
+	} else {
+		sort.SliceStable(utxos, less)
+	}
+	return nil
+}
+
+// utxoHistogram groups UTXOs into buckets of values by power of ten: [0, 1), [1, 10), [10, 100), etc. Only
+// non-empty buckets are returned, in increasing order of values.
+func utxoHistogram(utxos []utxoInfo) []utxoBucket {
+	buckets := make(map[int]*utxoBucket)
+	for _, utxo := range utxos {
+		// the number of decimal digits of the value
+		k, minValue := 0, uint64(0)
+		for v := utxo.Value; v > 0; v /= 10 {
+			if k == 0 {
+				minValue = 1
+			} else {
+				minValue *= 10
+			}
+			k++
+		}
+		bucket, ok := buckets[k]
+		if !ok {
+			maxValue := uint64(1)
+			if k > 0 {
+				maxValue = minValue * 10
+				if minValue > math.MaxUint64/10 {
+					maxValue = math.MaxUint64
+				}
+			}
+			bucket = &utxoBucket{MinValue: minValue, MaxValue: maxValue}
+			buckets[k] = bucket
+		}
+		bucket.NumUTXOs++
+		bucket.Balance += utxo.Value
+	}
+
+	res := make([]utxoBucket, 0)
+	for _, bucket := range buckets {
+		res = append(res, *bucket)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].MinValue < res[j].MinValue })
+
+	return res
+}
+
+// checkUTXOsSpent asks the full-node whether the key images of the UTXOs have been spent. The UTXOs whose key image
+// is unknown (e.g, for a watch-only account without imported key images) are left unchecked.
+func checkUTXOsSpent(utxos []utxoInfo, shardID byte, tokenIDStr string) error {
+	keyImages := make([]string, 0)
+	positions := make([]int, 0)
+	for i, utxo := range utxos {
+		if utxo.KeyImage != "" {
+			keyImages = append(keyImages, utxo.KeyImage)
+			positions = append(positions, i)
+		}
+	}
+
+	for start := 0; start < len(keyImages); start += keyImageBatchSize {
+		end := start + keyImageBatchSize
+		if end > len(keyImages) {
+			end = len(keyImages)
+		}
+		spentList, err := cfg.incClient.CheckCoinsSpent(shardID, tokenIDStr, keyImages[start:end])
+		if err != nil {
+			return err
+		}
+		for i, spent := range spentList {
+			spent := spent
+			utxos[positions[start+i]].Spent = &spent
+		}
+	}
+
+	return nil
+}
+
+// exportUTXOs writes the result of the `utxo` command to a JSON or a CSV file, depending on the extension of the file.
+func exportUTXOs(res utxoListResult, file string) error {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".json":
+		return writeJSONFile(file, res)
+	case ".csv":
+	default:
+		return fmt.Errorf("expected a .json or .csv file, got %v", file)
+	}
+
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err := f.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+
+	w := csv.NewWriter(f)
+	err = w.Write([]string{"Index", "Version", "PubKey", "KeyImage", "Value", "Dust", "Spent"})
+	if err != nil {
+		return err
+	}
+	for _, utxo := range res.UTXOs {
+		spent := ""
+		if utxo.Spent != nil {
+			spent = fmt.Sprintf("%v", *utxo.Spent)
+		} else if utxo.SpentUnknown {
+			spent = "unknown"
+		}
+		err = w.Write([]string{
+			fmt.Sprintf("%v", utxo.Index),
+			fmt.Sprintf("%v", utxo.Version),
+			utxo.PubKey,
+			utxo.KeyImage,
+			fmt.Sprintf("%v", utxo.Value),
+			fmt.Sprintf("%v", utxo.Dust),
+			spent,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+// inspectUTXOs filters, sorts and summarizes the UTXOs of an account w.r.t a tokenID as requested by the flags of the
+// `utxo` command, and prints the result or exports it to a file.
+func inspectUTXOs(c *cli.Context, utxos []coin.PlainCoin, indices []uint64, shardID byte, tokenIDStr string) error {
+	return inspectUTXOInfos(c, newUTXOListResult(utxos, indices).UTXOs, shardID, tokenIDStr)
+}
+
+// inspectUTXOInfos is the same as inspectUTXOs, for UTXOs already described by utxoInfos (e.g, returned by the daemon).
+func inspectUTXOInfos(c *cli.Context, utxos []utxoInfo, shardID byte, tokenIDStr string) error {
+	version := c.Int(versionFlag)
+	if version < 0 || version > 2 {
+		return newAppError(VersionError, fmt.Errorf("expected version 1, 2 or 0 (all versions), got %v", version))
+	}
+	filter := utxoFilter{version: int8(version), minValue: c.Uint64(minValueFlag), maxValue: c.Uint64(maxValueFlag)}
+	if filter.maxValue != 0 && filter.maxValue < filter.minValue {
+		return newAppError(UserInputError, fmt.Errorf("maxValue (%v) is less than minValue (%v)", filter.maxValue, filter.minValue))
+	}
+
+	res := utxoListResult{UTXOs: filterUTXOs(utxos, filter)}
+	if err := sortUTXOs(res.UTXOs, c.String(sortByFlag)); err != nil {
+		return newAppError(UserInputError, err)
+	}
+	if c.Bool(checkSpentFlag) {
+		if err := checkUTXOsSpent(res.UTXOs, shardID, tokenIDStr); err != nil {
+			return newAppError(GetUnspentOutputCoinsError, fmt.Errorf("cannot check the key images: %v", err))
+		}
+	}
+	res.summarize(c.Uint64(dustThresholdFlag))
+	if c.Bool(histogramFlag) {
+		res.Histogram = utxoHistogram(res.UTXOs)
+	}
+
+	exportFile := c.String(exportFileFlag)
+	if exportFile != "" {
+		if err := exportUTXOs(res, exportFile); err != nil {
+			return newAppError(SaveOutputFileError, err)
+		}
+		log.Printf("%v UTXOs exported to %v\n", len(res.UTXOs), exportFile)
+		return nil
+	}
+
+	return printResult(res)
+}
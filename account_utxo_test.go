@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/csv"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testUTXOs() []utxoInfo {
+	return []utxoInfo{
+		{Index: 5, Version: 2, Value: 1000},
+		{Index: 1, Version: 1, Value: 50},
+		{Index: 3, Version: 2, Value: 0},
+		{Index: 2, Version: 2, Value: 1000},
+		{Index: 4, Version: 1, Value: 123456},
+	}
+}
+
+func TestFilterAndSortUTXOs(t *testing.T) {
+	utxos := filterUTXOs(testUTXOs(), utxoFilter{version: 2, minValue: 1})
+	if len(utxos) != 2 || utxos[0].Index != 5 || utxos[1].Index != 2 {
+		t.Fatalf("unexpected filtered UTXOs %+v", utxos)
+	}
+	if utxos = filterUTXOs(testUTXOs(), utxoFilter{minValue: 50, maxValue: 1000}); len(utxos) != 3 {
+		t.Fatalf("expect 3 UTXOs with a value in [50, 1000], got %+v", utxos)
+	}
+
+	testCases := []struct {
+		sortBy   string
+		expected []uint64
+	}{
+		{"index", []uint64{1, 2, 3, 4, 5}},
+		{"-index", []uint64{5, 4, 3, 2, 1}},
+		{"value", []uint64{3, 1, 2, 5, 4}},
+		{"-value", []uint64{4, 5, 2, 1, 3}},
+	}
+	for _, tc := range testCases {
+		utxos := testUTXOs()
+		if err := sortUTXOs(utxos, tc.sortBy); err != nil {
+			t.Fatal(err)
+		}
+		for i, utxo := range utxos {
+			if utxo.Index != tc.expected[i] {
+				t.Fatalf("sortBy %v: expect indices %v, got %+v", tc.sortBy, tc.expected, utxos)
+			}
+		}
+	}
+	if err := sortUTXOs(testUTXOs(), "pubKey"); err == nil {
+		t.Fatalf("expect an error for an unsupported order")
+	}
+}
+
+func TestUTXOHistogram(t *testing.T) {
+	utxos := append(testUTXOs(), utxoInfo{Value: math.MaxUint64}, utxoInfo{Value: 9})
+	expected := []utxoBucket{
+		{MinValue: 0, MaxValue: 1, NumUTXOs: 1, Balance: 0},
+		{MinValue: 1, MaxValue: 10, NumUTXOs: 1, Balance: 9},
+		{MinValue: 10, MaxValue: 100, NumUTXOs: 1, Balance: 50},
+		{MinValue: 1000, MaxValue: 10000, NumUTXOs: 2, Balance: 2000},
+		{MinValue: 100000, MaxValue: 1000000, NumUTXOs: 1, Balance: 123456},
+		{MinValue: 1e19, MaxValue: math.MaxUint64, NumUTXOs: 1, Balance: math.MaxUint64},
+	}
+	res := utxoHistogram(utxos)
+	if len(res) != len(expected) {
+		t.Fatalf("expect %v buckets, got %+v", len(expected), res)
+	}
+	for i := range res {
+		if res[i] != expected[i] {
+			t.Fatalf("bucket #%v: expect %+v, got %+v", i, expected[i], res[i])
+		}
+	}
+}
+
+func TestSummarizeAndExportUTXOs(t *testing.T) {
+	spent := true
+	res := utxoListResult{UTXOs: testUTXOs()}
+	res.UTXOs[0].Spent = &spent
+	res.UTXOs[3].SpentUnknown = true
+	res.summarize(100)
+	if res.NumUTXOsV1 != 2 || res.NumUTXOsV2 != 3 || res.BalanceV1 != 123506 || res.TotalBalance != 125506 {
+		t.Fatalf("unexpected summary %+v", res)
+	}
+	if res.NumDustUTXOs != 2 || res.DustBalance != 50 || !res.UTXOs[1].Dust || res.NumSpentUTXOs != 1 {
+		t.Fatalf("unexpected dust or spent summary %+v", res)
+	}
+	if !res.Unverified || res.NumSpentUnknownUTXOs != 1 || res.SpentUnknownBalance != 1000 {
+		t.Fatalf("unexpected spent-unknown summary %+v", res)
+	}
+
+	dir, err := ioutil.TempDir("", "utxo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	csvFile := filepath.Join(dir, "utxos.csv")
+	if err = exportUTXOs(res, csvFile); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(csvFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 6 || records[1][0] != "5" || records[1][6] != "true" || records[2][5] != "true" || records[2][6] != "" ||
+		records[4][6] != "unknown" {
+		t.Fatalf("unexpected CSV records %v", records)
+	}
+
+	jsonFile := filepath.Join(dir, "utxos.json")
+	if err = exportUTXOs(res, jsonFile); err != nil {
+		t.Fatal(err)
+	}
+	var exported utxoListResult
+	if err = readJSONFile(jsonFile, &exported); err != nil {
+		t.Fatal(err)
+	}
+	if len(exported.UTXOs) != 5 || exported.NumDustUTXOs != 2 {
+		t.Fatalf("unexpected exported result %+v", exported)
+	}
+
+	if err = exportUTXOs(res, filepath.Join(dir, "utxos.txt")); err == nil {
+		t.Fatalf("expect an error for an unsupported format")
+	}
+}
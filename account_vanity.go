@@ -0,0 +1,324 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// paymentAddressLead is the leading part shared by all payment addresses.
+	paymentAddressLead = "12s"
+
+	// base58Alphabet is the alphabet of base58-encoded keys and addresses.
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+	// vanityProgressInterval is the delay between two progress reports of the vanity generation.
+	vanityProgressInterval = 5 * time.Second
+)
+
+// vanityTarget describes the accounts searched by the vanity generation.
+type vanityTarget struct {
+	// shards is the set of accepted shards, nil means any shard.
+	shards map[byte]bool
+	// prefix is matched against the payment address right after the paymentAddressLead.
+	prefix string
+	// probability is the probability for a payment address to start with the prefix.
+	probability float64
+}
+
+// paymentAddressRange returns the range [low, high) of the numbers base58-encoded by the payment addresses, and their
+// number of base58 digits. A payment address encodes a zero version byte (the leading "1"), the key type and the
+// length of the public key, then the public key and the other keys and checksum, which are taken as uniformly
+// distributed.
+func paymentAddressRange() (*big.Int, *big.Int, int) {
+	emptyKey := make([]byte, common.PublicKeySize)
+	w := &wallet.KeyWallet{KeySet: key.KeySet{PaymentAddress: key.PaymentAddress{Pk: emptyKey, Tk: emptyKey, OTAPublic: emptyKey}}}
+	encoded := base58.Base58{}.Decode(w.Base58CheckSerialize(wallet.PaymentAddressType))
+	header := new(big.Int).SetBytes(encoded[1:3])
+	numBits := uint(8 * (len(encoded) - 3))
+
+	low := new(big.Int).Lsh(header, numBits)
+	high := new(big.Int).Lsh(header.Add(header, big.NewInt(1)), numBits)
+
+	return low, high, len(base58.Base58{}.Encode(low.Bytes()))
+}
+
+// prefixProbability returns the probability for a payment address to start with the paymentAddressLead followed by a
+// base58 prefix. Since the leading bytes of a payment address are fixed, so are its first characters, and the one
+// right after the paymentAddressLead only takes a part of the alphabet.
+func prefixProbability(prefix string) float64 {
+	low, high, numDigits := paymentAddressRange()
+	digits := strings.TrimPrefix(paymentAddressLead, "1") + prefix
+	if len(digits) > numDigits {
+		return 0
+	}
+
+	// the numbers whose base58 encoding starts with the digits are in [start, start+size)
+	start := new(big.Int)
+	for _, ch := range digits {
+		start.Mul(start, big.NewInt(58))
+		start.Add(start, big.NewInt(int64(strings.IndexRune(base58Alphabet, ch))))
+	}
+	size := new(big.Int).Exp(big.NewInt(58), big.NewInt(int64(numDigits-len(digits))), nil)
+	start.Mul(start, size)
+	end := new(big.Int).Add(start, size)
+
+	if start.Cmp(low) < 0 {
+		start = low
+	}
+	if end.Cmp(high) > 0 {
+		end = high
+	}
+	if start.Cmp(end) >= 0 {
+		return 0
+	}
+	res, _ := new(big.Rat).SetFrac(new(big.Int).Sub(end, start), new(big.Int).Sub(high, low)).Float64()
+
+	return res
+}
+
+// newVanityTarget validates the shard and prefix of a vanity generation.
+func newVanityTarget(shardID int, prefix string) (*vanityTarget, error) {
+	if shardID < -1 || shardID >= common.MaxShardNumber {
+		return nil, newAppError(InvalidShardError, fmt.Errorf("expected shardID from -1 to %v", common.MaxShardNumber-1))
+	}
+	prefix = strings.TrimPrefix(prefix, paymentAddressLead)
+	for _, ch := range prefix {
+		if !strings.ContainsRune(base58Alphabet, ch) {
+			return nil, newAppError(UserInputError, fmt.Errorf("prefix contains a non-base58 character %q", ch))
+		}
+	}
+
+	res := &vanityTarget{prefix: prefix, probability: prefixProbability(prefix)}
+	if res.probability == 0 {
+		low, high, _ := paymentAddressRange()
+		first := base58.Base58{}.Encode(low.Bytes())[len(paymentAddressLead)-1]
+		last := base58.Base58{}.Encode(high.Sub(high, big.NewInt(1)).Bytes())[len(paymentAddressLead)-1]
+		return nil, newAppError(UserInputError, fmt.Errorf("no payment address starts with %v%v, the character after %v is between %q and %q",
+			paymentAddressLead, prefix, paymentAddressLead, first, last))
+	}
+	if shardID >= 0 {
+		res.shards = map[byte]bool{byte(shardID): true}
+	}
+
+	return res, nil
+}
+
+// match checks if the account of a private key is targeted.
+func (t *vanityTarget) match(privateKey string) bool {
+	if t.shards != nil && !t.shards[incclient.GetShardIDFromPrivateKey(privateKey)] {
+		return false
+	}
+	if t.prefix == "" {
+		return true
+	}
+	address := incclient.PrivateKeyToPaymentAddress(privateKey, -1)
+
+	return strings.HasPrefix(strings.TrimPrefix(address, paymentAddressLead), t.prefix)
+}
+
+// expectedTries returns the expected number of indexes to try before finding a targeted account.
+func (t *vanityTarget) expectedTries() float64 {
+	res := 1 / t.probability
+	if t.shards != nil {
+		res *= float64(common.MaxShardNumber)
+	}
+
+	return res
+}
+
+// genVanityKeySet generates a new mnemonic and searches its derivation indexes, with numThreads workers, for numAccounts
+// accounts matching a shard and/or a payment-address prefix.
+func genVanityKeySet(c *cli.Context) error {
+	numShards := c.Int(numShardsFlag)
+	if numShards == 0 {
+		return newAppError(InvalidNumberShardsError)
+	}
+	common.MaxShardNumber = numShards
+
+	target, err := newVanityTarget(c.Int(shardIDFlag), c.String(prefixFlag))
+	if err != nil {
+		return err
+	}
+
+	numAccounts := c.Int(numAccountsFlag)
+	if numAccounts <= 0 {
+		return newAppError(UserInputError, fmt.Errorf("numAccounts must be positive"))
+	}
+	numThreads := c.Int(numThreadsFlag)
+	if numThreads <= 0 {
+		return newAppError(NumThreadsError)
+	}
+
+	// ask for the passphrase first, the search may take a long time
+	outFile := c.String(outFileFlag)
+	var passphrase []byte
+	if outFile != "" {
+		passphrase, err = promptNewPassphrase("the output file")
+		if err != nil {
+			return newAppError(UserInputError, err)
+		}
+	}
+
+	w, mnemonic, err := newMasterKey(c)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Searching %v account(s) with %v threads, about %.0f indexes to try per account\n",
+		numAccounts, numThreads, target.expectedTries())
+	accounts, err := searchVanityAccounts(w, target, numAccounts, numThreads)
+	if err != nil {
+		return err
+	}
+
+	res := masterKeyInfo{Mnemonic: mnemonic, Accounts: accounts}
+	if outFile == "" {
+		return printResult(res)
+	}
+
+	jsb, err := json.Marshal(res)
+	if err != nil {
+		return newAppError(UnexpectedError, err)
+	}
+	encrypted, err := encryptWithPassphrase(jsb, passphrase, defaultScryptN, defaultScryptR, defaultScryptP)
+	if err != nil {
+		return newAppError(SaveOutputFileError, err)
+	}
+	err = writeJSONFile(outFile, encrypted)
+	if err != nil {
+		return newAppError(SaveOutputFileError, err)
+	}
+
+	// only print the public part of the result
+	type publicAccountInfo struct {
+		Index          int
+		PaymentAddress string
+		ShardID        byte
+	}
+	publicAccounts := make([]publicAccountInfo, 0)
+	for _, account := range accounts {
+		publicAccounts = append(publicAccounts, publicAccountInfo{account.Index, account.PaymentAddress, account.ShardID})
+	}
+	return printResult(map[string]interface{}{"OutFile": outFile, "Accounts": publicAccounts})
+}
+
+// searchVanityAccounts derives the indexes of a master key in parallel, and returns the first numAccounts accounts
+// matching the target.
+func searchVanityAccounts(w *wallet.KeyWallet, target *vanityTarget, numAccounts, numThreads int) ([]*accountInfo, error) {
+	var nextIndex, numTried uint32
+	var numFound int32
+	var mtx sync.Mutex
+	var searchErr error
+	accounts := make([]*accountInfo, 0)
+
+	done := make(chan struct{})
+	go reportVanityProgress(done, &numTried, &numFound, numAccounts)
+
+	var wg sync.WaitGroup
+	for i := 0; i < numThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&numFound) < int32(numAccounts) {
+				index := atomic.AddUint32(&nextIndex, 1)
+				childKey, err := w.DeriveChild(index)
+				if err == nil {
+					privateKey := childKey.Base58CheckSerialize(wallet.PrivateKeyType)
+					atomic.AddUint32(&numTried, 1)
+					if !target.match(privateKey) {
+						continue
+					}
+					var info *incclient.KeyInfo
+					info, err = incclient.GetAccountInfoFromPrivateKey(privateKey)
+					if err == nil {
+						mtx.Lock()
+						accounts = append(accounts, &accountInfo{Index: int(index), KeyInfo: info})
+						mtx.Unlock()
+						atomic.AddInt32(&numFound, 1)
+						continue
+					}
+				}
+
+				mtx.Lock()
+				searchErr = fmt.Errorf("index %v: %v", index, err)
+				mtx.Unlock()
+				atomic.StoreInt32(&numFound, int32(numAccounts))
+			}
+		}()
+	}
+	wg.Wait()
+	close(done)
+
+	if searchErr != nil {
+		return nil, newAppError(DeriveChildError, searchErr)
+	}
+
+	// indexes are handed out in order and all of them have been checked, so the lowest matching indexes are the first
+	// numAccounts matches
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Index < accounts[j].Index
+	})
+	if len(accounts) > numAccounts {
+		accounts = accounts[:numAccounts]
+	}
+
+	return accounts, nil
+}
+
+// reportVanityProgress periodically logs the progress of a vanity generation until done is closed.
+func reportVanityProgress(done chan struct{}, numTried *uint32, numFound *int32, numAccounts int) {
+	start := time.Now()
+	ticker := time.NewTicker(vanityProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			tried := atomic.LoadUint32(numTried)
+			log.Printf("Tried %v indexes (%.0f/s), found %v/%v\n", tried,
+				float64(tried)/time.Since(start).Seconds(), atomic.LoadInt32(numFound), numAccounts)
+		}
+	}
+}
+
+// decryptOutFile decrypts and prints a file written by genVanityKeySet.
+func decryptOutFile(c *cli.Context) error {
+	var encrypted encryptedData
+	err := readJSONFile(c.String(inFileFlag), &encrypted)
+	if err != nil {
+		return newAppError(DecryptFileError, err)
+	}
+
+	passphrase, err := promptInput("Enter the passphrase of the file", new(string), true)
+	if err != nil {
+		return newAppError(UserInputError, err)
+	}
+	plainText, err := decryptWithPassphrase(&encrypted, passphrase)
+	if err != nil {
+		return newAppError(DecryptFileError, err)
+	}
+
+	var res masterKeyInfo
+	err = json.Unmarshal(plainText, &res)
+	if err != nil {
+		return newAppError(DecryptFileError, err)
+	}
+
+	return printResult(res)
+}
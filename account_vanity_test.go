@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestSearchVanityAccounts(t *testing.T) {
+	common.MaxShardNumber = 8
+	w, _, err := wallet.NewMasterKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	target, err := newVanityTarget(-1, "12sa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := searchVanityAccounts(w, target, 2, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 2 || accounts[0].Index >= accounts[1].Index {
+		t.Fatalf("expect 2 accounts sorted by index, got %v", accounts)
+	}
+	for _, account := range accounts {
+		if !strings.HasPrefix(account.PaymentAddress, "12sa") {
+			t.Fatalf("account %v does not match the prefix: %v", account.Index, account.PaymentAddress)
+		}
+	}
+
+	// the first matching index must have been found
+	for index := 1; index < accounts[0].Index; index++ {
+		childKey, err := w.DeriveChild(uint32(index))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if target.match(childKey.Base58CheckSerialize(wallet.PrivateKeyType)) {
+			t.Fatalf("index %v matches but was skipped", index)
+		}
+	}
+
+	target, err = newVanityTarget(2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err = searchVanityAccounts(w, target, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, account := range accounts {
+		if account.ShardID != 2 {
+			t.Fatalf("expect account %v in shard 2, got %v", account.Index, account.ShardID)
+		}
+	}
+
+	if _, err = newVanityTarget(0, "0OIl"); err == nil {
+		t.Fatalf("expect an error for a non-base58 prefix")
+	}
+	for _, prefix := range []string{"1", "A", "y", "12sz"} {
+		if _, err = newVanityTarget(-1, prefix); err == nil {
+			t.Fatalf("expect an error for the prefix %v which no payment address can start with", prefix)
+		}
+	}
+}
+
+func TestPrefixProbability(t *testing.T) {
+	total := 0.0
+	for _, ch := range base58Alphabet {
+		p := prefixProbability(string(ch))
+		if (p != 0) != (ch >= 'a' && ch <= 'x') {
+			t.Fatalf("unexpected probability %v for %q", p, ch)
+		}
+		total += p
+
+		// the following characters are uniformly distributed, except at the edges of the range
+		if ch > 'a' && ch < 'x' && math.Abs(prefixProbability(string(ch)+"z")*58/p-1) > 1e-6 {
+			t.Fatalf("unexpected probability %v for %q", prefixProbability(string(ch)+"z"), string(ch)+"z")
+		}
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Fatalf("expect the probabilities to sum to 1, got %v", total)
+	}
+}
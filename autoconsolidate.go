@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/urfave/cli/v2"
+)
+
+// consolidationPlan is the consolidation of the UTXOs of a token with a version, and its result once run.
+type consolidationPlan struct {
+	TokenID      string
+	TokenName    string
+	Version      int8
+	NumUTXOs     int
+	EstimatedTxs int
+	// MaxSplitTxs is the number of PRV-split transactions included in EstimatedTxs: when consolidating token UTXOs v2,
+	// the SDK sends one at the beginning of each round if the account lacks PRV UTXOs v2 to pay the fees of the round.
+	// It is an upper bound, no split transaction is sent when the account has enough of them.
+	MaxSplitTxs int `json:",omitempty"`
+	// EstimatedFee is paid in FeeTokenID: PRV, except for token UTXOs v1 which pay the fee in the token itself unless a
+	// custom fee is set.
+	EstimatedFee uint64
+	FeeTokenID   string
+
+	TxList        []string `json:",omitempty"`
+	NumUTXOsAfter int      `json:",omitempty"`
+	Error         string   `json:",omitempty"`
+}
+
+// consolidationReport is the report written by autoConsolidate.
+type consolidationReport struct {
+	PaymentAddress string
+	Time           string
+	MinUTXOs       int
+	DryRun         bool
+	Plans          []*consolidationPlan
+}
+
+// estimateConsolidation returns the number of transactions and rounds needed to consolidate numUTXOs UTXOs, following
+// the way the SDK does: each round merges chunks of at most incclient.MaxInputSize UTXOs with at most numThreads
+// transactions, until there are no more than maxUTXOsAfterConsolidated UTXOs left.
+func estimateConsolidation(numUTXOs, numThreads int) (int, int) {
+	numTxs, numRounds := 0, 0
+	for numUTXOs > maxUTXOsAfterConsolidated {
+		numWorkers, consumed := 0, 0
+		for current := 0; current < numUTXOs && numWorkers < numThreads; current += incclient.MaxInputSize {
+			next := current + incclient.MaxInputSize
+			if next > numUTXOs {
+				next = numUTXOs
+			}
+			if next-current < 2 {
+				break
+			}
+			consumed += next - current
+			numWorkers++
+		}
+		if numWorkers == 0 {
+			break
+		}
+		numUTXOs = numUTXOs - consumed + numWorkers
+		numTxs += numWorkers
+		numRounds++
+	}
+
+	return numTxs, numRounds
+}
+
+// planConsolidation inspects the UTXOs of every token held by an account and returns the consolidations to run for
+// the tokens (and versions) having more than minUTXOs UTXOs. PRV comes first, since token transactions v2 pay their
+// fee with PRV UTXOs. A fee of 0 means the default fee of the SDK.
+func planConsolidation(privateKey string, minUTXOs, numThreads int, fee uint64) ([]*consolidationPlan, error) {
+	balances, err := cfg.incClient.GetAllBalancesV2(privateKey)
+	if err != nil {
+		return nil, newAppError(GetAllBalancesError, err)
+	}
+	tokenIDs := []string{common.PRVIDStr}
+	for tokenID := range balances {
+		if tokenID != common.PRVIDStr {
+			tokenIDs = append(tokenIDs, tokenID)
+		}
+	}
+	sort.Strings(tokenIDs[1:])
+
+	// transactions are sent one after another with a custom fee
+	feePerTx := fee
+	if fee != 0 {
+		numThreads = 1
+	} else {
+		feePerTx = incclient.DefaultPRVFee
+	}
+
+	plans := make([]*consolidationPlan, 0)
+	for _, tokenID := range tokenIDs {
+		utxos, _, err := cfg.incClient.GetUnspentOutputCoins(privateKey, tokenID, 0)
+		if err != nil {
+			return nil, newAppError(GetUnspentOutputCoinsError, fmt.Errorf("token %v: %v", tokenID, err))
+		}
+		numUTXOs := make(map[int8]int)
+		for _, utxo := range utxos {
+			numUTXOs[int8(utxo.GetVersion())]++
+		}
+		log.Printf("Token %v: %v UTXOs v1, %v UTXOs v2\n", getTokenName(tokenID), numUTXOs[1], numUTXOs[2])
+
+		for _, version := range []int8{1, 2} {
+			if numUTXOs[version] <= minUTXOs {
+				continue
+			}
+			numTxs, numRounds := estimateConsolidation(numUTXOs[version], numThreads)
+			plan := &consolidationPlan{
+				TokenID:      tokenID,
+				TokenName:    getTokenName(tokenID),
+				Version:      version,
+				NumUTXOs:     numUTXOs[version],
+				EstimatedTxs: numTxs,
+				FeeTokenID:   common.PRVIDStr,
+			}
+			if tokenID != common.PRVIDStr && version == 2 && fee == 0 {
+				plan.MaxSplitTxs = numRounds
+				plan.EstimatedTxs += numRounds
+			}
+			plan.EstimatedFee = uint64(plan.EstimatedTxs) * feePerTx
+			if tokenID != common.PRVIDStr && version == 1 && fee == 0 {
+				// the SDK pays MaxInputSize/10 times the token fee of the shard in the token
+				tokenFee, err := cfg.incClient.GetTokenFee(incclient.GetShardIDFromPrivateKey(privateKey), tokenID)
+				if err != nil {
+					return nil, newAppError(EstimateFeeError, fmt.Errorf("token %v: %v", tokenID, err))
+				}
+				plan.FeeTokenID = tokenID
+				plan.EstimatedFee = uint64(plan.EstimatedTxs) * (incclient.MaxInputSize * tokenFee / 10)
+			}
+			plans = append(plans, plan)
+		}
+	}
+
+	return plans, nil
+}
+
+// autoConsolidate consolidates the UTXOs of every token of an account whose number of UTXOs exceeds a threshold, and
+// writes a report of what it did.
+func autoConsolidate(c *cli.Context) error {
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	minUTXOs := c.Int(minUTXOsFlag)
+	if minUTXOs < maxUTXOsAfterConsolidated {
+		return newAppError(UserInputError, fmt.Errorf("minUTXOs must be at least %v", maxUTXOsAfterConsolidated))
+	}
+	numThreads := c.Int(numThreadsFlag)
+	if numThreads <= 0 {
+		return newAppError(NumThreadsError)
+	}
+	fee, err := getCustomTxFee(c)
+	if err != nil {
+		return err
+	}
+	dryRun := c.Bool(dryRunFlag)
+
+	plans, err := planConsolidation(privateKey, minUTXOs, numThreads, fee)
+	if err != nil {
+		return err
+	}
+	report := &consolidationReport{
+		PaymentAddress: incclient.PrivateKeyToPaymentAddress(privateKey, -1),
+		Time:           time.Now().Format(time.RFC3339),
+		MinUTXOs:       minUTXOs,
+		DryRun:         dryRun,
+		Plans:          plans,
+	}
+	if dryRun || len(plans) == 0 {
+		if len(plans) == 0 {
+			log.Printf("No token has more than %v UTXOs of a version, nothing to consolidate\n", minUTXOs)
+		}
+		return printResult(report)
+	}
+
+	for _, plan := range plans {
+		log.Printf("Token %v v%v: %v UTXOs, about %v txs and a fee of %v (%v)\n", plan.TokenName, plan.Version,
+			plan.NumUTXOs, plan.EstimatedTxs, plan.EstimatedFee, getTokenName(plan.FeeTokenID))
+		if plan.MaxSplitTxs != 0 {
+			log.Printf("  including up to %v PRV-split txs, sent only if PRV UTXOs v2 are missing to pay the fees\n",
+				plan.MaxSplitTxs)
+		}
+	}
+	err = yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
+
+	for _, plan := range plans {
+		log.Printf("CONSOLIDATING token %v, version %v\n", plan.TokenName, plan.Version)
+		plan.TxList, err = runConsolidation(privateKey, plan.TokenID, plan.Version, numThreads, fee)
+		if err != nil {
+			plan.Error = err.Error()
+			log.Printf("Token %v v%v: %v\n", plan.TokenName, plan.Version, err)
+		}
+		utxos, _, err := getUTXOsByVersion(privateKey, plan.TokenID, plan.Version)
+		if err == nil {
+			plan.NumUTXOsAfter = len(utxos)
+		}
+	}
+	log.Println("CONSOLIDATING FINISHED!!")
+
+	reportFile := c.String(reportFileFlag)
+	if reportFile != "" {
+		err = writeJSONFile(reportFile, report)
+		if err != nil {
+			return newAppError(SaveOutputFileError, err)
+		}
+		log.Printf("Report saved to %v\n", reportFile)
+	}
+
+	return printResult(report)
+}
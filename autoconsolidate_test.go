@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestEstimateConsolidation(t *testing.T) {
+	testCases := []struct {
+		numUTXOs, numThreads, expectedTxs, expectedRounds int
+	}{
+		{10, 4, 0, 0},
+		{11, 4, 1, 1},
+		{31, 4, 1, 1},
+		{100, 4, 4, 1},
+		{100, 1, 4, 4},
+		{200, 2, 7, 4},
+	}
+
+	for _, tc := range testCases {
+		numTxs, numRounds := estimateConsolidation(tc.numUTXOs, tc.numThreads)
+		if numTxs != tc.expectedTxs || numRounds != tc.expectedRounds {
+			t.Fatalf("%v UTXOs, %v threads: expect (%v txs, %v rounds), got (%v, %v)", tc.numUTXOs, tc.numThreads,
+				tc.expectedTxs, tc.expectedRounds, numTxs, numRounds)
+		}
+	}
+}
@@ -40,6 +40,43 @@ func NewBTCTestNetClient() (*BTCClient, error) {
 	return b, nil
 }
 
+// NewBTCRPCClient returns a BTC client backed by a BTC full-node's JSON-RPC server instead of BlockCypher.
+func NewBTCRPCClient(host, user, password string, disableTLS bool) (*BTCClient, error) {
+	rpcClient, err := rpcclient.New(&rpcclient.ConnConfig{
+		Host:         host,
+		User:         user,
+		Pass:         password,
+		HTTPPostMode: true,
+		DisableTLS:   disableTLS,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BTCClient{rpcClient: rpcClient}, nil
+}
+
+// GetBlockHeight returns the height of the latest BTC block known to the backend of the client.
+func (b *BTCClient) GetBlockHeight() (uint64, error) {
+	if b.isNil() {
+		return 0, ErrBTCClientNotInitialized
+	}
+
+	if b.rpcClient != nil {
+		blkHeight, err := b.rpcClient.GetBlockCount()
+		if err != nil {
+			return 0, err
+		}
+		return uint64(blkHeight), nil
+	}
+
+	chain, err := b.cypherBlockClient.GetChain()
+	if err != nil {
+		return 0, err
+	}
+	return uint64(chain.Height), nil
+}
+
 func (b *BTCClient) isNil() bool {
 	return b.rpcClient == nil && b.cypherBlockClient == nil
 }
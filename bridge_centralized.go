@@ -15,14 +15,17 @@ func shieldCentralized(c *cli.Context) error {
 		return newAppError(InvalidPaymentAddressError)
 	}
 
-	amt := c.Uint64(amountFlag)
-	if amt == 0 {
-		return newAppError(InvalidAmountError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	amt, err := getAmountFlag(c, amountFlag, tokenIDStr)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
+	if amt == 0 {
+		return newAppError(InvalidAmountError)
 	}
 
 	tokenName := c.String(tokenNameFlag)
@@ -33,5 +36,5 @@ func shieldCentralized(c *cli.Context) error {
 		return newAppError(CentralizedShieldError, err)
 	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
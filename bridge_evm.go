@@ -8,6 +8,7 @@ import (
 	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
 	"github.com/urfave/cli/v2"
 	"log"
+	"os"
 	"strings"
 	"time"
 )
@@ -40,14 +41,17 @@ var unShieldMessage = "This function helps withdraw an EVM (ETH/BNB/ERC20/BEP20,
 
 // shield deposits an EVM token (ETH/BNB/ERC20/BEP20) into the Incognito chain.
 func shield(c *cli.Context) error {
-	fmt.Println(shieldMessage)
-	yesNoPrompt("Do you want to continue?")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr, shieldMessage)
+	err := yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr)
 
 	log.Println("[STEP 0] PREPARE DATA")
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	incAddress := c.String(addressFlag)
@@ -75,7 +79,10 @@ func shield(c *cli.Context) error {
 		if strings.Contains(err.Error(), "incTokenID not found") {
 			log.Printf("IncTokenID not found for %v, perhaps it doesn't exist in the Incognito network.\n", tokenAddress.String())
 			incTokenID = fmt.Sprintf("%x", iCommon.RandBytes(32))
-			yesNoPrompt(fmt.Sprintf("Newly generated incTokenID: %v. Do you want to continue with this token?", incTokenID))
+			err = yesNoPrompt(fmt.Sprintf("Newly generated incTokenID: %v. Do you want to continue with this token?", incTokenID))
+			if err != nil {
+				return err
+			}
 		} else {
 			return newAppError(EVMTokenIDToIncognitoTokenIDError, err)
 		}
@@ -109,7 +116,10 @@ func shield(c *cli.Context) error {
 	}
 	log.Printf("Network: %v, TokenName: %v, TokenSymbol: %v, TokenAddress: %v, ShieldAmount: %v",
 		evmNetwork, tokenName, tokenSymbol, tokenAddress.String(), shieldAmount)
-	yesNoPrompt("Do you want to continue?")
+	err = yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
 	log.Printf("[STEP 0] FINISHED!\n\n")
 
 	log.Println("[STEP 1] CHECK INCOGNITO BALANCE")
@@ -123,12 +133,10 @@ func shield(c *cli.Context) error {
 	log.Printf("[STEP 2] IMPORT %v ACCOUNT\n", evmNetwork)
 
 	// Get EVM account
-	var privateEVMKey string
-	input, err := promptInput(fmt.Sprintf("Enter your %v private key", evmNetwork), &privateEVMKey, true)
+	privateEVMKey, err := getEVMPrivateKey(c, evmNetwork)
 	if err != nil {
 		return newAppError(UserInputError, err)
 	}
-	privateEVMKey = string(input)
 	acc, err := NewEVMAccount(privateEVMKey)
 	if err != nil {
 		return newAppError(NewEVMAccountError, err)
@@ -196,18 +204,19 @@ func shield(c *cli.Context) error {
 			log.Println("Shielding SUCCEEDED!!")
 			break
 		} else {
-			panic("Shielding FAILED!!")
+			return newAppError(ShieldingRejectedError, fmt.Errorf("tx %v has shielding status %v", incTxHash, status))
 		}
 	}
 	log.Printf("[STEP 5] FINISHED!\n\n")
-	return nil
+
+	return printTxResult(map[string]interface{}{"EVMTxHash": evmHash.String(), "IncTxHash": incTxHash}, "IncTxHash")
 }
 
 // retryShield retries to shield a token with an already-deposited evm TxHash.
 func retryShield(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	evmNetwork := c.String(evmFlag)
@@ -226,7 +235,10 @@ func retryShield(c *cli.Context) error {
 		if strings.Contains(err.Error(), "incTokenID not found") {
 			log.Printf("IncTokenID not found for %v, perhaps it doesn't exist in the Incognito network.\n", tokenAddress.String())
 			incTokenID = fmt.Sprintf("%x", iCommon.RandBytes(32))
-			yesNoPrompt(fmt.Sprintf("Newly generated incTokenID: %v. Do you want to continue with this token?", incTokenID))
+			err = yesNoPrompt(fmt.Sprintf("Newly generated incTokenID: %v. Do you want to continue with this token?", incTokenID))
+			if err != nil {
+				return err
+			}
 		} else {
 			return newAppError(EVMTokenIDToIncognitoTokenIDError, err)
 		}
@@ -253,37 +265,44 @@ func retryShield(c *cli.Context) error {
 			log.Println("Shielding SUCCEEDED!!")
 			break
 		} else {
-			panic("Shielding FAILED!!")
+			return newAppError(ShieldingRejectedError, fmt.Errorf("tx %v has shielding status %v", incTxHash, status))
 		}
 	}
 	log.Printf("[STEP 2] FINISHED!\n\n")
-	return nil
+
+	return printTxResult(map[string]interface{}{"IncTxHash": incTxHash}, "IncTxHash")
 }
 
 // unShield withdraws an EVM token (ETH/BNB/ERC20/BEP20) from the Incognito chain.
 func unShield(c *cli.Context) error {
-	fmt.Println(unShieldMessage)
-	yesNoPrompt("Do you want to continue?")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr, unShieldMessage)
+	err := yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr)
 
 	log.Println("[STEP 0] PREPARE DATA")
 	// get the private key
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	// get the Incognito tokenID, evmTokenID, name and symbol.
+	incTokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	// get the un-shield amount
-	unShieldAmount := c.Uint64(amountFlag)
+	unShieldAmount, err := getAmountFlag(c, amountFlag, incTokenIDStr)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
 	if unShieldAmount == 0 {
 		return newAppError(InvalidAmountError)
 	}
-
-	// get the Incognito tokenID, evmTokenID, name and symbol.
-	incTokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(incTokenIDStr) {
-		return newAppError(InvalidTokenIDError)
-	}
 	evmTokenIDStr, evmNetworkID, err := getEVMTokenIDIncTokenID(incTokenIDStr)
 	if err != nil {
 		return newAppError(IncognitoTokenIDToEVMTokenIDError, err)
@@ -327,7 +346,10 @@ func unShield(c *cli.Context) error {
 	}
 	log.Printf("Network: %v, TokenName: %v, TokenSymbol: %v, TokenAddress: %v, UnShieldAmount: %v",
 		evmNetwork, tokenName, tokenSymbol, evmTokenAddress.String(), unShieldAmount)
-	yesNoPrompt("Do you want to continue?")
+	err = yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
 	log.Printf("[STEP 0] FINISHED!\n\n")
 
 	log.Println("[STEP 1] CHECK INCOGNITO BALANCE")
@@ -345,12 +367,10 @@ func unShield(c *cli.Context) error {
 	log.Printf("[STEP 2] IMPORT %v ACCOUNT\n", evmNetwork)
 
 	// Get EVM account
-	var privateEVMKey string
-	input, err := promptInput(fmt.Sprintf("Enter your %v private key", evmNetwork), &privateEVMKey, true)
+	privateEVMKey, err := getEVMPrivateKey(c, evmNetwork)
 	if err != nil {
 		return newAppError(UserInputError, err)
 	}
-	privateEVMKey = string(input)
 	acc, err := NewEVMAccount(privateEVMKey)
 	if err != nil {
 		return newAppError(NewEVMAccountError, err)
@@ -362,26 +382,30 @@ func unShield(c *cli.Context) error {
 	nativeBalance, _ := tmpNativeBalance.Float64()
 	log.Printf("Your %v address: %v, %v: %v\n", evmNetwork, acc.address.String(), nativeTokenName, nativeBalance)
 	evmAddress := acc.address
-	var res string
-	resInBytes, err := promptInput(
-		fmt.Sprintf("Un-shield to the following address: %v. Continue? (y/n)", evmAddress.String()),
-		&res)
-	if err != nil {
-		return newAppError(UserInputError, err)
-	}
-	res = string(resInBytes)
-	if !strings.Contains(res, "y") && !strings.Contains(res, "Y") {
-		resInBytes, err = promptInput(
-			fmt.Sprintf("Enter the address you want to un-shield to"),
-			&res)
+	if evmAddressStr := c.String(evmAddressFlag); evmAddressStr != "" {
+		if !isValidEVMAddress(evmAddressStr) {
+			return newAppError(InvalidExternalAddressError)
+		}
+		evmAddress = common.HexToAddress(evmAddressStr)
+	} else {
+		yes, err := appPrompter.Confirm(fmt.Sprintf("Un-shield to the following address: %v. Continue?", evmAddress.String()))
 		if err != nil {
 			return newAppError(UserInputError, err)
 		}
-		res = string(resInBytes)
-		if !isValidEVMAddress(res) {
-			return newAppError(InvalidExternalAddressError)
+		if !yes {
+			var res string
+			resInBytes, err := promptInput(
+				fmt.Sprintf("Enter the address you want to un-shield to"),
+				&res)
+			if err != nil {
+				return newAppError(UserInputError, err)
+			}
+			res = string(resInBytes)
+			if !isValidEVMAddress(res) {
+				return newAppError(InvalidExternalAddressError)
+			}
+			evmAddress = common.HexToAddress(res)
 		}
-		evmAddress = common.HexToAddress(res)
 	}
 	log.Printf("[STEP 2] FINISHED!\n\n")
 
@@ -407,18 +431,21 @@ func unShield(c *cli.Context) error {
 	log.Printf("[STEP 4] FINISHED!\n\n")
 
 	log.Println("[STEP 5] SUBMIT THE BURN PROOF TO THE SC")
-	_, err = acc.UnShield(incTxHash, 0, 0, evmNetworkID)
+	evmHash, err := acc.UnShield(incTxHash, 0, 0, evmNetworkID)
 	if err != nil {
 		return newAppError(EVMWithdrawError, err)
 	}
 	log.Printf("[STEP 5] FINISHED!\n\n")
 
-	return nil
+	return printTxResult(map[string]interface{}{"IncTxHash": incTxHash, "EVMTxHash": evmHash.String()}, "IncTxHash")
 }
 
 // retryUnShield retries to un-shield a token with an already-burned Incognito TxHash.
 func retryUnShield(c *cli.Context) error {
-	yesNoPrompt("Do you want to continue?")
+	err := yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
 
 	incTxHash := c.String(txHashFlag)
 	if incTxHash == "" {
@@ -443,12 +470,10 @@ func retryUnShield(c *cli.Context) error {
 
 	log.Printf("[STEP 1] IMPORT %v ACCOUNT\n", evmNetwork)
 	// Get EVM account
-	var privateEVMKey string
-	input, err := promptInput(fmt.Sprintf("Enter your %v private key", evmNetwork), &privateEVMKey, true)
+	privateEVMKey, err := getEVMPrivateKey(c, evmNetwork)
 	if err != nil {
 		return newAppError(UserInputError, err)
 	}
-	privateEVMKey = string(input)
 	acc, err := NewEVMAccount(privateEVMKey)
 	if err != nil {
 		return newAppError(NewEVMAccountError, err)
@@ -475,11 +500,11 @@ func retryUnShield(c *cli.Context) error {
 	log.Printf("[STEP 2] FINISHED!\n\n")
 
 	log.Println("[STEP 3] SUBMIT THE BURN PROOF TO THE SC")
-	_, err = acc.UnShield(incTxHash, 0, 0, evmNetworkID)
+	evmHash, err := acc.UnShield(incTxHash, 0, 0, evmNetworkID)
 	if err != nil {
 		return newAppError(EVMWithdrawError, err)
 	}
 	log.Printf("[STEP 3] FINISHED!\n\n")
 
-	return nil
+	return printTxResult(map[string]interface{}{"IncTxHash": incTxHash, "EVMTxHash": evmHash.String()}, "IncTxHash")
 }
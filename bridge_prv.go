@@ -8,7 +8,7 @@ import (
 	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
 	"github.com/urfave/cli/v2"
 	"log"
-	"strings"
+	"os"
 	"time"
 )
 
@@ -41,7 +41,7 @@ var unShieldPRVMessage = "This function helps withdraw PRV to an EVM network. "
 var prv20AddressStr string
 
 func prvInitFunc(c *cli.Context) error {
-	err := initNetWork()
+	err := defaultBeforeFunc(c)
 	if err != nil {
 		return err
 	}
@@ -83,14 +83,17 @@ func prvInitFunc(c *cli.Context) error {
 
 // shieldPRV deposits PRV tokens (on ETH/BSC) into the Incognito chain.
 func shieldPRV(c *cli.Context) error {
-	fmt.Println(shieldPRVMessage)
-	yesNoPrompt("Do you want to continue?")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr, shieldPRVMessage)
+	err := yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr)
 
 	log.Println("[STEP 0] PREPARE DATA")
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	incAddress := c.String(addressFlag)
@@ -112,7 +115,10 @@ func shieldPRV(c *cli.Context) error {
 
 	log.Printf("Network: %v, Token: PRV, TokenAddress: %v, ShieldAmount: %v",
 		evmNetwork, prv20AddressStr, shieldAmount)
-	yesNoPrompt("Do you want to continue?")
+	err = yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
 	log.Printf("[STEP 0] FINISHED!\n\n")
 
 	log.Println("[STEP 1] CHECK INCOGNITO BALANCE")
@@ -126,12 +132,10 @@ func shieldPRV(c *cli.Context) error {
 	log.Printf("[STEP 2] IMPORT %v ACCOUNT\n", evmNetwork)
 
 	// Get EVM account
-	var privateEVMKey string
-	input, err := promptInput(fmt.Sprintf("Enter your %v private key", evmNetwork), &privateEVMKey, true)
+	privateEVMKey, err := getEVMPrivateKey(c, evmNetwork)
 	if err != nil {
 		return newAppError(UserInputError, err)
 	}
-	privateEVMKey = string(input)
 	acc, err := NewEVMAccount(privateEVMKey)
 	if err != nil {
 		return newAppError(NewEVMAccountError, err)
@@ -192,18 +196,19 @@ func shieldPRV(c *cli.Context) error {
 			log.Println("Shielding SUCCEEDED!!")
 			break
 		} else {
-			panic("Shielding FAILED!!")
+			return newAppError(ShieldingRejectedError, fmt.Errorf("tx %v has shielding status %v", incTxHash, status))
 		}
 	}
 	log.Printf("[STEP 5] FINISHED!\n\n")
-	return nil
+
+	return printTxResult(map[string]interface{}{"EVMTxHash": evmHash.String(), "IncTxHash": incTxHash}, "IncTxHash")
 }
 
 // retryShieldPRV retries to shield PRV with an already-deposited evm TxHash.
 func retryShieldPRV(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	evmNetwork := c.String(evmFlag)
@@ -232,28 +237,35 @@ func retryShieldPRV(c *cli.Context) error {
 			log.Println("Shielding SUCCEEDED!!")
 			break
 		} else {
-			panic("Shielding FAILED!!")
+			return newAppError(ShieldingRejectedError, fmt.Errorf("tx %v has shielding status %v", incTxHash, status))
 		}
 	}
 	log.Printf("[STEP 2] FINISHED!\n\n")
-	return nil
+
+	return printTxResult(map[string]interface{}{"IncTxHash": incTxHash}, "IncTxHash")
 }
 
 // unShieldPRV withdraws an amount of PRV on the Incognito network and mint to an EVM network.
 func unShieldPRV(c *cli.Context) error {
-	fmt.Println(unShieldPRVMessage)
-	yesNoPrompt("Do you want to continue?")
-	fmt.Println()
+	fmt.Fprintln(os.Stderr, unShieldPRVMessage)
+	err := yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr)
 
 	log.Println("[STEP 0] PREPARE DATA")
 	// get the private key
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	// get the un-shield amount
-	unShieldAmount := c.Uint64(amountFlag)
+	unShieldAmount, err := getAmountFlag(c, amountFlag, iCommon.PRVIDStr)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
 	if unShieldAmount == 0 {
 		return newAppError(InvalidAmountError)
 	}
@@ -266,7 +278,10 @@ func unShieldPRV(c *cli.Context) error {
 
 	log.Printf("Network: %v, Token: PRV, TokenAddress: %v, UnShieldAmount: %v",
 		evmNetwork, prv20AddressStr, unShieldAmount)
-	yesNoPrompt("Do you want to continue?")
+	err = yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
 	log.Printf("[STEP 0] FINISHED!\n\n")
 
 	log.Println("[STEP 1] CHECK INCOGNITO BALANCE")
@@ -281,12 +296,10 @@ func unShieldPRV(c *cli.Context) error {
 	log.Printf("[STEP 2] IMPORT %v ACCOUNT\n", evmNetwork)
 
 	// Get EVM account
-	var privateEVMKey string
-	input, err := promptInput(fmt.Sprintf("Enter your %v private key", evmNetwork), &privateEVMKey, true)
+	privateEVMKey, err := getEVMPrivateKey(c, evmNetwork)
 	if err != nil {
 		return newAppError(UserInputError, err)
 	}
-	privateEVMKey = string(input)
 	acc, err := NewEVMAccount(privateEVMKey)
 	if err != nil {
 		return newAppError(NewEVMAccountError, err)
@@ -309,26 +322,30 @@ func unShieldPRV(c *cli.Context) error {
 	nativeBalance, _ := tmpNativeBalance.Float64()
 	log.Printf("Your %v address: %v, %v: %v\n", evmNetwork, acc.address.String(), nativeTokenName, nativeBalance)
 	evmAddress := acc.address
-	var res string
-	resInBytes, err := promptInput(
-		fmt.Sprintf("Un-shield to the following address: %v. Continue? (y/n)", evmAddress.String()),
-		&res)
-	if err != nil {
-		return newAppError(UnexpectedError, err)
-	}
-	res = string(resInBytes)
-	if !strings.Contains(res, "y") && !strings.Contains(res, "Y") {
-		resInBytes, err = promptInput(
-			fmt.Sprintf("Enter the address you want to un-shield to"),
-			&res)
+	if evmAddressStr := c.String(evmAddressFlag); evmAddressStr != "" {
+		if !isValidEVMAddress(evmAddressStr) {
+			return newAppError(InvalidExternalAddressError)
+		}
+		evmAddress = common.HexToAddress(evmAddressStr)
+	} else {
+		yes, err := appPrompter.Confirm(fmt.Sprintf("Un-shield to the following address: %v. Continue?", evmAddress.String()))
 		if err != nil {
 			return newAppError(UserInputError, err)
 		}
-		res = string(resInBytes)
-		if !isValidEVMAddress(res) {
-			return newAppError(InvalidExternalAddressError)
+		if !yes {
+			var res string
+			resInBytes, err := promptInput(
+				fmt.Sprintf("Enter the address you want to un-shield to"),
+				&res)
+			if err != nil {
+				return newAppError(UserInputError, err)
+			}
+			res = string(resInBytes)
+			if !isValidEVMAddress(res) {
+				return newAppError(InvalidExternalAddressError)
+			}
+			evmAddress = common.HexToAddress(res)
 		}
-		evmAddress = common.HexToAddress(res)
 	}
 	log.Printf("[STEP 2] FINISHED!\n\n")
 
@@ -354,18 +371,21 @@ func unShieldPRV(c *cli.Context) error {
 	log.Printf("[STEP 4] FINISHED!\n\n")
 
 	log.Println("[STEP 5] SUBMIT THE BURN PROOF TO THE SC")
-	_, err = acc.UnShieldPRV(incTxHash, 0, 0, evmNetworkID)
+	evmHash, err := acc.UnShieldPRV(incTxHash, 0, 0, evmNetworkID)
 	if err != nil {
 		return newAppError(EVMMintPRVError, err)
 	}
 	log.Printf("[STEP 5] FINISHED!\n\n")
 
-	return nil
+	return printTxResult(map[string]interface{}{"IncTxHash": incTxHash, "EVMTxHash": evmHash.String()}, "IncTxHash")
 }
 
 // retryUnShieldPRV retries to un-shield PRV with an already-burned Incognito TxHash.
 func retryUnShieldPRV(c *cli.Context) error {
-	yesNoPrompt("Do you want to continue?")
+	err := yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
 
 	incTxHash := c.String(txHashFlag)
 	if !isValidIncTxHash(incTxHash) {
@@ -390,12 +410,10 @@ func retryUnShieldPRV(c *cli.Context) error {
 
 	log.Printf("[STEP 1] IMPORT %v ACCOUNT\n", evmNetwork)
 	// Get EVM account
-	var privateEVMKey string
-	input, err := promptInput(fmt.Sprintf("Enter your %v private key", evmNetwork), &privateEVMKey, true)
+	privateEVMKey, err := getEVMPrivateKey(c, evmNetwork)
 	if err != nil {
 		return newAppError(UserInputError, err)
 	}
-	privateEVMKey = string(input)
 	acc, err := NewEVMAccount(privateEVMKey)
 	if err != nil {
 		return newAppError(NewEVMAccountError, err)
@@ -422,11 +440,11 @@ func retryUnShieldPRV(c *cli.Context) error {
 	log.Printf("[STEP 2] FINISHED!\n\n")
 
 	log.Println("[STEP 3] SUBMIT THE BURN PROOF TO THE SC")
-	_, err = acc.UnShieldPRV(incTxHash, 0, 0, evmNetworkID)
+	evmHash, err := acc.UnShieldPRV(incTxHash, 0, 0, evmNetworkID)
 	if err != nil {
 		return newAppError(EVMMintPRVError, err)
 	}
 	log.Printf("[STEP 3] FINISHED!\n\n")
 
-	return nil
+	return printTxResult(map[string]interface{}{"IncTxHash": incTxHash, "EVMTxHash": evmHash.String()}, "IncTxHash")
 }
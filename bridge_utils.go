@@ -69,9 +69,9 @@ func getEVMTokenInfo(tokenAddressStr string, evmNetworkID int) (*EVMTokenInfo, e
 		return nil, fmt.Errorf("this is a native token")
 	}
 
-	evmClient := cfg.evmClients[evmNetworkID]
-	if evmClient == nil {
-		return nil, errEVMNetworkNotSupported(evmNetworkID)
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return nil, err
 	}
 	res := new(EVMTokenInfo)
 	res.address = tokenAddress
@@ -142,14 +142,17 @@ func NewEVMAccount(hexPrivateKey string) (*EVMAccount, error) {
 func (acc EVMAccount) newTransactionOpts(destAddr common.Address, gasPrice, gasLimit, amount uint64, data []byte, evmNetworkID int) (*bind.TransactOpts, error) {
 	var err error
 
-	evmClient, _ := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, _, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return nil, err
+	}
 
 	// calculate gas price if needed.
 	var gasPriceBigInt *big.Int
 	if gasPrice == 0 {
 		gasPriceBigInt, err = estimateGasPrice(evmNetworkID)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get gasPriceBigInt price")
+			return nil, err
 		}
 	} else {
 		gasPriceBigInt = new(big.Int).SetUint64(gasPrice)
@@ -189,7 +192,10 @@ func (acc EVMAccount) newTransactionOpts(destAddr common.Address, gasPrice, gasL
 
 // estimateDepositGas estimates the gas for depositing a token.
 func (acc EVMAccount) estimateDepositGas(tokenAddress common.Address, depositedAmount *big.Int, incAddress string, evmNetworkID int) (uint64, error) {
-	evmClient, vaultAddress := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, vaultAddress, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return 0, err
+	}
 
 	var gasLimit uint64
 	vaultABI, err := abi.JSON(strings.NewReader(vault.VaultABI))
@@ -235,7 +241,10 @@ func (acc EVMAccount) estimateDepositGas(tokenAddress common.Address, depositedA
 
 // estimateWithdrawalGas estimates the gas for withdrawing a token.
 func (acc EVMAccount) estimateWithdrawalGas(burnProof *incclient.BurnProof, evmNetworkID int) (uint64, error) {
-	evmClient, vaultAddress := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, vaultAddress, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return 0, err
+	}
 
 	vaultABI, err := abi.JSON(strings.NewReader(vault.VaultABI))
 	if err != nil {
@@ -271,11 +280,13 @@ func (acc EVMAccount) estimateWithdrawalGas(burnProof *incclient.BurnProof, evmN
 
 // getBalance returns the balance of a token.
 func (acc EVMAccount) getBalance(tokenAddress common.Address, evmNetworkID int) (*big.Int, *big.Float, error) {
-	evmClient, _ := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, _, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	decimals := uint64(nativeTokenDecimals)
 	var balance *big.Int
-	var err error
 	if tokenAddress.String() == nativeToken {
 		balance, err = evmClient.BalanceAt(context.Background(), acc.address, nil)
 		if err != nil {
@@ -306,7 +317,10 @@ func (acc EVMAccount) getBalance(tokenAddress common.Address, evmNetworkID int)
 
 // getAllowance returns the allowance of an owner to a spender w.r.t to an ERC20 token.
 func (acc EVMAccount) getAllowance(tokenAddress, spender common.Address, evmNetworkID int) (uint64, error) {
-	evmClient, _ := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, _, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return 0, err
+	}
 
 	erc20Instance, err := erc20.NewErc20(tokenAddress, evmClient)
 	if err != nil {
@@ -322,8 +336,10 @@ func (acc EVMAccount) getAllowance(tokenAddress, spender common.Address, evmNetw
 }
 
 func (acc EVMAccount) getGasLimitAndPrice(gasLimit, gasPrice uint64, callMsg ethereum.CallMsg, evmNetworkID int) (*big.Int, uint64, error) {
-	evmClient, _ := getEVMClientAndVaultAddress(evmNetworkID)
-	var err error
+	evmClient, _, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return nil, 0, err
+	}
 
 	// calculate gas price if needed.
 	var gasPriceBigInt *big.Int
@@ -366,7 +382,10 @@ func (acc EVMAccount) checkSufficientBalance(tokenAddress common.Address, requir
 // checkAllowance checks if the allowance of the token address is sufficient w.r.t to the requiredAmount.
 // It also returns the synthesized allowance of the token.
 func (acc EVMAccount) checkAllowance(tokenAddress common.Address, requiredAmount float64, evmNetworkID int) (err error) {
-	_, vaultAddress := getEVMClientAndVaultAddress(evmNetworkID)
+	_, vaultAddress, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return
+	}
 	prefix := "[CheckAllowanceERC20]"
 	switch evmNetworkID {
 	case rpc.BSCNetworkID:
@@ -418,9 +437,9 @@ func (acc EVMAccount) checkAllowance(tokenAddress common.Address, requiredAmount
 }
 
 func wait(tx common.Hash, evmNetworkID int) error {
-	evmClient := cfg.evmClients[evmNetworkID]
-	if evmClient == nil {
-		return errEVMNetworkNotSupported(evmNetworkID)
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return err
 	}
 	for range time.Tick(10 * time.Second) {
 		receipt, err := evmClient.TransactionReceipt(context.Background(), tx)
@@ -440,9 +459,9 @@ func wait(tx common.Hash, evmNetworkID int) error {
 }
 
 func verifyProofAndParseReceipt(iReq *metadata.IssuingEVMRequest, evmNetworkID int) (*types.Receipt, error) {
-	evmClient := cfg.evmClients[evmNetworkID]
-	if evmClient == nil {
-		return nil, errEVMNetworkNotSupported(evmNetworkID)
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return nil, err
 	}
 
 	evmHeader, err := evmClient.HeaderByHash(context.Background(), iReq.BlockHash)
@@ -492,7 +511,10 @@ func verifyProofAndParseReceipt(iReq *metadata.IssuingEVMRequest, evmNetworkID i
 }
 
 func getDecimals(tokenAddress common.Address, evmNetworkID int) (uint64, error) {
-	evmClient := cfg.evmClients[evmNetworkID]
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return 0, err
+	}
 
 	erc20Instance, err := erc20.NewErc20(tokenAddress, evmClient)
 	if err != nil {
@@ -514,8 +536,11 @@ func getSynthesizedAmount(amount *big.Int, decimals uint64) *big.Float {
 		new(big.Float).SetInt(new(big.Int).Exp(new(big.Int).SetUint64(10), new(big.Int).SetUint64(decimals), nil)))
 }
 
-func getEVMClientAndVaultAddress(evmNetworkID int) (evmClient *ethclient.Client, vaultAddress common.Address) {
-	evmClient = cfg.evmClients[evmNetworkID]
+func getEVMClientAndVaultAddress(evmNetworkID int) (evmClient *ethclient.Client, vaultAddress common.Address, err error) {
+	evmClient, err = cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return
+	}
 	vaultAddress = cfg.evmVaultAddresses[evmNetworkID]
 	return
 }
@@ -604,7 +629,7 @@ func estimateGasPrice(evmNetworkID int) (*big.Int, error) {
 		if err == nil {
 			responseData, err := ioutil.ReadAll(response.Body)
 			if err != nil {
-				log.Fatal(err)
+				return nil, newAppError(EstimateEVMGasPriceError, err)
 			}
 
 			mapRes := make(map[string]interface{})
@@ -621,8 +646,16 @@ func estimateGasPrice(evmNetworkID int) (*big.Int, error) {
 			}
 		}
 	}
-	evmClient, _ := getEVMClientAndVaultAddress(evmNetworkID)
-	return evmClient.SuggestGasPrice(context.Background())
+	evmClient, _, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return nil, newAppError(EstimateEVMGasPriceError, err)
+	}
+	gasPrice, err := evmClient.SuggestGasPrice(context.Background())
+	if err != nil {
+		return nil, newAppError(EstimateEVMGasPriceError, err)
+	}
+
+	return gasPrice, nil
 }
 
 func getEVMNetworkIDFromName(networkName string) (int, error) {
@@ -657,21 +690,17 @@ func checkAndChangeRPCEndPoint(evmNetworkID int, err error) error {
 		evmNetwork = "FTM"
 	}
 
-	if strings.Contains(err.Error(), "504 Gateway Timeout") {
-		yesNoPrompt(fmt.Sprintf("Gateway time-out. Do you want to change the %v RPC-endpoint?", evmNetwork))
-		var newRPCEndPoint string
-		var input []byte
-		input, err = promptInput(fmt.Sprintf("Enter new %v RPC endpoint", evmNetwork), &newRPCEndPoint, true)
-		if err != nil {
+	if askUser && strings.Contains(err.Error(), "504 Gateway Timeout") {
+		if yesNoPrompt(fmt.Sprintf("Gateway time-out. Do you want to change the %v RPC-endpoint?", evmNetwork)) != nil {
 			return err
 		}
-		newRPCEndPoint = string(input)
-		cfg.evmClients[evmNetworkID], err = ethclient.Dial(newRPCEndPoint)
+		var newRPCEndPoint string
+		input, err := promptInput(fmt.Sprintf("Enter new %v RPC endpoint", evmNetwork), &newRPCEndPoint, true)
 		if err != nil {
 			return err
 		}
-
-		return nil
+		newRPCEndPoint = string(input)
+		return cfg.setEVMClient(evmNetworkID, newRPCEndPoint)
 	}
 
 	return err
@@ -684,7 +713,10 @@ func checkAndChangeRPCEndPoint(evmNetworkID int, err error) error {
 // DepositNative deposits an amount of ETH/BNB to the Incognito contract.
 func (acc EVMAccount) DepositNative(incAddress string, depositedAmount float64, gasLimit, gasPrice uint64, evmNetworkID int) (*common.Hash, error) {
 	prefix := "[DepositETH]"
-	evmClient, vaultAddress := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, vaultAddress, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return nil, err
+	}
 	switch evmNetworkID {
 	case rpc.BSCNetworkID:
 		prefix = "[DepositBNB]"
@@ -723,8 +755,11 @@ func (acc EVMAccount) DepositNative(incAddress string, depositedAmount float64,
 		return nil, err
 	}
 	if askUser {
-		yesNoPrompt(fmt.Sprintf("%v DepositAmount: %v, GasPrice: %v gWei, DepositFee: %v, TotalAmount: %v. Do you want to continue?",
+		err = yesNoPrompt(fmt.Sprintf("%v DepositAmount: %v, GasPrice: %v gWei, DepositFee: %v, TotalAmount: %v. Do you want to continue?",
 			prefix, depositedAmount, float64(gasPriceBigInt.Uint64())/math.Pow10(9), txFee, requiredAmount))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	auth, err := acc.newTransactionOpts(vaultAddress, gasPriceBigInt.Uint64(), gasLimit, amountBigInt.Uint64(), nil, evmNetworkID)
@@ -748,7 +783,10 @@ func (acc EVMAccount) DepositNative(incAddress string, depositedAmount float64,
 
 // DepositToken shields an amount of ERC20/BEP20 to the Incognito network.
 func (acc EVMAccount) DepositToken(incAddress, tokenAddressStr string, depositedAmount float64, gasLimit, gasPrice uint64, evmNetworkID int) (*common.Hash, error) {
-	evmClient, vaultAddress := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, vaultAddress, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return nil, err
+	}
 	prefix := "[DepositERC20]"
 	switch evmNetworkID {
 	case rpc.BSCNetworkID:
@@ -784,7 +822,7 @@ func (acc EVMAccount) DepositToken(incAddress, tokenAddressStr string, deposited
 	if gasPrice == 0 {
 		gasPriceBigInt, err = estimateGasPrice(evmNetworkID)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get gasPriceBigInt price")
+			return nil, err
 		}
 	} else {
 		gasPriceBigInt = new(big.Int).SetUint64(gasPrice)
@@ -802,8 +840,11 @@ func (acc EVMAccount) DepositToken(incAddress, tokenAddressStr string, deposited
 		return nil, err
 	}
 	if askUser {
-		yesNoPrompt(fmt.Sprintf("%v DepositAmount: %v, GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
+		err = yesNoPrompt(fmt.Sprintf("%v DepositAmount: %v, GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
 			prefix, depositedAmount, float64(gasPriceBigInt.Uint64())/math.Pow10(9), txFee))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	auth, err := acc.newTransactionOpts(vaultAddress, gasPriceBigInt.Uint64(), gasLimit, 0, nil, evmNetworkID)
@@ -837,7 +878,10 @@ func (acc EVMAccount) ApproveERC20(tokenAddress, approved common.Address, approv
 	case rpc.FTMNetworkID:
 		prefix = "[ApproveFTM20]"
 	}
-	evmClient, _ := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, _, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return nil, err
+	}
 
 	erc20Token, err := erc20.NewErc20(tokenAddress, evmClient)
 	if err != nil {
@@ -875,10 +919,16 @@ func (acc EVMAccount) ApproveERC20(tokenAddress, approved common.Address, approv
 		uint64(nativeTokenDecimals),
 	)
 	if askUser {
-		yesNoPrompt(fmt.Sprintf("%v Approve %v to spend %v of token %v. Are you sure?",
+		err = yesNoPrompt(fmt.Sprintf("%v Approve %v to spend %v of token %v. Are you sure?",
 			prefix, approved, approvedAmount, tokenAddress.String()))
-		yesNoPrompt(fmt.Sprintf("%v GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
+		if err != nil {
+			return nil, err
+		}
+		err = yesNoPrompt(fmt.Sprintf("%v GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
 			prefix, float64(gasPriceBigInt.Uint64())/math.Pow10(9), txFee.String()))
+		if err != nil {
+			return nil, err
+		}
 	} else {
 		log.Printf("%v GasPrice: %v, GasLimit %v, TxFee %v\n", prefix, gasPriceBigInt.Uint64(), gasLimit, txFee.String())
 	}
@@ -902,7 +952,10 @@ func (acc EVMAccount) ApproveERC20(tokenAddress, approved common.Address, approv
 func (acc EVMAccount) UnShield(incTxHash string, gasLimit, gasPrice uint64, evmNetworkID int) (*common.Hash, error) {
 	prefix := "[UnShield]"
 
-	evmClient, vaultAddress := getEVMClientAndVaultAddress(evmNetworkID)
+	evmClient, vaultAddress, err := getEVMClientAndVaultAddress(evmNetworkID)
+	if err != nil {
+		return nil, err
+	}
 
 	// load the vault instance
 	v, err := vault.NewVault(vaultAddress, evmClient)
@@ -925,7 +978,7 @@ func (acc EVMAccount) UnShield(incTxHash string, gasLimit, gasPrice uint64, evmN
 	if gasPrice == 0 {
 		gasPriceBigInt, err = estimateGasPrice(evmNetworkID)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get gasPriceBigInt price")
+			return nil, err
 		}
 	} else {
 		gasPriceBigInt = new(big.Int).SetUint64(gasPrice)
@@ -942,8 +995,11 @@ func (acc EVMAccount) UnShield(incTxHash string, gasLimit, gasPrice uint64, evmN
 		return nil, err
 	}
 	if askUser {
-		yesNoPrompt(fmt.Sprintf("%v GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
+		err = yesNoPrompt(fmt.Sprintf("%v GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
 			prefix, float64(gasPriceBigInt.Uint64())/math.Pow10(9), txFee))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	auth, err := acc.newTransactionOpts(vaultAddress, gasPriceBigInt.Uint64(), gasLimit, 0, []byte{}, evmNetworkID)
@@ -976,9 +1032,9 @@ func (acc EVMAccount) UnShield(incTxHash string, gasLimit, gasPrice uint64, evmN
 func Shield(privateKey, pTokenID string, evmTxHashStr string, evmNetworkID int) (string, error) {
 	prefix := "[Shield]"
 
-	evmClient := cfg.evmClients[evmNetworkID]
-	if evmClient == nil {
-		return "", errEVMNetworkNotSupported(evmNetworkID)
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return "", err
 	}
 
 	evmTxHash := common.HexToHash(evmTxHashStr)
@@ -1058,7 +1114,10 @@ func (acc EVMAccount) estimatePRVDepositGas(depositedAmount *big.Int, incAddress
 		return 0, errEVMNetworkNotSupported(evmNetworkID)
 	}
 
-	evmClient := cfg.evmClients[evmNetworkID]
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return 0, err
+	}
 	prv20Address := common.HexToAddress(prv20AddressStr)
 
 	var gasLimit uint64
@@ -1092,7 +1151,10 @@ func (acc EVMAccount) estimatePRVWithdrawalGas(burnProof *incclient.BurnProof, e
 		return 0, errEVMNetworkNotSupported(evmNetworkID)
 	}
 
-	evmClient := cfg.evmClients[evmNetworkID]
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return 0, err
+	}
 	prv20Address := common.HexToAddress(prv20AddressStr)
 
 	prvABI, err := abi.JSON(strings.NewReader(prv20.Prv20ABI))
@@ -1133,7 +1195,10 @@ func (acc EVMAccount) BurnPRVOnEVM(incAddress string, depositedAmount float64, g
 		return nil, errEVMNetworkNotSupported(evmNetworkID)
 	}
 
-	evmClient := cfg.evmClients[evmNetworkID]
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return nil, err
+	}
 	prefix := "[DepositPRVERC20]"
 	switch evmNetworkID {
 	case rpc.BSCNetworkID:
@@ -1163,7 +1228,7 @@ func (acc EVMAccount) BurnPRVOnEVM(incAddress string, depositedAmount float64, g
 	if gasPrice == 0 {
 		gasPriceBigInt, err = estimateGasPrice(evmNetworkID)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get gasPriceBigInt price")
+			return nil, err
 		}
 	} else {
 		gasPriceBigInt = new(big.Int).SetUint64(gasPrice)
@@ -1182,8 +1247,11 @@ func (acc EVMAccount) BurnPRVOnEVM(incAddress string, depositedAmount float64, g
 		return nil, err
 	}
 	if askUser {
-		yesNoPrompt(fmt.Sprintf("%v DepositAmount: %v, GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
+		err = yesNoPrompt(fmt.Sprintf("%v DepositAmount: %v, GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
 			prefix, depositedAmount, float64(gasPriceBigInt.Uint64())/math.Pow10(9), txFee))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	auth, err := acc.newTransactionOpts(prv20Address, gasPriceBigInt.Uint64(), gasLimit, 0, nil, evmNetworkID)
@@ -1215,9 +1283,9 @@ func ShieldPRV(privateKey, evmTxHashStr string, evmNetworkID int) (string, error
 
 	prefix := "[ShieldPRV]"
 
-	evmClient := cfg.evmClients[evmNetworkID]
-	if evmClient == nil {
-		return "", errEVMNetworkNotSupported(evmNetworkID)
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return "", err
 	}
 
 	evmTxHash := common.HexToHash(evmTxHashStr)
@@ -1292,9 +1360,9 @@ func (acc EVMAccount) UnShieldPRV(incTxHash string, gasLimit, gasPrice uint64, e
 
 	prefix := "[UnShieldPRV]"
 
-	evmClient := cfg.evmClients[evmNetworkID]
-	if evmClient == nil {
-		return nil, errEVMNetworkNotSupported(evmNetworkID)
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		return nil, err
 	}
 	prvToken, err := prv20.NewPrv20(common.HexToAddress(prv20AddressStr), evmClient)
 	if err != nil {
@@ -1316,7 +1384,7 @@ func (acc EVMAccount) UnShieldPRV(incTxHash string, gasLimit, gasPrice uint64, e
 	if gasPrice == 0 {
 		gasPriceBigInt, err = estimateGasPrice(evmNetworkID)
 		if err != nil {
-			return nil, fmt.Errorf("cannot get gasPriceBigInt price")
+			return nil, err
 		}
 	} else {
 		gasPriceBigInt = new(big.Int).SetUint64(gasPrice)
@@ -1333,8 +1401,11 @@ func (acc EVMAccount) UnShieldPRV(incTxHash string, gasLimit, gasPrice uint64, e
 		return nil, err
 	}
 	if askUser {
-		yesNoPrompt(fmt.Sprintf("%v GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
+		err = yesNoPrompt(fmt.Sprintf("%v GasPrice: %v gWei, TxFee: %v. Do you want to continue?",
 			prefix, float64(gasPriceBigInt.Uint64())/math.Pow10(9), txFee))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	auth, err := acc.newTransactionOpts(common.HexToAddress(prv20AddressStr), gasPriceBigInt.Uint64(), gasLimit, 0, []byte{}, evmNetworkID)
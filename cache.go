@@ -0,0 +1,563 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// utxoCacheDir is the directory where the SDK stores the UTXO cache, relative to the working directory.
+	utxoCacheDir = ".cache"
+
+	// encryptedCacheExt is the extension of the file holding an encrypted UTXO cache.
+	encryptedCacheExt = ".enc"
+
+	// cacheVerifyBatchSize is the maximum number of coins retrieved by a single request when verifying the cache.
+	cacheVerifyBatchSize = 1000
+)
+
+// cachedTokenFile is the cache of the output coins of a token, as stored by the SDK. PRV and the confidential asset
+// (all the tokens) have their own stream of coin indices; the cache of a token is a subset of the confidential asset's.
+type cachedTokenFile struct {
+	LatestIndex uint64
+	OutCoins    struct {
+		// Data maps the indices of the coins to their base58-encoded bytes.
+		Data map[uint64]string
+	}
+}
+
+// cachedAccountFile is the UTXO cache of an OTA key, as stored by the SDK in a file named after the OTA key.
+type cachedAccountFile struct {
+	OtaKey       string
+	CachedTokens map[string]*cachedTokenFile
+}
+
+// getCacheDirectory returns the UTXO cache directory of the current network.
+func getCacheDirectory() string {
+	if host != "" {
+		return filepath.Join(utxoCacheDir, "custom")
+	}
+	return filepath.Join(utxoCacheDir, cfg.network)
+}
+
+// loadCachedAccount reads the UTXO cache of an OTA key.
+func loadCachedAccount(otaKey string) (*cachedAccountFile, error) {
+	file := filepath.Join(getCacheDirectory(), otaKey)
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		if _, err := os.Stat(getCacheDirectory() + encryptedCacheExt); err == nil {
+			return nil, newAppError(CacheNotFoundError, fmt.Errorf("the cache is encrypted, run `cache decrypt` first"))
+		}
+		return nil, newAppError(CacheNotFoundError, fmt.Errorf("no cache for OTA key %v in %v", otaKey, getCacheDirectory()))
+	}
+
+	res := new(cachedAccountFile)
+	if err := readJSONFile(file, res); err != nil {
+		return nil, newAppError(CacheError, fmt.Errorf("cannot read %v: %v", file, err))
+	}
+	if res.CachedTokens == nil {
+		res.CachedTokens = make(map[string]*cachedTokenFile)
+	}
+
+	return res, nil
+}
+
+// save writes the UTXO cache of an OTA key in the format of the SDK, or removes it if it has no token left.
+func (ac *cachedAccountFile) save() error {
+	file := filepath.Join(getCacheDirectory(), ac.OtaKey)
+	if len(ac.CachedTokens) == 0 {
+		return os.Remove(file)
+	}
+
+	return writeJSONFile(file, ac)
+}
+
+// cachedTokenInfo summarizes the cache of a token.
+type cachedTokenInfo struct {
+	TokenID     string
+	TokenName   string
+	LatestIndex uint64
+	NumCoins    int
+	MinIndex    uint64 `json:",omitempty"`
+	MaxIndex    uint64 `json:",omitempty"`
+}
+
+// newCachedTokenInfo summarizes the cache of a token.
+func newCachedTokenInfo(tokenID string, tc *cachedTokenFile) cachedTokenInfo {
+	res := cachedTokenInfo{
+		TokenID:     tokenID,
+		TokenName:   getTokenName(tokenID),
+		LatestIndex: tc.LatestIndex,
+		NumCoins:    len(tc.OutCoins.Data),
+	}
+	first := true
+	for idx := range tc.OutCoins.Data {
+		if first || idx < res.MinIndex {
+			res.MinIndex = idx
+		}
+		if first || idx > res.MaxIndex {
+			res.MaxIndex = idx
+		}
+		first = false
+	}
+	if tokenID == common.ConfidentialAssetID.String() {
+		res.TokenName = "All tokens"
+	}
+
+	return res
+}
+
+// sortedTokenIDs returns the tokenIDs of a cache, PRV and the confidential asset first.
+func (ac *cachedAccountFile) sortedTokenIDs() []string {
+	rank := func(tokenID string) int {
+		switch tokenID {
+		case common.PRVIDStr:
+			return 0
+		case common.ConfidentialAssetID.String():
+			return 1
+		default:
+			return 2
+		}
+	}
+	res := make([]string, 0)
+	for tokenID := range ac.CachedTokens {
+		res = append(res, tokenID)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if rank(res[i]) != rank(res[j]) {
+			return rank(res[i]) < rank(res[j])
+		}
+		return res[i] < res[j]
+	})
+
+	return res
+}
+
+// cacheList lists the OTA keys having a UTXO cache for the current network.
+func cacheList(_ *cli.Context) error {
+	dir := getCacheDirectory()
+	type cachedAccountSummary struct {
+		OTAKey    string
+		Size      int64
+		Modified  string
+		NumTokens int
+		NumCoins  int
+		Error     string `json:",omitempty"`
+	}
+	res := struct {
+		Directory string
+		Encrypted bool
+		Accounts  []cachedAccountSummary
+	}{Directory: dir, Accounts: make([]cachedAccountSummary, 0)}
+	if _, err := os.Stat(dir + encryptedCacheExt); err == nil {
+		res.Encrypted = true
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return newAppError(CacheError, err)
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		summary := cachedAccountSummary{
+			OTAKey:   f.Name(),
+			Size:     f.Size(),
+			Modified: f.ModTime().Format(time.RFC3339),
+		}
+		ac, err := loadCachedAccount(f.Name())
+		if err != nil {
+			summary.Error = err.Error()
+		} else {
+			summary.NumTokens = len(ac.CachedTokens)
+			for tokenID, tc := range ac.CachedTokens {
+				if tokenID != common.ConfidentialAssetID.String() {
+					summary.NumCoins += len(tc.OutCoins.Data)
+				}
+			}
+		}
+		res.Accounts = append(res.Accounts, summary)
+	}
+
+	return printResult(res)
+}
+
+// cacheInfo shows the tokens covered by the UTXO cache of an OTA key.
+func cacheInfo(c *cli.Context) error {
+	ac, err := loadCachedAccount(c.String(otaKeyFlag))
+	if err != nil {
+		return err
+	}
+
+	tokens := make([]cachedTokenInfo, 0)
+	for _, tokenID := range ac.sortedTokenIDs() {
+		tokens = append(tokens, newCachedTokenInfo(tokenID, ac.CachedTokens[tokenID]))
+	}
+
+	return printResult(map[string]interface{}{
+		"OTAKey": ac.OtaKey,
+		"Tokens": tokens,
+	})
+}
+
+// pruneCachedTokens removes a token from the cache of an OTA key, or all of them if tokenID is empty. Since the tokens
+// share the coin stream of the confidential asset, removing the latter removes every token.
+func (ac *cachedAccountFile) pruneCachedTokens(tokenID string) ([]string, error) {
+	removed := make([]string, 0)
+	for _, cachedTokenID := range ac.sortedTokenIDs() {
+		remove := tokenID == "" || cachedTokenID == tokenID
+		if tokenID == common.ConfidentialAssetID.String() && cachedTokenID != common.PRVIDStr {
+			remove = true
+		}
+		if remove {
+			delete(ac.CachedTokens, cachedTokenID)
+			removed = append(removed, cachedTokenID)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, fmt.Errorf("token %v is not cached", tokenID)
+	}
+
+	return removed, nil
+}
+
+// cachePrune deletes the UTXO cache of an OTA key, or only that of a token.
+func cachePrune(c *cli.Context) error {
+	ac, err := loadCachedAccount(c.String(otaKeyFlag))
+	if err != nil {
+		return err
+	}
+
+	tokenID, err := resolveTokenID(c.String(tokenIDFlag))
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+	if tokenID != "" && !isValidTokenID(tokenID) {
+		return newAppError(InvalidTokenIDError)
+	}
+	removed, err := ac.pruneCachedTokens(tokenID)
+	if err != nil {
+		return newAppError(CacheNotFoundError, err)
+	}
+	if err = ac.save(); err != nil {
+		return newAppError(CacheError, err)
+	}
+	log.Printf("Removed %v tokens from the cache\n", len(removed))
+
+	return printResult(map[string]interface{}{
+		"OTAKey":        ac.OtaKey,
+		"RemovedTokens": removed,
+	})
+}
+
+// cacheRebuild drops the UTXO cache of an OTA key (PRV, the tokens, or both) and re-syncs it from the full-node.
+func cacheRebuild(c *cli.Context) error {
+	address := c.String(addressFlag)
+	if !isValidAddress(address) {
+		return newAppError(InvalidPaymentAddressError)
+	}
+	otaKey := c.String(otaKeyFlag)
+	if !isValidOtaKey(otaKey) {
+		return newAppError(InvalidOTAKeyError)
+	}
+
+	// the tokens are synced together, as the confidential asset
+	syncedTokenIDs := []string{common.PRVIDStr, common.ConfidentialAssetID.String()}
+	tokenID, err := resolveTokenID(c.String(tokenIDFlag))
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+	switch {
+	case tokenID == "":
+	case tokenID == common.PRVIDStr:
+		syncedTokenIDs = syncedTokenIDs[:1]
+	case isValidTokenID(tokenID):
+		log.Println("All the tokens share the same coin stream, they are rebuilt together")
+		syncedTokenIDs = syncedTokenIDs[1:]
+	default:
+		return newAppError(InvalidTokenIDError)
+	}
+
+	ac, err := loadCachedAccount(otaKey)
+	if err == nil {
+		for _, syncedTokenID := range syncedTokenIDs {
+			if _, ok := ac.CachedTokens[syncedTokenID]; ok {
+				_, _ = ac.pruneCachedTokens(syncedTokenID)
+			}
+		}
+		if err = ac.save(); err != nil {
+			return newAppError(CacheError, err)
+		}
+	} else if !errors.Is(err, newAppError(CacheNotFoundError)) {
+		return err
+	}
+
+	outCoinKey := rpc.NewOutCoinKey(address, otaKey, "")
+	for _, syncedTokenID := range syncedTokenIDs {
+		log.Printf("Syncing the output coins of %v\n", getTokenName(syncedTokenID))
+		if _, _, err = cfg.incClient.GetAndCacheOutCoins(outCoinKey, syncedTokenID); err != nil {
+			return newAppError(CacheError, fmt.Errorf("cannot sync %v: %v", syncedTokenID, err))
+		}
+	}
+
+	return cacheInfo(c)
+}
+
+// cachedTokenVerification is the result of the verification of the cache of a token.
+type cachedTokenVerification struct {
+	TokenID     string
+	NumCoins    int
+	LatestIndex uint64
+	// NumOTACoins is the number of coins of the shard known by the full-node.
+	NumOTACoins uint64 `json:",omitempty"`
+	Mismatched  []uint64
+	NotOwned    []uint64
+	Invalid     []uint64
+	Valid       bool
+}
+
+// decodeCachedCoin decodes a coin of the cache.
+func decodeCachedCoin(encoded string) (*coin.CoinV2, error) {
+	rawCoin, _, err := base58.Base58Check{}.Decode(encoded)
+	if err != nil {
+		return nil, err
+	}
+	tmpCoin, err := coin.NewCoinFromByte(rawCoin)
+	if err != nil {
+		return nil, err
+	}
+	res, ok := tmpCoin.(*coin.CoinV2)
+	if !ok {
+		return nil, fmt.Errorf("not a coin v2")
+	}
+
+	return res, nil
+}
+
+// verifyCachedToken checks the coins of the cache of a token (PRV or the confidential asset) against the full-node,
+// and that they belong to the OTA key.
+func verifyCachedToken(tokenID string, tc *cachedTokenFile, w *wallet.KeyWallet, shardID byte) (*cachedTokenVerification, error) {
+	res := &cachedTokenVerification{
+		TokenID:     tokenID,
+		NumCoins:    len(tc.OutCoins.Data),
+		LatestIndex: tc.LatestIndex,
+		Mismatched:  make([]uint64, 0),
+		NotOwned:    make([]uint64, 0),
+		Invalid:     make([]uint64, 0),
+	}
+	numOTACoins, err := cfg.incClient.GetOTACoinLengthByShard(shardID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+	res.NumOTACoins = numOTACoins
+
+	indices := make([]uint64, 0)
+	for idx := range tc.OutCoins.Data {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	for start := 0; start < len(indices); start += cacheVerifyBatchSize {
+		end := start + cacheVerifyBatchSize
+		if end > len(indices) {
+			end = len(indices)
+		}
+		remoteCoins, err := cfg.incClient.GetOTACoinsByIndices(shardID, tokenID, indices[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, idx := range indices[start:end] {
+			cachedCoin, err := decodeCachedCoin(tc.OutCoins.Data[idx])
+			if err != nil {
+				res.Invalid = append(res.Invalid, idx)
+				continue
+			}
+			if remoteCoin, ok := remoteCoins[idx]; !ok || string(remoteCoin.Bytes()) != string(cachedCoin.Bytes()) {
+				res.Mismatched = append(res.Mismatched, idx)
+			}
+			if belongs, _ := cachedCoin.DoesCoinBelongToKeySet(&w.KeySet); !belongs {
+				res.NotOwned = append(res.NotOwned, idx)
+			}
+		}
+		log.Printf("Verified %v/%v coins of %v\n", end, len(indices), getTokenName(tokenID))
+	}
+	res.Valid = len(res.Mismatched) == 0 && len(res.NotOwned) == 0 && len(res.Invalid) == 0 &&
+		(numOTACoins == 0 || tc.LatestIndex < numOTACoins)
+
+	return res, nil
+}
+
+// cacheVerify checks the cached coins of an OTA key against the full-node.
+func cacheVerify(c *cli.Context) error {
+	ac, err := loadCachedAccount(c.String(otaKeyFlag))
+	if err != nil {
+		return err
+	}
+	w, err := wallet.Base58CheckDeserialize(ac.OtaKey)
+	if err != nil || w.KeySet.OTAKey.GetPublicSpend() == nil {
+		return newAppError(InvalidOTAKeyError)
+	}
+	pk := w.KeySet.OTAKey.GetPublicSpend().ToBytesS()
+	shardID := common.GetShardIDFromLastByte(pk[len(pk)-1])
+
+	res := make([]*cachedTokenVerification, 0)
+	for _, tokenID := range []string{common.PRVIDStr, common.ConfidentialAssetID.String()} {
+		tc, ok := ac.CachedTokens[tokenID]
+		if !ok {
+			continue
+		}
+		verification, err := verifyCachedToken(tokenID, tc, w, shardID)
+		if err != nil {
+			return newAppError(CacheError, fmt.Errorf("cannot verify %v: %v", tokenID, err))
+		}
+		res = append(res, verification)
+	}
+
+	// the cache of each token must be a subset of that of the confidential asset
+	assetCache := ac.CachedTokens[common.ConfidentialAssetID.String()]
+	orphans := make(map[string][]uint64)
+	for tokenID, tc := range ac.CachedTokens {
+		if tokenID == common.PRVIDStr || tokenID == common.ConfidentialAssetID.String() {
+			continue
+		}
+		for idx, encoded := range tc.OutCoins.Data {
+			if assetCache == nil || assetCache.OutCoins.Data[idx] != encoded {
+				orphans[tokenID] = append(orphans[tokenID], idx)
+			}
+		}
+	}
+
+	valid := len(orphans) == 0
+	for _, verification := range res {
+		valid = valid && verification.Valid
+	}
+	if !valid {
+		log.Println("The cache is inconsistent, use `cache rebuild` to re-sync it")
+	}
+
+	return printResult(map[string]interface{}{
+		"OTAKey":        ac.OtaKey,
+		"Valid":         valid,
+		"Verifications": res,
+		"OrphanCoins":   orphans,
+	})
+}
+
+// cacheEncrypt moves the UTXO cache of the current network into a single file encrypted with a passphrase. The SDK
+// cannot use the encrypted cache until it is decrypted.
+func cacheEncrypt(_ *cli.Context) error {
+	dir := getCacheDirectory()
+	encryptedFile := dir + encryptedCacheExt
+	if _, err := os.Stat(encryptedFile); err == nil {
+		return newAppError(CacheError, fmt.Errorf("%v already exists, run `cache decrypt` first", encryptedFile))
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return newAppError(CacheNotFoundError, err)
+	}
+	contents := make(map[string]json.RawMessage)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return newAppError(CacheError, err)
+		}
+		if !json.Valid(data) {
+			return newAppError(CacheError, fmt.Errorf("%v is not a cache file", f.Name()))
+		}
+		contents[f.Name()] = data
+	}
+	if len(contents) == 0 {
+		return newAppError(CacheNotFoundError, fmt.Errorf("no cache found in %v", dir))
+	}
+
+	passphrase, err := promptNewPassphrase("the UTXO cache")
+	if err != nil {
+		return newAppError(UserInputError, err)
+	}
+	plainText, err := json.Marshal(contents)
+	if err != nil {
+		return newAppError(CacheError, err)
+	}
+	encrypted, err := encryptWithPassphrase(plainText, passphrase, defaultScryptN, defaultScryptR, defaultScryptP)
+	if err != nil {
+		return newAppError(CacheError, err)
+	}
+	if err = writeJSONFile(encryptedFile, encrypted); err != nil {
+		return newAppError(SaveOutputFileError, err)
+	}
+	for name := range contents {
+		if err = os.Remove(filepath.Join(dir, name)); err != nil {
+			return newAppError(CacheError, err)
+		}
+	}
+	log.Printf("Encrypted the cache of %v OTA keys to %v\n", len(contents), encryptedFile)
+
+	return nil
+}
+
+// cacheDecrypt restores the UTXO cache encrypted by cacheEncrypt. A cache file created since the encryption is kept
+// over its encrypted version.
+func cacheDecrypt(_ *cli.Context) error {
+	dir := getCacheDirectory()
+	encryptedFile := dir + encryptedCacheExt
+	var encrypted encryptedData
+	if err := readJSONFile(encryptedFile, &encrypted); err != nil {
+		return newAppError(DecryptFileError, err)
+	}
+
+	passphrase, err := promptInput("Enter the passphrase of the UTXO cache", new(string), true)
+	if err != nil {
+		return newAppError(UserInputError, err)
+	}
+	plainText, err := decryptWithPassphrase(&encrypted, passphrase)
+	if err != nil {
+		return newAppError(DecryptFileError, err)
+	}
+	contents := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(plainText, &contents); err != nil {
+		return newAppError(DecryptFileError, err)
+	}
+
+	if err = os.MkdirAll(dir, os.ModePerm); err != nil {
+		return newAppError(CacheError, err)
+	}
+	for name, data := range contents {
+		file := filepath.Join(dir, filepath.Base(name))
+		if _, err := os.Stat(file); err == nil {
+			log.Printf("%v has been re-created since the encryption, keeping it\n", file)
+			continue
+		}
+		if err = ioutil.WriteFile(file, data, 0600); err != nil {
+			return newAppError(CacheError, err)
+		}
+	}
+	if err = os.Remove(encryptedFile); err != nil {
+		return newAppError(CacheError, err)
+	}
+	log.Printf("Decrypted the cache of %v OTA keys to %v\n", len(contents), dir)
+
+	return nil
+}
+
+// cacheBeforeFunc sets up the network with the UTXO cache enabled.
+func cacheBeforeFunc(c *cli.Context) error {
+	cache = 1
+	return defaultBeforeFunc(c)
+}
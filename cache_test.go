@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+)
+
+func newTestCachedAccount() *cachedAccountFile {
+	newTokenFile := func(latestIndex uint64, indices ...uint64) *cachedTokenFile {
+		res := &cachedTokenFile{LatestIndex: latestIndex}
+		res.OutCoins.Data = make(map[uint64]string)
+		for _, idx := range indices {
+			res.OutCoins.Data[idx] = "coin"
+		}
+		return res
+	}
+
+	return &cachedAccountFile{
+		OtaKey: "otaKey",
+		CachedTokens: map[string]*cachedTokenFile{
+			common.PRVIDStr:                     newTokenFile(5353, 5331, 5353),
+			common.ConfidentialAssetID.String(): newTokenFile(2011, 2004, 2005, 2006),
+			"token1":                            newTokenFile(2011, 2005, 2006),
+			"token2":                            newTokenFile(2011, 2004),
+		},
+	}
+}
+
+func TestCachedTokenInfo(t *testing.T) {
+	ac := newTestCachedAccount()
+	tokenIDs := ac.sortedTokenIDs()
+	expected := []string{common.PRVIDStr, common.ConfidentialAssetID.String(), "token1", "token2"}
+	for i := range expected {
+		if tokenIDs[i] != expected[i] {
+			t.Fatalf("expect tokenIDs %v, got %v", expected, tokenIDs)
+		}
+	}
+
+	info := newCachedTokenInfo("token1", ac.CachedTokens["token1"])
+	if info.NumCoins != 2 || info.MinIndex != 2005 || info.MaxIndex != 2006 || info.LatestIndex != 2011 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+}
+
+func TestPruneCachedTokens(t *testing.T) {
+	testCases := []struct {
+		tokenID   string
+		remaining int
+		isValid   bool
+	}{
+		{"token1", 3, true},
+		{common.PRVIDStr, 3, true},
+		{common.ConfidentialAssetID.String(), 1, true},
+		{"", 0, true},
+		{"token3", 4, false},
+	}
+
+	for _, tc := range testCases {
+		ac := newTestCachedAccount()
+		removed, err := ac.pruneCachedTokens(tc.tokenID)
+		if (err == nil) != tc.isValid {
+			t.Fatalf("token %q: expect valid = %v, got %v", tc.tokenID, tc.isValid, err)
+		}
+		if len(ac.CachedTokens) != tc.remaining || len(removed)+tc.remaining != 4 && tc.isValid {
+			t.Fatalf("token %q: expect %v tokens left, got %v (removed %v)", tc.tokenID, tc.remaining, len(ac.CachedTokens), removed)
+		}
+	}
+}
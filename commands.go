@@ -5,10 +5,261 @@ import (
 	"github.com/incognitochain/go-incognito-sdk-v2/common"
 	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
 	"github.com/urfave/cli/v2"
+	"strings"
 )
 
 var dexStatusErrMsg = "If an error is thrown, it is mainly because the transaction has not yet reached the beacon chain or the txHash is invalid."
 
+// configCommands consists of all commands managing the CLI config file.
+var configCommands = []*cli.Command{
+	{
+		Name:  "config",
+		Usage: "Manage the CLI config profiles.",
+		Description: fmt.Sprintf("This command helps manage the named profiles stored in the CLI config file "+
+			"(~/.incognito-cli/config.yaml). The values of the active profile (or the one given by the global `profile` flag) "+
+			"are used for the global flags not explicitly set, as well as for the EVM endpoints, vault addresses and BTC backend. "+
+			"Supported keys: %v.", strings.Join(profileKeys, ", ")),
+		Category: configCat,
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List all profiles.",
+				Action: configList,
+			},
+			{
+				Name:  "get",
+				Usage: "Print a key (or the whole profile if no key is given) of the active profile.",
+				Flags: []cli.Flag{
+					defaultFlags[configKeyFlag],
+					defaultFlags[revealFlag],
+				},
+				Action: configGet,
+			},
+			{
+				Name:  "set",
+				Usage: "Set a key of the active profile.",
+				Description: "This command sets a key of the active profile (or the one given by the global `profile` flag). " +
+					"The profile is created if it does not exist yet; the first profile created becomes the active one.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     configKeyFlag,
+						Usage:    "A config key (e.g, network, host, evm.ETH.host)",
+						Required: true,
+					},
+					defaultFlags[configValueFlag],
+				},
+				Action: configSet,
+			},
+			{
+				Name:  "use",
+				Usage: "Set the active profile.",
+				Flags: []cli.Flag{
+					defaultFlags[profileNameFlag],
+				},
+				Action: configUse,
+			},
+		},
+	},
+}
+
+// networkCommands consists of all commands checking the network environment.
+var networkCommands = []*cli.Command{
+	{
+		Name:     "network",
+		Usage:    "Check the network environment.",
+		Category: networkCat,
+		Subcommands: []*cli.Command{
+			{
+				Name:  "status",
+				Usage: "Probe all configured endpoints.",
+				Description: "This command probes the Incognito full-node, the RPC endpoint of each EVM network and the BTC backend " +
+					"of the current environment, and reports the latency, chain-id and block height of each of them.",
+				Action: networkStatus,
+				Before: networkBeforeFunc,
+			},
+		},
+	},
+}
+
+// cacheCommands consists of all commands managing the local UTXO cache.
+var cacheCommands = []*cli.Command{
+	{
+		Name:  "cache",
+		Usage: "Manage the local UTXO cache.",
+		Description: fmt.Sprintf("This command helps manage the UTXO cache written by the SDK when the global `%v` flag is set. "+
+			"The cache of each OTA key is stored in a file named after the key, under the %v directory of the working "+
+			"directory (one sub-directory per network). PRV and the tokens are synced separately: the coins of all the "+
+			"tokens come from the same stream of indices, and are cached as a whole and for each token.", cacheFlag, utxoCacheDir),
+		Category: configCat,
+		Subcommands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "List the OTA keys having a UTXO cache.",
+				Action: cacheList,
+				Before: networkBeforeFunc,
+			},
+			{
+				Name:  "info",
+				Usage: "Show the tokens covered by the UTXO cache of an OTA key.",
+				Flags: []cli.Flag{
+					defaultFlags[otaKeyFlag],
+				},
+				Action: cacheInfo,
+				Before: networkBeforeFunc,
+			},
+			{
+				Name:  "prune",
+				Usage: "Delete the UTXO cache of an OTA key, or that of a token.",
+				Description: "This command deletes the UTXO cache of an OTA key, or only that of a token if a tokenID is " +
+					"given. A pruned token is only synced again by the `cache rebuild` command, which re-syncs all the tokens.",
+				Flags: []cli.Flag{
+					defaultFlags[otaKeyFlag],
+					&cli.StringFlag{
+						Name:    tokenIDFlag,
+						Aliases: aliases[tokenIDFlag],
+						Usage:   "The Incognito ID of the token to remove from the cache (default: all the tokens)",
+					},
+				},
+				Action: cachePrune,
+				Before: networkBeforeFunc,
+			},
+			{
+				Name:  "rebuild",
+				Usage: "Re-sync the UTXO cache of an OTA key from the full-node.",
+				Description: "This command drops the UTXO cache of an OTA key and syncs it again from the full-node. With " +
+					"the PRV tokenID, only PRV is re-synced; with any other tokenID, all the tokens are re-synced.",
+				Flags: []cli.Flag{
+					defaultFlags[addressFlag],
+					defaultFlags[otaKeyFlag],
+					&cli.StringFlag{
+						Name:    tokenIDFlag,
+						Aliases: aliases[tokenIDFlag],
+						Usage:   "The Incognito ID of the token to re-sync (default: PRV and all the tokens)",
+					},
+				},
+				Action: cacheRebuild,
+				Before: cacheBeforeFunc,
+			},
+			{
+				Name:  "verify",
+				Usage: "Verify the UTXO cache of an OTA key against the full-node.",
+				Description: "This command retrieves the cached coins of an OTA key from the full-node, and checks that they " +
+					"are identical, that they belong to the OTA key, and that the cache of each token is consistent with " +
+					"that of all the tokens.",
+				Flags: []cli.Flag{
+					defaultFlags[otaKeyFlag],
+				},
+				Action: cacheVerify,
+				Before: defaultBeforeFunc,
+			},
+			{
+				Name:  "encrypt",
+				Usage: "Encrypt the UTXO cache of the network with a passphrase.",
+				Description: "The UTXO cache links OTA keys to their coins. This command moves the cache of the current " +
+					"network into a single file encrypted with a passphrase. The cache cannot be used until it is " +
+					"decrypted with the `cache decrypt` command.",
+				Action: cacheEncrypt,
+				Before: networkBeforeFunc,
+			},
+			{
+				Name:   "decrypt",
+				Usage:  "Decrypt the UTXO cache encrypted by the `cache encrypt` command.",
+				Action: cacheDecrypt,
+				Before: networkBeforeFunc,
+			},
+		},
+	},
+}
+
+// daemonCommands consists of the commands running and querying the background sync daemon.
+var daemonCommands = []*cli.Command{
+	{
+		Name:  "daemon",
+		Usage: "Run a daemon syncing accounts in the background and serving them over a local JSON-RPC API.",
+		Description: fmt.Sprintf("This command keeps the Incognito client warm and syncs the UTXOs of the registered "+
+			"keystore accounts at every %v, using the UTXO cache. It serves the methods status, balance, utxos, history, "+
+			"send and pdexQuote over a JSON-RPC 2.0 API on a loopback address; the address and the token authenticating "+
+			"the requests are written to the %v file of the CLI home directory. While the daemon is running, the "+
+			"`balance`, `utxo`, `history`, `send` and `pdeinfo checkprice` commands of the same network use it for "+
+			"the registered accounts. To run it without a terminal (e.g, as a service), give the passphrase of the "+
+			"accounts with the global %v flag or the %v environment variable. The daemon stops with Ctrl+C or "+
+			"SIGTERM.", syncIntervalFlag, daemonInfoFileName, passphraseFlag, keystorePassphraseEnv),
+		Category: configCat,
+		Flags: []cli.Flag{
+			defaultFlags[accountsFlag],
+			defaultFlags[listenFlag],
+			defaultFlags[syncIntervalFlag],
+			defaultFlags[numThreadsFlag],
+		},
+		Action: runDaemon,
+		Subcommands: []*cli.Command{
+			{
+				Name:   "status",
+				Usage:  "Show the accounts synced by the running daemon.",
+				Action: daemonStatusCmd,
+				Before: networkBeforeFunc,
+			},
+		},
+	},
+}
+
+// tokenCommands consists of the commands managing the local token list.
+var tokenCommands = []*cli.Command{
+	{
+		Name:  "token",
+		Usage: "Manage the local token list.",
+		Description: fmt.Sprintf("This command helps manage the list of tokens used to resolve token symbols (e.g, "+
+			"`--tokenID USDT`) and to print amounts with their decimals. The list of each network is stored in the %v "+
+			"directory of the CLI home directory, and is only updated by the `refresh` sub-command; without it, only "+
+			"PRV and, on the main-net, a bundled list of the main bridged tokens are known. A symbol resolves to a verified token only; if several verified tokens share a symbol, the "+
+			"unified one is chosen, and the others are selected by suffixing the network (e.g, USDT:BSC).", tokenRegistryDir),
+		Category: configCat,
+		Subcommands: []*cli.Command{
+			{
+				Name:  "list",
+				Usage: "List the tokens of the local token list.",
+				Flags: []cli.Flag{
+					defaultFlags[verifiedOnlyFlag],
+				},
+				Action: tokenList,
+				Before: networkBeforeFunc,
+			},
+			{
+				Name:  "search",
+				Usage: "Search the local token list by symbol, name or tokenID.",
+				Flags: []cli.Flag{
+					defaultFlags[queryFlag],
+					defaultFlags[verifiedOnlyFlag],
+				},
+				Action: tokenSearch,
+				Before: networkBeforeFunc,
+			},
+			{
+				Name:  "info",
+				Usage: "Show a token of the local token list.",
+				Flags: []cli.Flag{
+					defaultFlags[tokenIDFlag],
+				},
+				Action: tokenInfo,
+				Before: networkBeforeFunc,
+			},
+			{
+				Name:  "refresh",
+				Usage: "Update the local token list.",
+				Description: "This command downloads the token list of the current network (from the coinservice by " +
+					"default on the main-net), or imports it from a JSON file for an offline machine, and replaces the " +
+					"local token list with it.",
+				Flags: []cli.Flag{
+					defaultFlags[tokenListURLFlag],
+					defaultFlags[tokenListFileFlag],
+				},
+				Action: tokenRefresh,
+				Before: networkBeforeFunc,
+			},
+		},
+	},
+}
+
 // accountCommands consists of all account-related commands
 var accountCommands = []*cli.Command{
 	{
@@ -22,18 +273,17 @@ var accountCommands = []*cli.Command{
 				Name:  "keyinfo",
 				Usage: "Print all related-keys of a private key.",
 				Flags: []cli.Flag{
-					&cli.StringFlag{
-						Name:     privateKeyFlag,
-						Aliases:  aliases[privateKeyFlag],
-						Usage:    "a base58-encoded private key",
-						Required: true,
-					},
+					defaultFlags[privateKeyFlag],
 				},
 				Action: keyInfo,
 			},
 			{
 				Name:  "balance",
 				Usage: "Check the balance of an account for a tokenID.",
+				Description: "This command checks the balance of an account w.r.t a tokenID. It also works with a watch-only " +
+					"keystore account (via the global account flag), in which case only UTXOs v2 are counted and coins are " +
+					"considered as spent only if their key images have been imported (see exportkeyimages); the result is then " +
+					"marked Unverified, with the SpentUnknownBalance of the coins whose key images are missing.",
 				Flags: []cli.Flag{
 					defaultFlags[privateKeyFlag],
 					defaultFlags[tokenIDFlag],
@@ -66,9 +316,28 @@ var accountCommands = []*cli.Command{
 			{
 				Name:  "utxo",
 				Usage: "Print the UTXOs of an account.",
+				Description: "This command prints the UTXOs of an account w.r.t a tokenID. With a watch-only keystore account, " +
+					"only UTXOs v2 are listed and their key images are shown only if they have been imported; the UTXOs " +
+					"without a key image are marked SpentUnknown and the result Unverified. " +
+					"The UTXOs can be filtered by version and value, and sorted by index or value; the summary (balances, " +
+					"dust and histogram) only covers the listed UTXOs. With the checkSpent flag, the key images of the " +
+					"listed UTXOs are checked against the full-node. The result can be exported to a JSON or CSV file.",
 				Flags: []cli.Flag{
 					defaultFlags[privateKeyFlag],
 					defaultFlags[tokenIDFlag],
+					&cli.IntFlag{
+						Name:    versionFlag,
+						Aliases: aliases[versionFlag],
+						Usage:   "Only list the UTXOs of this version (1 or 2; 0: all versions)",
+						Value:   0,
+					},
+					defaultFlags[minValueFlag],
+					defaultFlags[maxValueFlag],
+					defaultFlags[sortByFlag],
+					defaultFlags[histogramFlag],
+					defaultFlags[dustThresholdFlag],
+					defaultFlags[checkSpentFlag],
+					defaultFlags[exportFileFlag],
 				},
 				Action: checkUTXOs,
 				Before: defaultBeforeFunc,
@@ -78,32 +347,61 @@ var accountCommands = []*cli.Command{
 				Aliases: []string{"csl"},
 				Usage:   "Consolidate UTXOs of an account.",
 				Description: "This command helps consolidate UTXOs of an account. It consolidates a version of UTXOs at a time, users need to specify which version they need to consolidate. " +
+					"When a fee is set (by the fee flag or the active profile), transactions are sent one after another and pay this PRV fee, token UTXOs v1 included. " +
 					"Please note that this process is time-consuming and requires a considerable amount of CPU.",
 				Flags: []cli.Flag{
 					defaultFlags[privateKeyFlag],
 					defaultFlags[tokenIDFlag],
 					defaultFlags[versionFlag],
 					defaultFlags[numThreadsFlag],
+					defaultFlags[feeFlag],
 				},
 				Action: consolidateUTXOs,
 				Before: defaultBeforeFunc,
 			},
+			{
+				Name:    "autoconsolidate",
+				Aliases: []string{"acsl"},
+				Usage:   "Consolidate all tokens of an account having too many UTXOs.",
+				Description: "This command inspects the UTXOs of every token of an account (PRV, and the tokens with a non-zero " +
+					"v2 balance), and consolidates each token and version whose number of UTXOs exceeds minUTXOs. It first shows " +
+					"the plan with the estimated number of transactions and fee, and asks for confirmation; use dryRun to only " +
+					"show the plan. For token UTXOs v2, the estimate includes the PRV-split transactions the SDK may send at each round, " +
+					"so it is an upper bound. A report of the consolidation is written to reportFile. Please note that this process is " +
+					"time-consuming and requires a considerable amount of CPU.",
+				Flags: []cli.Flag{
+					defaultFlags[privateKeyFlag],
+					defaultFlags[minUTXOsFlag],
+					defaultFlags[numThreadsFlag],
+					defaultFlags[feeFlag],
+					defaultFlags[dryRunFlag],
+					defaultFlags[reportFileFlag],
+				},
+				Action: autoConsolidate,
+				Before: defaultBeforeFunc,
+			},
 			{
 				Name:    "history",
 				Aliases: []string{"hst"},
 				Usage:   "Retrieve the history of an account.",
 				Description: "This command helps retrieve the history of an account w.r.t a tokenID. " +
-					"Please note that this process is time-consuming and requires a considerable amount of CPU.",
+					"The history is stored locally, so that only the transactions newer than the last run are retrieved; " +
+					"the first run is time-consuming and requires a considerable amount of CPU. " +
+					"With a watch-only keystore account, only in-coming transactions (v2) are listed; the change of the " +
+					"account's own transfers is excluded only if the key images of the spent coins have been imported.",
 				Flags: []cli.Flag{
 					defaultFlags[privateKeyFlag],
 					&cli.StringFlag{
 						Aliases: aliases[tokenIDFlag],
 						Name:    tokenIDFlag,
-						Usage:   "ID of the token",
+						Usage:   "ID or symbol of the token",
 						Value:   common.PRVIDStr,
 					},
 					defaultFlags[numThreadsFlag],
 					defaultFlags[csvFileFlag],
+					defaultFlags[fromFlag],
+					defaultFlags[toFlag],
+					defaultFlags[resyncFlag],
 				},
 				Action: getHistory,
 				Before: defaultBeforeFunc,
@@ -113,21 +411,48 @@ var accountCommands = []*cli.Command{
 				Aliases: []string{"finext"},
 				Usage:   "Export the financial history of an account.",
 				Description: "This command helps export the financial history of an account. " +
-					"Please note that this process is time-consuming and requires a considerable amount of CPU. The more " +
+					"The history is stored locally, so that only the transactions newer than the last run are retrieved. " +
+					"Please note that the first run is time-consuming and requires a considerable amount of CPU. The more " +
 					"transactions you have, the more time it takes to build up the report. If you want to see the log, " +
-					"use the global `debug` flag `--d 1`. Use this command with the main-net network for the best result.",
+					"use the global `debug` flag `--d 1`. Use this command with the main-net network for the best result. " +
+					"The exportProfile flag selects the layout (Koinly, CoinTracking, beancount, JSON); pDEX trades, shields, " +
+					"unshields, rewards, etc. are classified by type, a trade request being merged with its response. " +
+					"With a priceFile, each transaction is also valued in the fiat currency.",
 				Flags: []cli.Flag{
 					defaultFlags[privateKeyFlag],
 					defaultFlags[numThreadsFlag],
 					&cli.StringFlag{
 						Name:    csvFileFlag,
 						Aliases: aliases[csvFileFlag],
-						Usage:   "The csv file location to store the history",
+						Usage:   "The file location to store the history (by default, with a .json or .beancount extension for these profiles)",
 						Value:   incclient.DefaultTxHistory,
 					},
+					defaultFlags[fromFlag],
+					defaultFlags[toFlag],
+					defaultFlags[resyncFlag],
+					defaultFlags[exportProfileFlag],
+					defaultFlags[priceFileFlag],
+					defaultFlags[fiatCurrencyFlag],
 				},
 				Action: financialExport,
-				Before: initForFinancialReport,
+				Before: defaultBeforeFunc,
+			},
+			{
+				Name:    "portfolio",
+				Aliases: []string{"pf"},
+				Usage:   "Show the holdings of all accounts derived from a mnemonic.",
+				Description: "This command retrieves, for each of the first numAccounts accounts derived from a mnemonic, " +
+					"the token balances, the estimated values of the pDEX liquidity shares and the pending committee rewards. " +
+					"It then prints the holdings per account, and aggregated over all accounts. Accounts are processed " +
+					"concurrently; an account that fails is reported with its errors instead of aborting the whole command.",
+				Flags: []cli.Flag{
+					defaultFlags[mnemonicFlag],
+					defaultFlags[bip39PassphraseFlag],
+					defaultFlags[numAccountsFlag],
+					defaultFlags[numThreadsFlag],
+				},
+				Action: getPortfolio,
+				Before: defaultBeforeFunc,
 			},
 			{
 				Name:        "generate",
@@ -135,25 +460,95 @@ var accountCommands = []*cli.Command{
 				Usage:       "Generate a new Incognito account.",
 				Description: "This command helps generate a new mnemonic phrase and its Incognito accounts.",
 				Flags: []cli.Flag{
+					defaultFlags[numWordsFlag],
+					defaultFlags[bip39PassphraseFlag],
 					defaultFlags[numShardsFlag],
 					defaultFlags[shardIDFlag],
 					defaultFlags[numAccountsFlag],
 				},
 				Action: genKeySet,
 			},
+			{
+				Name:    "vanity",
+				Aliases: []string{"van"},
+				Usage:   "Generate a new mnemonic with accounts in a shard and/or with a payment-address prefix.",
+				Description: "This command generates a new mnemonic phrase and searches its derivation indexes, with numThreads " +
+					"workers, for numAccounts accounts in the given shard and/or whose payment address starts with the given " +
+					"prefix. The progress and rate are logged periodically. Each extra character of the prefix makes the " +
+					"search about 58 times longer. If an output file is given, the mnemonic and the keys are written to it " +
+					"encrypted with a passphrase (see decryptfile), and only the payment addresses are printed.",
+				Flags: []cli.Flag{
+					defaultFlags[numWordsFlag],
+					defaultFlags[bip39PassphraseFlag],
+					defaultFlags[numShardsFlag],
+					&cli.IntFlag{
+						Name:  shardIDFlag,
+						Usage: "A specific shardID (-1: any shard)",
+						Value: -1,
+					},
+					defaultFlags[prefixFlag],
+					defaultFlags[numAccountsFlag],
+					defaultFlags[numThreadsFlag],
+					defaultFlags[outFileFlag],
+				},
+				Action: genVanityKeySet,
+			},
+			{
+				Name:  "decryptfile",
+				Usage: "Decrypt a file written by the vanity command.",
+				Flags: []cli.Flag{
+					defaultFlags[inFileFlag],
+				},
+				Action: decryptOutFile,
+			},
 			{
 				Name:        "importaccount",
 				Aliases:     []string{"import"},
-				Usage:       "Import a mnemonic of 12 words.",
+				Usage:       "Import a BIP39 mnemonic.",
 				Description: "This command helps generate Incognito accounts given a mnemonic.",
 				Flags: []cli.Flag{
 					defaultFlags[mnemonicFlag],
+					defaultFlags[bip39PassphraseFlag],
 					defaultFlags[numShardsFlag],
 					defaultFlags[shardIDFlag],
 					defaultFlags[numAccountsFlag],
 				},
 				Action: importMnemonic,
 			},
+			{
+				Name:    "verifymnemonic",
+				Aliases: []string{"verify"},
+				Usage:   "Verify a mnemonic backup offline.",
+				Description: "This command checks the number of words, each word against the BIP39 English wordlist and the " +
+					"checksum of a mnemonic, and tells which word is wrong if any. It then prints the payment addresses of the " +
+					"first numAccounts accounts so that they can be compared with the expected ones. No network connection is made.",
+				Flags: []cli.Flag{
+					defaultFlags[mnemonicFlag],
+					defaultFlags[bip39PassphraseFlag],
+					defaultFlags[numAccountsFlag],
+				},
+				Action: verifyMnemonic,
+			},
+			{
+				Name:    "discover",
+				Aliases: []string{"disc"},
+				Usage:   "Discover the funded accounts of a mnemonic.",
+				Description: "This command walks the derivation indexes of a mnemonic, starting at 1, and reports every index " +
+					"that has ever received coins, whatever its shard. The OTA key of each index is submitted to the full-node " +
+					"(in an authorized manner if an access token is provided) and the command waits for it to be indexed, at most indexingTimeout. " +
+					"The scan stops after gapLimit consecutive unused indexes, so accounts created at non-contiguous indexes " +
+					"(e.g, by the mobile app) are found as long as the gaps between them are shorter than gapLimit.",
+				Flags: []cli.Flag{
+					defaultFlags[mnemonicFlag],
+					defaultFlags[bip39PassphraseFlag],
+					defaultFlags[numShardsFlag],
+					defaultFlags[gapLimitFlag],
+					defaultFlags[accessTokenFlag],
+					defaultFlags[indexingTimeoutFlag],
+				},
+				Action: discoverAccounts,
+				Before: defaultBeforeFunc,
+			},
 			{
 				Name:    "submitkey",
 				Aliases: []string{"sub"},
@@ -171,6 +566,129 @@ var accountCommands = []*cli.Command{
 				Action: submitKey,
 				Before: defaultBeforeFunc,
 			},
+			{
+				Name:  "exportkeyimages",
+				Usage: "Export the key images of the output coins v2 of an account.",
+				Description: "This command computes the key images of all output coins v2 of an account and writes them to a file. " +
+					"Import the file on a host using a watch-only account of the same account (importkeyimages) to let it " +
+					"detect the spent coins. Key images reveal which coins have been spent, but give no spending authority.",
+				Flags: []cli.Flag{
+					defaultFlags[privateKeyFlag],
+					defaultFlags[keyImageFileFlag],
+				},
+				Action: exportKeyImages,
+				Before: defaultBeforeFunc,
+			},
+			{
+				Name:  "importkeyimages",
+				Usage: "Import the key images exported by exportkeyimages.",
+				Description: "This command adds the key images exported from a spending account to the local key image file, " +
+					"which is used by watch-only accounts and `tx prepare` to skip the spent coins.",
+				Flags: []cli.Flag{
+					defaultFlags[keyImageFileFlag],
+				},
+				Action: importKeyImages,
+			},
+			keyStoreCommands,
+			backupCommands,
+		},
+	},
+}
+
+// backupCommands consists of all commands backing up a mnemonic with shares (sub-commands of accountCommands).
+var backupCommands = &cli.Command{
+	Name:  "backup",
+	Usage: "Back up a mnemonic with Shamir's secret sharing.",
+	Description: "This command helps split a mnemonic into n shares, any k of which can reconstruct it, so that the mnemonic " +
+		"is not a single point of failure. Each share has a checksum, and a digest of the mnemonic is shared along with it, " +
+		"so a wrong share is detected instead of silently producing a different wallet. Everything runs offline. The BIP39 " +
+		"passphrase (if any) is not part of the shares and must be backed up separately.",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "split",
+			Usage: "Split a mnemonic into shares.",
+			Flags: []cli.Flag{
+				defaultFlags[mnemonicFlag],
+				defaultFlags[thresholdFlag],
+				defaultFlags[numSharesFlag],
+			},
+			Action: backupSplit,
+		},
+		{
+			Name:  "combine",
+			Usage: "Reconstruct a mnemonic from its shares.",
+			Description: "This command reconstructs a mnemonic from at least threshold of its shares, and prints it together " +
+				"with the payment address of its first account (derived with the BIP39 passphrase, if given) so that it can " +
+				"be compared with the expected wallet.",
+			Flags: []cli.Flag{
+				defaultFlags[shareFlag],
+				defaultFlags[bip39PassphraseFlag],
+			},
+			Action: backupCombine,
+		},
+	},
+}
+
+// keyStoreCommands consists of all commands managing the local keystore (sub-commands of accountCommands).
+var keyStoreCommands = &cli.Command{
+	Name:    "keystore",
+	Aliases: []string{"ks"},
+	Usage:   "Manage the encrypted local keystore.",
+	Description: "This command helps manage the local keystore where private keys are stored encrypted with a passphrase " +
+		"(scrypt + AES-GCM). A keystore account can be used in any command requiring a private key via the global " +
+		"`account` flag instead of passing the private key on the command line. Watch-only accounts (see addwatch) can be " +
+		"used the same way by commands that do not spend funds.",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "add",
+			Usage: "Encrypt a private key and add it to the keystore.",
+			Description: "This command encrypts a private key with a passphrase and stores it in the keystore under the given name. " +
+				"If the private key is not provided, it will be asked for interactively so that it does not appear in the shell history.",
+			Flags: []cli.Flag{
+				defaultFlags[accountNameFlag],
+				&cli.StringFlag{
+					Name:    privateKeyFlag,
+					Aliases: aliases[privateKeyFlag],
+					Usage:   "A base58-encoded Incognito private key (if not provided, it will be prompted)",
+				},
+			},
+			Action: keyStoreAdd,
+		},
+		{
+			Name:  "addwatch",
+			Usage: "Add a watch-only account to the keystore.",
+			Description: "This command adds a watch-only account made of a payment address, its OTA key and its read-only key. " +
+				"A watch-only account can check balances, UTXOs and the in-coming history via the global account flag, but " +
+				"any action requiring the private key is refused. The keys are stored unencrypted since they cannot spend funds.",
+			Flags: []cli.Flag{
+				defaultFlags[accountNameFlag],
+				defaultFlags[addressFlag],
+				defaultFlags[otaKeyFlag],
+				defaultFlags[readonlyKeyFlag],
+			},
+			Action: keyStoreAddWatch,
+		},
+		{
+			Name:   "list",
+			Usage:  "List all accounts in the keystore.",
+			Action: keyStoreList,
+		},
+		{
+			Name:  "remove",
+			Usage: "Remove an account from the keystore.",
+			Flags: []cli.Flag{
+				defaultFlags[accountNameFlag],
+			},
+			Action: keyStoreRemove,
+		},
+		{
+			Name:  "rename",
+			Usage: "Rename an account in the keystore.",
+			Flags: []cli.Flag{
+				defaultFlags[accountNameFlag],
+				defaultFlags[newAccountNameFlag],
+			},
+			Action: keyStoreRename,
 		},
 	},
 }
@@ -187,6 +705,7 @@ var committeeCommands = []*cli.Command{
 			defaultFlags[candidateAddressFlag],
 			defaultFlags[rewardReceiverFlag],
 			defaultFlags[autoReStakeFlag],
+			defaultFlags[feeFlag],
 		},
 		Action: stake,
 		Before: defaultBeforeFunc,
@@ -199,6 +718,7 @@ var committeeCommands = []*cli.Command{
 			defaultFlags[privateKeyFlag],
 			defaultFlags[miningKeyFlag],
 			defaultFlags[candidateAddressFlag],
+			defaultFlags[feeFlag],
 		},
 		Action: unStake,
 		Before: defaultBeforeFunc,
@@ -226,6 +746,7 @@ var committeeCommands = []*cli.Command{
 			},
 			defaultFlags[tokenIDFlag],
 			defaultFlags[versionFlag],
+			defaultFlags[feeFlag],
 		},
 		Action: withdrawReward,
 		Before: defaultBeforeFunc,
@@ -237,46 +758,110 @@ var txCommands = []*cli.Command{
 	{
 		Name:  "send",
 		Usage: "Send an amount of PRV or token from one wallet to another wallet.",
-		Description: "This command sends an amount of PRV or token from one wallet to another wallet. By default, " +
-			"it used 100 nano PRVs to pay the transaction fee.",
+		Description: fmt.Sprintf("This command sends an amount of PRV or token from one wallet to another wallet. By default, "+
+			"it uses %v nano PRVs to pay the transaction fee; use the %v flag (or the fee of a profile) to pay a different fee. "+
+			"The coins to spend are chosen by the SDK, unless a %v strategy or an explicit list of %v is given.",
+			incclient.DefaultPRVFee, feeFlag, coinSelectionFlag, inputsFlag),
 		Category: transactionCat,
 		Flags: []cli.Flag{
 			defaultFlags[privateKeyFlag],
-			defaultFlags[addressFlag],
-			defaultFlags[amountFlag],
+			&cli.StringFlag{
+				Name:    addressFlag,
+				Aliases: aliases[addressFlag],
+				Usage:   "The base58-encoded payment address of the receiver",
+			},
+			&cli.StringFlag{
+				Name:    amountFlag,
+				Aliases: aliases[amountFlag],
+				Usage:   "The amount to send, " + tokenAmountFormats,
+			},
 			defaultFlags[tokenIDFlag],
 			defaultFlags[versionFlag],
+			defaultFlags[feeFlag],
+			defaultFlags[coinSelectionFlag],
+			defaultFlags[inputsFlag],
 		},
 		Action: send,
-		Before: defaultBeforeFunc,
+		Subcommands: []*cli.Command{
+			{
+				Name:  "sweep",
+				Usage: "Send the entire balance of PRV or a token to another wallet.",
+				Description: fmt.Sprintf("This command sends all the UTXOs of a version of PRV or a token to another "+
+					"wallet, minus the fees. The UTXOs are spent with transactions of at most %v inputs, each paying the "+
+					"fee; for a token, the fees are paid with PRV. For PRV, the smallest UTXOs not covering the fee of "+
+					"their transaction are left.", incclient.MaxInputSize),
+				Flags: []cli.Flag{
+					defaultFlags[privateKeyFlag],
+					&cli.StringFlag{
+						Name:    addressFlag,
+						Aliases: aliases[addressFlag],
+						Usage:   "The base58-encoded payment address of the receiver",
+					},
+					defaultFlags[tokenIDFlag],
+					defaultFlags[versionFlag],
+					defaultFlags[feeFlag],
+				},
+				Action: sendSweep,
+				Before: defaultBeforeFunc,
+			},
+			{
+				Name:  "batch",
+				Usage: "Send PRV or tokens to multiple receivers listed in a CSV file.",
+				Description: fmt.Sprintf("This command sends the payments listed in a CSV file of address,amount,tokenID rows. "+
+					"Payments of the same token are grouped into transactions of at most %v receivers, and transactions "+
+					"are sent one after another. The result of each payment is written to a result CSV file; the hash of a "+
+					"transaction is saved before it is broadcast. If the run is interrupted, re-running the command with the "+
+					"same files first waits for the transactions already sent, then only sends the remaining payments. The "+
+					"payments of a failed or dropped transaction keep its hash, and are only sent again with %v.",
+					maxBatchReceivers, resendFlag),
+				Flags: []cli.Flag{
+					defaultFlags[privateKeyFlag],
+					defaultFlags[batchFileFlag],
+					defaultFlags[resultFileFlag],
+					defaultFlags[resendFlag],
+					defaultFlags[versionFlag],
+					defaultFlags[feeFlag],
+				},
+				Action: sendBatch,
+				Before: defaultBeforeFunc,
+			},
+		},
 	},
 	{
 		Name:  "convert",
 		Usage: "Convert UTXOs of an account w.r.t a tokenID.",
 		Description: "This command helps convert UTXOs v1 of a user to UTXO v2 w.r.t a tokenID. " +
+			"Each transaction converts a chunk of UTXOs and pays the fee in PRV (with a PRV UTXO v2 for a token), numThreads transactions are created at a time. " +
 			"Please note that this process is time-consuming and requires a considerable amount of CPU.",
 		Category: transactionCat,
 		Flags: []cli.Flag{
 			defaultFlags[privateKeyFlag],
 			defaultFlags[tokenIDFlag],
 			defaultFlags[numThreadsFlag],
+			defaultFlags[feeFlag],
 		},
 		Action: convertUTXOs,
 		Before: defaultBeforeFunc,
 	},
-	//{
-	//	Name:  "convertall",
-	//	Usage: "Convert UTXOs of an account for all assets.",
-	//	Description: "This command helps convert UTXOs v1 of a user to UTXO v2 for all assets. " +
-	//		"It will automatically check for all UTXOs v1 of all tokens and convert them. " +
-	//		"Please note that this process is time-consuming and requires a considerable amount of CPU.",
-	//	Category: transactionCat,
-	//	Flags: []cli.Flag{
-	//		defaultFlags[privateKeyFlag],
-	//		defaultFlags[numThreadsFlag],
-	//	},
-	//	Action: convertAll,
-	//},
+	{
+		Name:  "convertall",
+		Usage: "Convert UTXOs of an account for all assets.",
+		Description: "This command helps convert UTXOs v1 of a user to UTXO v2 for all assets. " +
+			"It will automatically check for the UTXOs v1 of PRV and of the tokens found in the transactions v1 of the account, and convert them, starting with PRV so that " +
+			"the fees of the token conversions can be paid, and waiting for the transactions of a token to be " +
+			"confirmed before moving to the next one. The progress is kept in a state file: if the command is " +
+			"interrupted, running it again with the same state file resumes the conversion. Each transaction is recorded " +
+			"in the state file before it is broadcast. " +
+			"Please note that this process is time-consuming and requires a considerable amount of CPU.",
+		Category: transactionCat,
+		Flags: []cli.Flag{
+			defaultFlags[privateKeyFlag],
+			defaultFlags[numThreadsFlag],
+			defaultFlags[stateFileFlag],
+		},
+		Action: convertAll,
+		Before: defaultBeforeFunc,
+	},
 	{
 		Name:  "checkreceiver",
 		Usage: "Check if an OTA key is a receiver of a transaction.",
@@ -291,6 +876,94 @@ var txCommands = []*cli.Command{
 		Action: checkReceiver,
 		Before: defaultBeforeFunc,
 	},
+	{
+		Name:     "tx",
+		Usage:    "Manage Incognito transactions.",
+		Category: transactionCat,
+		Subcommands: []*cli.Command{
+			{
+				Name:  "status",
+				Usage: "Check the status of an Incognito transaction.",
+				Description: fmt.Sprintf("This command reports whether a transaction is unknown (%v), pending in the mempool (%v), "+
+					"included in a block (%v) or confirmed by %v shard blocks (%v), together with its shard, block height and fee. "+
+					"With the global %v flag, it polls the full-node until the transaction is confirmed or dropped (%v, after "+
+					"%v consecutive %v answers over at least %v).",
+					txNotFound, txInMempool, txInBlock, txConfirmations, txConfirmed, waitFlag, txDropped,
+					txDroppedMinNotFound, txNotFound, txNotFoundGracePeriod),
+				Flags: []cli.Flag{
+					defaultFlags[txHashFlag],
+				},
+				Action: checkTxStatus,
+				Before: defaultBeforeFunc,
+			},
+			{
+				Name:  "estimatefee",
+				Usage: "Estimate the fee of a transfer transaction.",
+				Description: "This command builds a transfer transaction without broadcasting it, and reports its size (in KB), " +
+					"the current fee per KB of the sender's shard, and the minimum fee the network will accept for it.",
+				Flags: []cli.Flag{
+					defaultFlags[privateKeyFlag],
+					defaultFlags[addressFlag],
+					defaultFlags[amountFlag],
+					defaultFlags[tokenIDFlag],
+					defaultFlags[versionFlag],
+					defaultFlags[feeFlag],
+				},
+				Action: estimateFee,
+				Before: defaultBeforeFunc,
+			},
+			{
+				Name:  "prepare",
+				Usage: "Prepare an unsigned transfer transaction to be signed offline.",
+				Description: fmt.Sprintf("This command is the first step of the offline signing flow (prepare -> sign -> broadcast). "+
+					"Run on an online machine, it fetches the UTXOs v2 of the sender with its OTA key and read-only key "+
+					"(no private key needed, the OTA key must have been submitted to the full-node), chooses the ones to "+
+					"spend, retrieves the decoys for their rings, and writes everything to the %v file. Coins spent by "+
+					"transactions previously broadcast from this machine are skipped; coins spent elsewhere cannot be "+
+					"detected without the private key.", bundleFileFlag),
+				Flags: []cli.Flag{
+					defaultFlags[otaKeyFlag],
+					defaultFlags[readonlyKeyFlag],
+					defaultFlags[addressFlag],
+					defaultFlags[amountFlag],
+					defaultFlags[tokenIDFlag],
+					defaultFlags[feeFlag],
+					defaultFlags[bundleFileFlag],
+				},
+				Action: prepareTx,
+				Before: defaultBeforeFunc,
+			},
+			{
+				Name:  "sign",
+				Usage: "Sign an unsigned transaction bundle offline.",
+				Description: fmt.Sprintf("This command is the second step of the offline signing flow. It signs the transaction "+
+					"of a bundle created by `tx prepare` with the private key of the sender and writes it to the %v file. "+
+					"It never connects to the network and can be run on an air-gapped machine.", signedTxFileFlag),
+				Flags: []cli.Flag{
+					defaultFlags[privateKeyFlag],
+					defaultFlags[bundleFileFlag],
+					defaultFlags[signedTxFileFlag],
+				},
+				Action: signTx,
+			},
+			{
+				Name:  "broadcast",
+				Usage: "Broadcast a transaction signed offline.",
+				Description: "This command is the last step of the offline signing flow. It submits a transaction signed by " +
+					"`tx sign` to the network, and remembers the key images of its input coins so that `tx prepare` " +
+					"does not spend them again.",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     signedTxFileFlag,
+						Usage:    "The JSON file of a transaction signed by the sign command",
+						Required: true,
+					},
+				},
+				Action: broadcastTx,
+				Before: defaultBeforeFunc,
+			},
+		},
+	},
 }
 
 // bridgeCommands consists of all bridge-related commands
@@ -346,6 +1019,7 @@ var evmBridgeCommands = &cli.Command{
 					Aliases: aliases[addressFlag],
 					Usage:   "The Incognito payment address to receive the shielding asset (default: the payment address of the privateKey)",
 				},
+				defaultFlags[evmPrivateKeyFileFlag],
 			},
 			Action: shield,
 			Before: defaultBeforeFunc,
@@ -376,6 +1050,8 @@ var evmBridgeCommands = &cli.Command{
 					Required: true,
 				},
 				defaultFlags[amountFlag],
+				defaultFlags[evmAddressFlag],
+				defaultFlags[evmPrivateKeyFileFlag],
 			},
 			Action: unShield,
 			Before: defaultBeforeFunc,
@@ -387,6 +1063,7 @@ var evmBridgeCommands = &cli.Command{
 			Flags: []cli.Flag{
 				defaultFlags[txHashFlag],
 				defaultFlags[evmFlag],
+				defaultFlags[evmPrivateKeyFileFlag],
 			},
 			Action: retryUnShield,
 			Before: defaultBeforeFunc,
@@ -408,6 +1085,7 @@ var evmBridgeCommands = &cli.Command{
 					Aliases: aliases[addressFlag],
 					Usage:   "The Incognito payment address to receive the shielding asset (default: the payment address of the privateKey)",
 				},
+				defaultFlags[evmPrivateKeyFileFlag],
 			},
 			Action: shieldPRV,
 			Before: prvInitFunc,
@@ -440,6 +1118,8 @@ var evmBridgeCommands = &cli.Command{
 					Usage: "The EVM network (ETH or BSC)",
 					Value: "ETH",
 				},
+				defaultFlags[evmAddressFlag],
+				defaultFlags[evmPrivateKeyFileFlag],
 			},
 			Action: unShieldPRV,
 			Before: prvInitFunc,
@@ -455,6 +1135,7 @@ var evmBridgeCommands = &cli.Command{
 					Usage: "The EVM network (ETH or BSC)",
 					Value: "ETH",
 				},
+				defaultFlags[evmPrivateKeyFileFlag],
 			},
 			Action: retryUnShieldPRV,
 			Before: prvInitFunc,
@@ -590,6 +1271,7 @@ var pDEXActionCommands = &cli.Command{
 				defaultFlags[tradingPathFlag],
 				defaultFlags[prvFeeFlag],
 				defaultFlags[maxTradingPathLengthFlag],
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXTrade,
 			Before: defaultBeforeFunc,
@@ -600,6 +1282,7 @@ var pDEXActionCommands = &cli.Command{
 			Description: "This command creates and broadcasts a transaction that mints a new (pDEX) NFT for the pDEX.",
 			Flags: []cli.Flag{
 				defaultFlags[privateKeyFlag],
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXMintNFT,
 			Before: defaultBeforeFunc,
@@ -620,6 +1303,7 @@ var pDEXActionCommands = &cli.Command{
 					Usage: "The ID of the contributing pool pair. For pool-initializing transactions (e.g, first contribution in the pool), it should be left empty.",
 					Value: "",
 				},
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXContribute,
 			Before: defaultBeforeFunc,
@@ -641,6 +1325,7 @@ var pDEXActionCommands = &cli.Command{
 					Aliases: aliases[amountFlag],
 					Usage:   "The amount of share wished to withdraw. If set to 0, it will withdraw all of the share.",
 				},
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXWithdraw,
 			Before: defaultBeforeFunc,
@@ -655,12 +1340,13 @@ var pDEXActionCommands = &cli.Command{
 				defaultFlags[nftIDFlag],
 				defaultFlags[tokenIDToSellFlag],
 				defaultFlags[sellingAmountFlag],
-				&cli.Uint64Flag{
+				&cli.StringFlag{
 					Name:     minAcceptableAmountFlag,
 					Aliases:  aliases[minAcceptableAmountFlag],
-					Usage:    fmt.Sprintf("The minimum acceptable amount of %v wished to receive", tokenIDToBuyFlag),
+					Usage:    fmt.Sprintf("The minimum acceptable amount of %v wished to receive, %v", tokenIDToBuyFlag, tokenAmountFormats),
 					Required: true,
 				},
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXAddOrder,
 			Before: defaultBeforeFunc,
@@ -687,6 +1373,7 @@ var pDEXActionCommands = &cli.Command{
 					Usage:   "Amount to withdraw (0 for all)",
 					Value:   0,
 				},
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXWithdrawOrder,
 			Before: defaultBeforeFunc,
@@ -704,6 +1391,7 @@ var pDEXActionCommands = &cli.Command{
 					Usage: "The ID of the target staking pool ID (or token ID)",
 					Value: common.PRVIDStr,
 				},
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXStake,
 			Before: defaultBeforeFunc,
@@ -721,6 +1409,7 @@ var pDEXActionCommands = &cli.Command{
 					Usage: "The ID of the target staking pool ID (or token ID)",
 					Value: common.PRVIDStr,
 				},
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXUnStake,
 			Before: defaultBeforeFunc,
@@ -737,6 +1426,7 @@ var pDEXActionCommands = &cli.Command{
 					Usage: "The ID of the target staking pool ID (or token ID)",
 					Value: common.PRVIDStr,
 				},
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXWithdrawStakingReward,
 			Before: defaultBeforeFunc,
@@ -749,6 +1439,7 @@ var pDEXActionCommands = &cli.Command{
 				defaultFlags[privateKeyFlag],
 				defaultFlags[pairIDFlag],
 				defaultFlags[nftIDFlag],
+				defaultFlags[feeFlag],
 			},
 			Action: pDEXWithdrawLPFee,
 			Before: defaultBeforeFunc,
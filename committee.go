@@ -4,13 +4,14 @@ import (
 	"fmt"
 	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
 	"github.com/urfave/cli/v2"
+	"log"
 )
 
 // stake creates a staking transaction.
 func stake(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 	canAddr := c.String(candidateAddressFlag)
 	if canAddr == "" {
@@ -36,19 +37,28 @@ func stake(c *cli.Context) error {
 	reStake := c.Int(autoReStakeFlag)
 	autoReStake := reStake != 0
 
-	txHash, err := cfg.incClient.CreateAndSendShardStakingTransaction(privateKey, miningKey, canAddr, rewardAddr, autoReStake)
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createShardStakingTx(privateKey, miningKey, canAddr, rewardAddr, autoReStake, fee)
+	if err != nil {
+		return newAppError(CreateStakingTransactionError, err)
+	}
+	err = sendRawTx(encodedTx, false)
 	if err != nil {
 		return newAppError(CreateStakingTransactionError, err)
 	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // unStake creates an un-staking transaction.
 func unStake(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	canAddr := c.String(candidateAddressFlag)
@@ -67,12 +77,21 @@ func unStake(c *cli.Context) error {
 		return newAppError(InvalidMiningKeyError)
 	}
 
-	txHash, err := cfg.incClient.CreateAndSendUnStakingTransaction(privateKey, miningKey, canAddr)
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createUnStakingTx(privateKey, miningKey, canAddr, fee)
+	if err != nil {
+		return newAppError(CreateUnStakingTransactionError, err)
+	}
+	err = sendRawTx(encodedTx, false)
 	if err != nil {
 		return newAppError(CreateUnStakingTransactionError, err)
 	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // checkRewards gets all rewards of a payment address.
@@ -88,19 +107,17 @@ func checkRewards(c *cli.Context) error {
 	}
 
 	if len(rewards) == 0 {
-		fmt.Printf("There is not rewards found for the address %v\n", addr)
-	} else {
-		return jsonPrint(rewards)
+		log.Printf("There is not rewards found for the address %v\n", addr)
 	}
 
-	return nil
+	return printResult(rewards)
 }
 
 // withdrawReward withdraws the reward of a privateKey w.r.t to a tokenID.
 func withdrawReward(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	addr := c.String(addressFlag)
@@ -111,9 +128,9 @@ func withdrawReward(c *cli.Context) error {
 		return newAppError(InvalidPaymentAddressError)
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	version := c.Int(versionFlag)
@@ -121,12 +138,21 @@ func withdrawReward(c *cli.Context) error {
 		return newAppError(VersionError)
 	}
 
-	fmt.Printf("Withdrawing the reward for tokenID %v, using tx version %v\n", tokenIDStr, version)
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Withdrawing the reward for tokenID %v, using tx version %v\n", tokenIDStr, version)
 
-	txHash, err := cfg.incClient.CreateAndSendWithDrawRewardTransaction(privateKey, addr, tokenIDStr, int8(version))
+	encodedTx, txHash, err := createWithdrawRewardTx(privateKey, addr, tokenIDStr, int8(version), fee)
+	if err != nil {
+		return newAppError(CreateWithdrawRewardTransactionError, err)
+	}
+	err = sendRawTx(encodedTx, false)
 	if err != nil {
 		return newAppError(CreateWithdrawRewardTransactionError, err)
 	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/fatih/camelcase"
@@ -20,6 +19,13 @@ const (
 	clientVersionFlag = "clientVersion"
 	debugFlag         = "debug"
 	cacheFlag         = "utxoCache"
+	accountFlag       = "account"
+	passphraseFlag    = "passphraseFile"
+	profileFlag       = "profile"
+	outputFlag        = "output"
+	yesFlag           = "yes"
+	waitFlag          = "wait"
+	waitTimeoutFlag   = "waitTimeout"
 	privateKeyFlag    = "privateKey"
 	addressFlag       = "address"
 	otaKeyFlag        = "otaKey"
@@ -32,10 +38,41 @@ const (
 	logFileFlag       = "logFile"
 	enableLogFlag     = "enableLog"
 	csvFileFlag       = "csvFile"
+	batchFileFlag     = "file"
+	resultFileFlag    = "resultFile"
+	resendFlag        = "resend"
 	accessTokenFlag   = "accessToken"
 	fromHeightFlag    = "fromHeight"
 	isResetFlag       = "isReset"
 	txHashFlag        = "txHash"
+	bundleFileFlag    = "bundle"
+	signedTxFileFlag  = "signedTx"
+	minUTXOsFlag      = "minUTXOs"
+	dryRunFlag        = "dryRun"
+	reportFileFlag    = "reportFile"
+	stateFileFlag     = "stateFile"
+	minValueFlag      = "minValue"
+	maxValueFlag      = "maxValue"
+	sortByFlag        = "sortBy"
+	histogramFlag     = "histogram"
+	dustThresholdFlag = "dustThreshold"
+	checkSpentFlag    = "checkSpent"
+	exportFileFlag    = "exportFile"
+	coinSelectionFlag = "coinSelection"
+	inputsFlag        = "inputs"
+	listenFlag        = "listen"
+	accountsFlag      = "accounts"
+	syncIntervalFlag  = "syncInterval"
+	fromFlag          = "from"
+	toFlag            = "to"
+	resyncFlag        = "resync"
+	exportProfileFlag = "exportProfile"
+	priceFileFlag     = "priceFile"
+	fiatCurrencyFlag  = "fiat"
+	queryFlag         = "query"
+	verifiedOnlyFlag  = "verified"
+	tokenListURLFlag  = "url"
+	tokenListFileFlag = "listFile"
 
 	tokenIDToSellFlag        = "sellTokenID"
 	tokenIDToBuyFlag         = "buyTokenID"
@@ -53,17 +90,28 @@ const (
 	pairHashFlag             = "pairHash"
 	amplifierFlag            = "amplifier"
 
-	mnemonicFlag    = "mnemonic"
-	numShardsFlag   = "numShards"
-	numAccountsFlag = "numAccounts"
-	shardIDFlag     = "shardID"
-
-	evmAddressFlag      = "evmAddress"
-	tokenAddressFlag    = "externalTokenAddress"
-	shieldAmountFlag    = "shieldAmount"
-	evmFlag             = "evm"
-	externalTxIDFlag    = "externalTxHash"
-	externalAddressFlag = "externalAddress"
+	mnemonicFlag        = "mnemonic"
+	bip39PassphraseFlag = "bip39Passphrase"
+	numWordsFlag        = "numWords"
+	thresholdFlag       = "threshold"
+	numSharesFlag       = "shares"
+	shareFlag           = "share"
+	numShardsFlag       = "numShards"
+	numAccountsFlag     = "numAccounts"
+	shardIDFlag         = "shardID"
+	gapLimitFlag        = "gapLimit"
+	indexingTimeoutFlag = "indexingTimeout"
+	prefixFlag          = "prefix"
+	outFileFlag         = "outFile"
+	inFileFlag          = "inFile"
+
+	evmAddressFlag        = "evmAddress"
+	evmPrivateKeyFileFlag = "evmPrivateKeyFile"
+	tokenAddressFlag      = "externalTokenAddress"
+	shieldAmountFlag      = "shieldAmount"
+	evmFlag               = "evm"
+	externalTxIDFlag      = "externalTxHash"
+	externalAddressFlag   = "externalAddress"
 
 	miningKeyFlag        = "miningKey"
 	candidateAddressFlag = "candidateAddress"
@@ -72,11 +120,21 @@ const (
 
 	adminPrivateKeyFlag = "adminPrivateKey"
 	tokenNameFlag       = "tokenName"
+
+	accountNameFlag    = "name"
+	newAccountNameFlag = "newName"
+	keyImageFileFlag   = "keyImageFile"
+
+	profileNameFlag = "profileName"
+	configKeyFlag   = "key"
+	configValueFlag = "value"
+	revealFlag      = "reveal"
 )
 
 // aliases for defaultFlags
 var aliases = map[string][]string{
 	networkFlag:          {"net"},
+	accountFlag:          {"acc"},
 	debugFlag:            {"d"},
 	privateKeyFlag:       {"p", "prvKey"},
 	otaKeyFlag:           {"ota"},
@@ -110,6 +168,8 @@ var aliases = map[string][]string{
 
 // category constants
 const (
+	configCat      = "CONFIG"
+	networkCat     = "NETWORK"
 	accountCat     = "ACCOUNTS"
 	committeeCat   = "COMMITTEES"
 	transactionCat = "TRANSACTIONS"
@@ -288,19 +348,6 @@ func isSupportedVersion(version int8) bool {
 	return version == 1 || version == 2
 }
 
-func jsonPrintWithKey(key string, val interface{}) error {
-	return jsonPrint(map[string]interface{}{key: val})
-}
-
-func jsonPrint(val interface{}) error {
-	jsb, err := json.MarshalIndent(val, "", "\t")
-	if err != nil {
-		return err
-	}
-	fmt.Println(string(jsb))
-	return nil
-}
-
 // flagToVariable gets the variable representation for a flag.
 // The variable representation of a flag is a ALL_UPPER_CASE form of a flag.
 //
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"sync"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
@@ -8,8 +11,11 @@ import (
 	"github.com/incognitochain/incognito-cli/bridge/portal"
 )
 
-// Config represents the config of an environment of the CLI tool.
+// Config represents the config of an environment of the CLI tool. Clients are lazily initialized on first use
+// so that an unreachable endpoint only affects the commands which actually need it.
 type Config struct {
+	network string
+
 	incClient *incclient.IncClient
 
 	evmClients map[int]*ethclient.Client
@@ -17,249 +23,253 @@ type Config struct {
 	btcClient *portal.BTCClient
 
 	evmVaultAddresses map[int]common.Address
+
+	evmEndpoints map[int]evmEndpoint
+
+	newIncClient func() (*incclient.IncClient, error)
+
+	incMtx sync.Mutex
+	evmMtx sync.Mutex
+	btcMtx sync.Mutex
+}
+
+// evmEndpoint holds the RPC host and the vault address of an EVM network.
+type evmEndpoint struct {
+	Host  string `yaml:"host,omitempty" json:"host,omitempty"`
+	Vault string `yaml:"vault,omitempty" json:"vault,omitempty"`
 }
 
-// NewConfig returns a new Config from given parameters.
+// defaultEVMEndpoints holds the built-in EVM endpoints of each supported Incognito network.
+var defaultEVMEndpoints = map[string]map[int]evmEndpoint{
+	"mainnet": {
+		rpc.ETHNetworkID: {Host: incclient.MainNetETHHost, Vault: incclient.MainNetETHContractAddressStr},
+		rpc.BSCNetworkID: {Host: incclient.MainNetBSCHost, Vault: incclient.MainNetBSCContractAddressStr},
+		rpc.PLGNetworkID: {Host: incclient.MainNetPLGHost, Vault: incclient.MainNetPLGContractAddressStr},
+		rpc.FTMNetworkID: {Host: incclient.MainNetFTMHost, Vault: incclient.MainNetFTMContractAddressStr},
+	},
+	"testnet": {
+		rpc.ETHNetworkID: {Host: incclient.TestNetETHHost, Vault: incclient.TestNetETHContractAddressStr},
+		rpc.BSCNetworkID: {Host: incclient.TestNetBSCHost, Vault: incclient.TestNetBSCContractAddressStr},
+		rpc.PLGNetworkID: {Host: incclient.TestNetPLGHost, Vault: incclient.TestNetPLGContractAddressStr},
+		rpc.FTMNetworkID: {Host: incclient.TestNetFTMHost, Vault: incclient.TestNetFTMContractAddressStr},
+	},
+	"testnet1": {
+		rpc.ETHNetworkID: {Host: incclient.TestNet1ETHHost, Vault: incclient.TestNet1ETHContractAddressStr},
+		rpc.BSCNetworkID: {Host: incclient.TestNet1BSCHost, Vault: incclient.TestNet1BSCContractAddressStr},
+		rpc.PLGNetworkID: {Host: incclient.TestNet1PLGHost, Vault: incclient.TestNet1PLGContractAddressStr},
+		rpc.FTMNetworkID: {Host: incclient.TestNet1FTMHost, Vault: incclient.TestNet1FTMContractAddressStr},
+	},
+	"local": {
+		rpc.ETHNetworkID: {Host: incclient.LocalETHHost, Vault: incclient.LocalETHContractAddressStr},
+		rpc.BSCNetworkID: {Host: incclient.LocalETHHost, Vault: incclient.LocalETHContractAddressStr},
+		rpc.PLGNetworkID: {Host: incclient.LocalETHHost, Vault: incclient.LocalETHContractAddressStr},
+		rpc.FTMNetworkID: {Host: incclient.LocalETHHost, Vault: incclient.LocalETHContractAddressStr},
+	},
+}
+
+// NewConfig returns a new Config for the given Incognito network. No client is created until it is first used;
+// newIncClient is called to create the Incognito client if incClient is nil.
 func NewConfig(
+	networkName string,
 	incClient *incclient.IncClient,
-	evmClients map[int]*ethclient.Client,
-	btcClient *portal.BTCClient,
-	evmVaultAddresses map[int]common.Address,
+	newIncClient func() (*incclient.IncClient, error),
 ) *Config {
+	evmEndpoints := getEVMEndpoints(networkName)
+	evmVaultAddresses := make(map[int]common.Address)
+	for evmNetworkID, endpoint := range evmEndpoints {
+		evmVaultAddresses[evmNetworkID] = common.HexToAddress(endpoint.Vault)
+	}
+
+	if networkName == "mainnet" {
+		isMainNet = true
+	}
+
 	return &Config{
+		network:           networkName,
 		incClient:         incClient,
-		evmClients:        evmClients,
+		evmClients:        make(map[int]*ethclient.Client),
 		evmVaultAddresses: evmVaultAddresses,
-		btcClient:         btcClient,
+		evmEndpoints:      evmEndpoints,
+		newIncClient:      newIncClient,
 	}
 }
 
 // NewTestNetConfig creates a new testnet Config.
 func NewTestNetConfig(incClient *incclient.IncClient) error {
-	var err error
-	if incClient == nil {
-		if cache == 0 {
-			incClient, err = incclient.NewTestNetClient()
-		} else {
-			incClient, err = incclient.NewTestNetClientWithCache()
-		}
-		if err != nil {
-			return err
-		}
-	}
-
-	ethClient, err := ethclient.Dial(incclient.TestNetETHHost)
-	if err != nil {
-		return err
-	}
-
-	bscClient, err := ethclient.Dial(incclient.TestNetBSCHost)
-	if err != nil {
-		return err
-	}
+	cfg = NewConfig("testnet", incClient, newIncClientFunc("testnet"))
 
-	plgClient, err := ethclient.Dial(incclient.TestNetPLGHost)
-	if err != nil {
-		return err
-	}
+	_, err := cfg.getIncClient()
+	return err
+}
 
-	ftmClient, err := ethclient.Dial(incclient.TestNetFTMHost)
-	if err != nil {
-		return err
-	}
+// NewTestNet1Config creates a new testnet1 Config.
+func NewTestNet1Config(incClient *incclient.IncClient) error {
+	cfg = NewConfig("testnet1", incClient, newIncClientFunc("testnet1"))
 
-	evmClients := map[int]*ethclient.Client{
-		rpc.ETHNetworkID: ethClient,
-		rpc.BSCNetworkID: bscClient,
-		rpc.PLGNetworkID: plgClient,
-		rpc.FTMNetworkID: ftmClient,
-	}
+	_, err := cfg.getIncClient()
+	return err
+}
 
-	evmVaultAddresses := map[int]common.Address{
-		rpc.ETHNetworkID: common.HexToAddress(incclient.TestNetETHContractAddressStr),
-		rpc.BSCNetworkID: common.HexToAddress(incclient.TestNetBSCContractAddressStr),
-		rpc.PLGNetworkID: common.HexToAddress(incclient.TestNetPLGContractAddressStr),
-		rpc.FTMNetworkID: common.HexToAddress(incclient.TestNetFTMContractAddressStr),
-	}
+// NewMainNetConfig creates a new main-net Config.
+func NewMainNetConfig(incClient *incclient.IncClient) error {
+	cfg = NewConfig("mainnet", incClient, newIncClientFunc("mainnet"))
 
-	btcClient, err := portal.NewBTCTestNetClient()
-	if err != nil {
-		return err
-	}
+	_, err := cfg.getIncClient()
+	return err
+}
 
-	cfg = NewConfig(incClient, evmClients, btcClient, evmVaultAddresses)
+// NewLocalConfig creates a new local Config.
+func NewLocalConfig(incClient *incclient.IncClient) error {
+	cfg = NewConfig("local", incClient, newIncClientFunc("local"))
 
-	return nil
+	_, err := cfg.getIncClient()
+	return err
 }
 
-// NewTestNet1Config creates a new testnet1 Config.
-func NewTestNet1Config(incClient *incclient.IncClient) error {
-	var err error
-	if incClient == nil {
-		if cache == 0 {
-			incClient, err = incclient.NewTestNet1Client()
-		} else {
-			incClient, err = incclient.NewTestNet1ClientWithCache()
+// newIncClientFunc returns the function creating the Incognito client of the given network.
+func newIncClientFunc(networkName string) func() (*incclient.IncClient, error) {
+	return func() (*incclient.IncClient, error) {
+		switch networkName {
+		case "mainnet":
+			if cache == 0 {
+				return incclient.NewMainNetClient()
+			}
+			return incclient.NewMainNetClientWithCache()
+		case "testnet":
+			if cache == 0 {
+				return incclient.NewTestNetClient()
+			}
+			return incclient.NewTestNetClientWithCache()
+		case "testnet1":
+			if cache == 0 {
+				return incclient.NewTestNet1Client()
+			}
+			return incclient.NewTestNet1ClientWithCache()
+		case "local":
+			if cache == 0 {
+				return incclient.NewLocalClient("9334")
+			}
+			return incclient.NewLocalClientWithCache()
 		}
-		if err != nil {
-			return err
-		}
-	}
-
-	ethClient, err := ethclient.Dial(incclient.TestNet1ETHHost)
-	if err != nil {
-		return err
-	}
 
-	bscClient, err := ethclient.Dial(incclient.TestNet1BSCHost)
-	if err != nil {
-		return err
-	}
-
-	plgClient, err := ethclient.Dial(incclient.TestNet1PLGHost)
-	if err != nil {
-		return err
+		return nil, fmt.Errorf("network %v not found", networkName)
 	}
+}
 
-	ftmClient, err := ethclient.Dial(incclient.TestNet1FTMHost)
-	if err != nil {
-		return err
-	}
+// getIncClient returns the Incognito client, creating it if needed.
+func (cfg *Config) getIncClient() (*incclient.IncClient, error) {
+	cfg.incMtx.Lock()
+	defer cfg.incMtx.Unlock()
 
-	evmClients := map[int]*ethclient.Client{
-		rpc.ETHNetworkID: ethClient,
-		rpc.BSCNetworkID: bscClient,
-		rpc.PLGNetworkID: plgClient,
-		rpc.FTMNetworkID: ftmClient,
+	if cfg.incClient != nil {
+		return cfg.incClient, nil
 	}
-
-	evmVaultAddresses := map[int]common.Address{
-		rpc.ETHNetworkID: common.HexToAddress(incclient.TestNet1ETHContractAddressStr),
-		rpc.BSCNetworkID: common.HexToAddress(incclient.TestNet1BSCContractAddressStr),
-		rpc.PLGNetworkID: common.HexToAddress(incclient.TestNet1PLGContractAddressStr),
-		rpc.FTMNetworkID: common.HexToAddress(incclient.TestNet1FTMContractAddressStr),
+	if cfg.newIncClient == nil {
+		return nil, fmt.Errorf("the Incognito client has not been configured")
 	}
-
-	btcClient, err := portal.NewBTCTestNetClient()
+	incClient, err := cfg.newIncClient()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot connect to the Incognito full-node: %v", err)
 	}
+	cfg.incClient = incClient
 
-	cfg = NewConfig(incClient, evmClients, btcClient, evmVaultAddresses)
-	return nil
+	return incClient, nil
 }
 
-// NewMainNetConfig creates a new main-net Config.
-func NewMainNetConfig(incClient *incclient.IncClient) error {
-	var err error
-	if incClient == nil {
-		if cache == 0 {
-			incClient, err = incclient.NewMainNetClient()
-		} else {
-			incClient, err = incclient.NewMainNetClientWithCache()
-		}
-		if err != nil {
-			return err
-		}
-	}
-	isMainNet = true
-
-	ethClient, err := ethclient.Dial(incclient.MainNetETHHost)
-	if err != nil {
-		return err
-	}
+// getEVMClient returns the client of the given EVM network, dialing its RPC endpoint if needed.
+func (cfg *Config) getEVMClient(evmNetworkID int) (*ethclient.Client, error) {
+	cfg.evmMtx.Lock()
+	defer cfg.evmMtx.Unlock()
 
-	bscClient, err := ethclient.Dial(incclient.MainNetBSCHost)
-	if err != nil {
-		return err
+	if evmClient, ok := cfg.evmClients[evmNetworkID]; ok && evmClient != nil {
+		return evmClient, nil
 	}
-
-	plgClient, err := ethclient.Dial(incclient.MainNetPLGHost)
-	if err != nil {
-		return err
+	endpoint, ok := cfg.evmEndpoints[evmNetworkID]
+	if !ok || endpoint.Host == "" {
+		return nil, errEVMNetworkNotSupported(evmNetworkID)
 	}
-
-	ftmClient, err := ethclient.Dial(incclient.MainNetFTMHost)
+	evmClient, err := ethclient.Dial(endpoint.Host)
 	if err != nil {
-		return err
-	}
-
-	evmClients := map[int]*ethclient.Client{
-		rpc.ETHNetworkID: ethClient,
-		rpc.BSCNetworkID: bscClient,
-		rpc.PLGNetworkID: plgClient,
-		rpc.FTMNetworkID: ftmClient,
+		return nil, fmt.Errorf("cannot dial %v: %v", endpoint.Host, err)
 	}
+	cfg.evmClients[evmNetworkID] = evmClient
 
-	evmVaultAddresses := map[int]common.Address{
-		rpc.ETHNetworkID: common.HexToAddress(incclient.MainNetETHContractAddressStr),
-		rpc.BSCNetworkID: common.HexToAddress(incclient.MainNetBSCContractAddressStr),
-		rpc.PLGNetworkID: common.HexToAddress(incclient.MainNetPLGContractAddressStr),
-		rpc.FTMNetworkID: common.HexToAddress(incclient.MainNetFTMContractAddressStr),
-	}
+	return evmClient, nil
+}
 
-	btcClient, err := portal.NewBTCMainNetClient()
+// setEVMClient replaces the client of the given EVM network by a client dialing to a new RPC endpoint.
+func (cfg *Config) setEVMClient(evmNetworkID int, host string) error {
+	evmClient, err := ethclient.Dial(host)
 	if err != nil {
 		return err
 	}
 
-	cfg = NewConfig(incClient, evmClients, btcClient, evmVaultAddresses)
+	cfg.evmMtx.Lock()
+	defer cfg.evmMtx.Unlock()
+	endpoint := cfg.evmEndpoints[evmNetworkID]
+	endpoint.Host = host
+	cfg.evmEndpoints[evmNetworkID] = endpoint
+	cfg.evmClients[evmNetworkID] = evmClient
+
 	return nil
 }
 
-// NewLocalConfig creates a new local Config.
-func NewLocalConfig(incClient *incclient.IncClient) error {
-	var err error
-	if incClient == nil {
-		if cache == 0 {
-			incClient, err = incclient.NewLocalClient("9334")
-		} else {
-			incClient, err = incclient.NewLocalClientWithCache()
-		}
-		if err != nil {
-			return err
-		}
-	}
+// getBTCClient returns the BTC client, creating it if needed.
+func (cfg *Config) getBTCClient() (*portal.BTCClient, error) {
+	cfg.btcMtx.Lock()
+	defer cfg.btcMtx.Unlock()
 
-	ethClient, err := ethclient.Dial(incclient.LocalETHHost)
-	if err != nil {
-		return err
+	if cfg.btcClient != nil {
+		return cfg.btcClient, nil
 	}
-
-	bscClient, err := ethclient.Dial(incclient.LocalETHHost)
+	btcClient, err := newBTCClient(cfg.network)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("cannot create the BTC client: %v", err)
 	}
+	cfg.btcClient = btcClient
 
-	plgClient, err := ethclient.Dial(incclient.LocalETHHost)
-	if err != nil {
-		return err
-	}
+	return btcClient, nil
+}
 
-	fmtClient, err := ethclient.Dial(incclient.LocalETHHost)
-	if err != nil {
-		return err
+// getEVMEndpoints returns the EVM endpoints of the given Incognito network, taking into account the overrides
+// of the active profile.
+func getEVMEndpoints(networkName string) map[int]evmEndpoint {
+	res := make(map[int]evmEndpoint)
+	for evmNetworkID, endpoint := range defaultEVMEndpoints[networkName] {
+		res[evmNetworkID] = endpoint
 	}
-
-	evmClients := map[int]*ethclient.Client{
-		rpc.ETHNetworkID: ethClient,
-		rpc.BSCNetworkID: bscClient,
-		rpc.PLGNetworkID: plgClient,
-		rpc.FTMNetworkID: fmtClient,
+	if activeProfile == nil {
+		return res
 	}
 
-	evmVaultAddresses := map[int]common.Address{
-		rpc.ETHNetworkID: common.HexToAddress(incclient.LocalETHContractAddressStr),
-		rpc.BSCNetworkID: common.HexToAddress(incclient.LocalETHContractAddressStr),
-		rpc.PLGNetworkID: common.HexToAddress(incclient.LocalETHContractAddressStr),
-		rpc.FTMNetworkID: common.HexToAddress(incclient.LocalETHContractAddressStr),
+	for evmNetwork, override := range activeProfile.EVM {
+		evmNetworkID, err := getEVMNetworkIDFromName(evmNetwork)
+		if err != nil || override == nil {
+			continue
+		}
+		endpoint := res[evmNetworkID]
+		if override.Host != "" {
+			endpoint.Host = override.Host
+		}
+		if override.Vault != "" {
+			endpoint.Vault = override.Vault
+		}
+		res[evmNetworkID] = endpoint
 	}
 
-	btcClient, err := portal.NewBTCTestNetClient()
-	if err != nil {
-		return err
+	return res
+}
+
+// newBTCClient returns the BTCClient for the given Incognito network. By default, BlockCypher is used; a BTC full-node
+// can be used instead by setting the BTC backend of the active profile to rpc.
+func newBTCClient(networkName string) (*portal.BTCClient, error) {
+	if activeProfile != nil && activeProfile.BTC != nil && activeProfile.BTC.Backend == btcRPCBackend {
+		btc := activeProfile.BTC
+		return portal.NewBTCRPCClient(btc.Host, btc.User, btc.Password, btc.DisableTLS)
 	}
 
-	cfg = NewConfig(incClient, evmClients, btcClient, evmVaultAddresses)
-	return nil
+	if networkName == "mainnet" {
+		return portal.NewBTCMainNetClient()
+	}
+	return portal.NewBTCTestNetClient()
 }
@@ -0,0 +1,700 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	// defaultDaemonAddress is the default address on which the daemon serves its API.
+	defaultDaemonAddress = "127.0.0.1:9338"
+
+	// daemonInfoFileName is the file, under the CLI home directory, through which the other commands find the daemon.
+	daemonInfoFileName = "daemon.json"
+)
+
+// JSON-RPC 2.0 error codes of the daemon, in addition to the codes of the appErrors returned by the methods.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+)
+
+// daemonInfo is written by a running daemon so that the other commands can reach it.
+type daemonInfo struct {
+	Address string
+	Token   string
+	PID     int
+	Network string
+	Host    string `json:",omitempty"`
+}
+
+// daemonInfoFile returns the path of the file holding the daemonInfo.
+func daemonInfoFile() (string, error) {
+	homeDir, err := cliHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, daemonInfoFileName), nil
+}
+
+// rpcRequest is a JSON-RPC 2.0 request.
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// rpcError is the error of a JSON-RPC 2.0 response. The code of an appError is kept, with its cause as the data.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    string `json:"data,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// accountParams identifies a registered account by its payment address, and optionally a token.
+type accountParams struct {
+	PaymentAddress string
+	TokenID        string `json:",omitempty"`
+}
+
+// sendParams are the parameters of the `send` method.
+type sendParams struct {
+	PaymentAddress string
+	Receiver       string
+	Amount         uint64
+	TokenID        string
+	Fee            uint64
+	Version        int8
+}
+
+// pdexQuoteParams are the parameters of the `pdexQuote` method. All the pool pairs of the tokens are checked if no
+// PairID is given.
+type pdexQuoteParams struct {
+	TokenToSell   string
+	TokenToBuy    string
+	SellingAmount uint64
+	PairID        string `json:",omitempty"`
+}
+
+// pdexQuoteResult is the result of the `pdexQuote` method.
+type pdexQuoteResult struct {
+	BestPairID   string
+	BestReceived uint64
+}
+
+// accountStatus is the sync status of a registered account.
+type accountStatus struct {
+	Name           string
+	PaymentAddress string
+	LastSync       *time.Time `json:",omitempty"`
+	SyncError      string     `json:",omitempty"`
+	Balances       map[string]uint64
+}
+
+// daemonStatus is the result of the `status` method.
+type daemonStatus struct {
+	Network   string
+	Host      string `json:",omitempty"`
+	StartTime time.Time
+	Accounts  []accountStatus
+}
+
+// utxoSnapshot is the list of UTXOs of a token at the last sync, along with their indices.
+type utxoSnapshot struct {
+	coins   []coin.PlainCoin
+	indices []uint64
+}
+
+// fingerprint changes whenever a UTXO of the snapshot is spent or received.
+func (s utxoSnapshot) fingerprint() string {
+	sum, maxIndex := uint64(0), uint64(0)
+	for i, utxo := range s.coins {
+		sum += utxo.GetValue()
+		if s.indices[i] > maxIndex {
+			maxIndex = s.indices[i]
+		}
+	}
+
+	return fmt.Sprintf("%v-%v-%v", len(s.coins), sum, maxIndex)
+}
+
+// historySnapshot is the history of a token, along with the fingerprint of the UTXOs it was computed from.
+type historySnapshot struct {
+	history     *incclient.TxHistory
+	fingerprint string
+}
+
+// daemonAccount is an account registered to the daemon.
+type daemonAccount struct {
+	name           string
+	privateKey     string
+	paymentAddress string
+
+	mtx       sync.RWMutex
+	utxos     map[string]utxoSnapshot
+	histories map[string]historySnapshot
+	lastSync  time.Time
+	syncErr   error
+}
+
+// balance returns the balance of a token at the last sync, and whether the token is synced.
+func (acc *daemonAccount) balance(tokenIDStr string) (uint64, bool) {
+	acc.mtx.RLock()
+	defer acc.mtx.RUnlock()
+
+	snapshot, ok := acc.utxos[tokenIDStr]
+	if !ok {
+		return 0, false
+	}
+	res := uint64(0)
+	for _, utxo := range snapshot.coins {
+		res += utxo.GetValue()
+	}
+
+	return res, true
+}
+
+// daemon keeps the Incognito client warm and the UTXOs of the registered accounts synced, and serves them over a
+// local JSON-RPC API.
+type daemon struct {
+	info       daemonInfo
+	accounts   map[string]*daemonAccount
+	numThreads int
+	startTime  time.Time
+
+	// coinMtx serializes the retrieval of coins, which share the UTXO cache of the SDK.
+	coinMtx sync.Mutex
+	// syncNow triggers a sync before the next tick, e.g. after a transaction is sent.
+	syncNow chan struct{}
+}
+
+// syncToken retrieves the UTXOs of a token of an account and updates its snapshot.
+func (d *daemon) syncToken(acc *daemonAccount, tokenIDStr string) (utxoSnapshot, error) {
+	d.coinMtx.Lock()
+	utxos, idxList, err := cfg.incClient.GetUnspentOutputCoins(acc.privateKey, tokenIDStr, 0)
+	d.coinMtx.Unlock()
+	if err != nil {
+		return utxoSnapshot{}, err
+	}
+
+	res := utxoSnapshot{coins: utxos, indices: make([]uint64, 0)}
+	for _, idx := range idxList {
+		res.indices = append(res.indices, idx.Uint64())
+	}
+	acc.mtx.Lock()
+	acc.utxos[tokenIDStr] = res
+	acc.mtx.Unlock()
+
+	return res, nil
+}
+
+// syncHistory re-computes the history of a token of an account, unless its UTXOs have not changed since the last time.
+func (d *daemon) syncHistory(acc *daemonAccount, tokenIDStr string, snapshot utxoSnapshot) (*incclient.TxHistory, error) {
+	acc.mtx.RLock()
+	cached, ok := acc.histories[tokenIDStr]
+	acc.mtx.RUnlock()
+	if ok && cached.fingerprint == snapshot.fingerprint() {
+		return cached.history, nil
+	}
+
+	history, err := incclient.NewTxHistoryProcessor(cfg.incClient, d.numThreads).GetTokenHistory(acc.privateKey, tokenIDStr)
+	if err != nil {
+		return nil, err
+	}
+	acc.mtx.Lock()
+	acc.histories[tokenIDStr] = historySnapshot{history: history, fingerprint: snapshot.fingerprint()}
+	acc.mtx.Unlock()
+
+	return history, nil
+}
+
+// syncAccount syncs PRV, the tokens held by an account, and the tokens requested since the daemon started. The
+// histories requested before are refreshed as well.
+func (d *daemon) syncAccount(acc *daemonAccount) error {
+	d.coinMtx.Lock()
+	balances, err := cfg.incClient.GetAllBalancesV2(acc.privateKey)
+	d.coinMtx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	acc.mtx.RLock()
+	tokenIDs := map[string]bool{common.PRVIDStr: true}
+	for tokenID := range balances {
+		tokenIDs[tokenID] = true
+	}
+	for tokenID := range acc.utxos {
+		tokenIDs[tokenID] = true
+	}
+	histories := make([]string, 0)
+	for tokenID := range acc.histories {
+		histories = append(histories, tokenID)
+	}
+	acc.mtx.RUnlock()
+
+	snapshots := make(map[string]utxoSnapshot)
+	for tokenID := range tokenIDs {
+		snapshot, err := d.syncToken(acc, tokenID)
+		if err != nil {
+			return fmt.Errorf("token %v: %v", tokenID, err)
+		}
+		snapshots[tokenID] = snapshot
+	}
+	for _, tokenID := range histories {
+		if _, err := d.syncHistory(acc, tokenID, snapshots[tokenID]); err != nil {
+			return fmt.Errorf("history of token %v: %v", tokenID, err)
+		}
+	}
+
+	return nil
+}
+
+// syncAll syncs the registered accounts, one after another.
+func (d *daemon) syncAll() {
+	for _, acc := range d.accounts {
+		start := time.Now()
+		err := d.syncAccount(acc)
+		acc.mtx.Lock()
+		acc.syncErr = err
+		if err == nil {
+			acc.lastSync = time.Now()
+		}
+		acc.mtx.Unlock()
+		if err != nil {
+			log.Printf("Cannot sync account %v: %v\n", acc.name, err)
+		} else {
+			log.Printf("Account %v synced in %v\n", acc.name, time.Since(start).Round(time.Millisecond))
+		}
+	}
+}
+
+// syncLoop syncs the registered accounts every interval until the context is done.
+func (d *daemon) syncLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		d.syncAll()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.syncNow:
+		}
+	}
+}
+
+// getAccount returns the registered account of a payment address.
+func (d *daemon) getAccount(paymentAddress string) (*daemonAccount, error) {
+	acc, ok := d.accounts[paymentAddress]
+	if !ok {
+		return nil, newAppError(AccountNotFoundError, fmt.Errorf("%v is not registered to the daemon", paymentAddress))
+	}
+
+	return acc, nil
+}
+
+// getSnapshot returns the UTXOs of a token of an account, syncing it first if the token has not been synced yet.
+func (d *daemon) getSnapshot(acc *daemonAccount, tokenIDStr string) (utxoSnapshot, error) {
+	acc.mtx.RLock()
+	snapshot, ok := acc.utxos[tokenIDStr]
+	acc.mtx.RUnlock()
+	if ok {
+		return snapshot, nil
+	}
+
+	return d.syncToken(acc, tokenIDStr)
+}
+
+// decodeAccountParams decodes the parameters of a method taking an account and a token (PRV by default).
+func (d *daemon) decodeAccountParams(rawParams json.RawMessage) (*daemonAccount, string, error) {
+	var params accountParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, "", err
+	}
+	if params.TokenID == "" {
+		params.TokenID = common.PRVIDStr
+	}
+	if !isValidTokenID(params.TokenID) {
+		return nil, "", newAppError(InvalidTokenIDError)
+	}
+	acc, err := d.getAccount(params.PaymentAddress)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return acc, params.TokenID, nil
+}
+
+func (d *daemon) status(json.RawMessage) (interface{}, error) {
+	res := daemonStatus{Network: d.info.Network, Host: d.info.Host, StartTime: d.startTime, Accounts: make([]accountStatus, 0)}
+	for _, acc := range d.accounts {
+		status := accountStatus{Name: acc.name, PaymentAddress: acc.paymentAddress, Balances: make(map[string]uint64)}
+		acc.mtx.RLock()
+		if !acc.lastSync.IsZero() {
+			lastSync := acc.lastSync
+			status.LastSync = &lastSync
+		}
+		if acc.syncErr != nil {
+			status.SyncError = acc.syncErr.Error()
+		}
+		for tokenID, snapshot := range acc.utxos {
+			for _, utxo := range snapshot.coins {
+				status.Balances[tokenID] += utxo.GetValue()
+			}
+		}
+		acc.mtx.RUnlock()
+		res.Accounts = append(res.Accounts, status)
+	}
+	sort.Slice(res.Accounts, func(i, j int) bool { return res.Accounts[i].Name < res.Accounts[j].Name })
+
+	return res, nil
+}
+
+func (d *daemon) balance(rawParams json.RawMessage) (interface{}, error) {
+	acc, tokenIDStr, err := d.decodeAccountParams(rawParams)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = d.getSnapshot(acc, tokenIDStr); err != nil {
+		return nil, newAppError(GetBalanceError, err)
+	}
+	res, _ := acc.balance(tokenIDStr)
+
+	return res, nil
+}
+
+func (d *daemon) utxos(rawParams json.RawMessage) (interface{}, error) {
+	acc, tokenIDStr, err := d.decodeAccountParams(rawParams)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := d.getSnapshot(acc, tokenIDStr)
+	if err != nil {
+		return nil, newAppError(GetUnspentOutputCoinsError, err)
+	}
+
+	return newUTXOListResult(snapshot.coins, snapshot.indices).UTXOs, nil
+}
+
+func (d *daemon) history(rawParams json.RawMessage) (interface{}, error) {
+	acc, tokenIDStr, err := d.decodeAccountParams(rawParams)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, err := d.getSnapshot(acc, tokenIDStr)
+	if err != nil {
+		return nil, newAppError(GetHistoryError, err)
+	}
+	res, err := d.syncHistory(acc, tokenIDStr, snapshot)
+	if err != nil {
+		return nil, newAppError(GetHistoryError, err)
+	}
+
+	return res, nil
+}
+
+func (d *daemon) send(rawParams json.RawMessage) (interface{}, error) {
+	var params sendParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, err
+	}
+	acc, err := d.getAccount(params.PaymentAddress)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidAddress(params.Receiver) {
+		return nil, newAppError(InvalidPaymentAddressError)
+	}
+	if !isValidTokenID(params.TokenID) {
+		return nil, newAppError(InvalidTokenIDError)
+	}
+	if params.Amount == 0 {
+		return nil, newAppError(InvalidAmountError)
+	}
+	if !isSupportedVersion(params.Version) {
+		return nil, newAppError(VersionError)
+	}
+
+	log.Printf("Account %v sends %v of token %v to %v\n", acc.name, params.Amount, params.TokenID, params.Receiver)
+	d.coinMtx.Lock()
+	txHash, err := createAndSendRawTx(acc.privateKey, []string{params.Receiver}, []uint64{params.Amount}, params.TokenID,
+		params.Fee, nil, params.Version)
+	d.coinMtx.Unlock()
+	if err != nil {
+		return nil, newAppError(CreateTransferTransactionError, err)
+	}
+
+	select {
+	case d.syncNow <- struct{}{}:
+	default:
+	}
+
+	return txHash, nil
+}
+
+func (d *daemon) pdexQuote(rawParams json.RawMessage) (interface{}, error) {
+	var params pdexQuoteParams
+	if err := json.Unmarshal(rawParams, &params); err != nil {
+		return nil, err
+	}
+	if !isValidTokenID(params.TokenToSell) {
+		return nil, newAppError(InvalidSellTokenIDError)
+	}
+	if !isValidTokenID(params.TokenToBuy) {
+		return nil, newAppError(InvalidBuyTokenIDError)
+	}
+	if params.SellingAmount == 0 {
+		return nil, newAppError(InvalidSellAmountError)
+	}
+
+	pairID, received, err := checkBestPrice(params.TokenToSell, params.TokenToBuy, params.SellingAmount, params.PairID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pdexQuoteResult{BestPairID: pairID, BestReceived: received}, nil
+}
+
+// methods returns the JSON-RPC methods of the daemon.
+func (d *daemon) methods() map[string]func(json.RawMessage) (interface{}, error) {
+	return map[string]func(json.RawMessage) (interface{}, error){
+		"status":    d.status,
+		"balance":   d.balance,
+		"utxos":     d.utxos,
+		"history":   d.history,
+		"send":      d.send,
+		"pdexQuote": d.pdexQuote,
+	}
+}
+
+// newRPCError converts the error of a method into a JSON-RPC error. Errors other than appErrors come from decoding
+// the parameters.
+func newRPCError(err error) *rpcError {
+	var appErr appError
+	if !errors.As(err, &appErr) {
+		return &rpcError{Code: rpcInvalidParams, Message: "Invalid params", Data: err.Error()}
+	}
+	res := &rpcError{Code: appErr.Code, Message: appErr.Message}
+	if appErr.Err != nil {
+		res.Data = appErr.Err.Error()
+	}
+
+	return res
+}
+
+// ServeHTTP handles the JSON-RPC requests, authenticated by the token of the daemon.
+func (d *daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST requests are supported", http.StatusMethodNotAllowed)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+d.info.Token)) != 1 {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	var req rpcRequest
+	res := rpcResponse{JSONRPC: "2.0"}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		res.Error = &rpcError{Code: rpcParseError, Message: "Parse error", Data: err.Error()}
+	} else if res.ID = req.ID; req.JSONRPC != "2.0" {
+		res.Error = &rpcError{Code: rpcInvalidRequest, Message: "Invalid request", Data: "expected jsonrpc 2.0"}
+	} else if method, ok := d.methods()[req.Method]; !ok {
+		res.Error = &rpcError{Code: rpcMethodNotFound, Message: "Method not found", Data: req.Method}
+	} else if result, err := method(req.Params); err != nil {
+		res.Error = newRPCError(err)
+	} else if res.Result, err = json.Marshal(result); err != nil {
+		res.Error = newRPCError(newAppError(UnexpectedError, err))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Println(err)
+	}
+}
+
+// checkLoopbackAddress makes sure that the daemon is only reachable from the local machine.
+func checkLoopbackAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("expected a loopback address, got %v", address)
+	}
+
+	return nil
+}
+
+// newDaemonToken returns a random token authenticating the requests to the daemon.
+func newDaemonToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token), nil
+}
+
+// runDaemon runs the daemon until it is interrupted.
+// The network is initialized here rather than in a Before function, which would also run for the subcommands.
+func runDaemon(c *cli.Context) error {
+	if !hasKeystorePassphrase() && !terminal.IsTerminal(int(os.Stdin.Fd())) {
+		return newAppError(UserInputError, fmt.Errorf("no terminal to ask for the passphrases of the accounts, "+
+			"give them with the %v flag or the %v environment variable", passphraseFlag, keystorePassphraseEnv))
+	}
+	err := cacheBeforeFunc(c)
+	if err != nil {
+		return err
+	}
+
+	accountNames := c.StringSlice(accountsFlag)
+	if len(accountNames) == 0 {
+		return newAppError(UserInputError, fmt.Errorf("at least one account must be registered with the %v flag", accountsFlag))
+	}
+	address := c.String(listenFlag)
+	if err := checkLoopbackAddress(address); err != nil {
+		return newAppError(UserInputError, err)
+	}
+	interval := c.Duration(syncIntervalFlag)
+	if interval <= 0 {
+		return newAppError(UserInputError, fmt.Errorf("syncInterval must be positive"))
+	}
+	numThreads := c.Int(numThreadsFlag)
+	if numThreads <= 0 {
+		return newAppError(NumThreadsError)
+	}
+	infoFile, err := daemonInfoFile()
+	if err != nil {
+		return newAppError(DaemonError, err)
+	}
+	if dc := getDaemonClient(); dc != nil {
+		if err := dc.call("status", nil, nil); err == nil {
+			return newAppError(DaemonError, fmt.Errorf("a daemon is already running at %v", dc.info.Address))
+		}
+	}
+
+	token, err := newDaemonToken()
+	if err != nil {
+		return newAppError(DaemonError, err)
+	}
+	d := &daemon{
+		info:       daemonInfo{Address: address, Token: token, PID: os.Getpid(), Network: cfg.network, Host: host},
+		accounts:   make(map[string]*daemonAccount),
+		numThreads: numThreads,
+		startTime:  time.Now(),
+		syncNow:    make(chan struct{}, 1),
+	}
+	for _, name := range accountNames {
+		privateKey, err := unlockAccount(name)
+		if err != nil {
+			return err
+		}
+		paymentAddress := incclient.PrivateKeyToPaymentAddress(privateKey, -1)
+		d.accounts[paymentAddress] = &daemonAccount{
+			name:           name,
+			privateKey:     privateKey,
+			paymentAddress: paymentAddress,
+			utxos:          make(map[string]utxoSnapshot),
+			histories:      make(map[string]historySnapshot),
+		}
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return newAppError(DaemonError, err)
+	}
+	d.info.Address = listener.Addr().String()
+	err = os.MkdirAll(filepath.Dir(infoFile), 0700)
+	if err == nil {
+		err = writeJSONFile(infoFile, d.info)
+	}
+	if err != nil {
+		_ = listener.Close()
+		return newAppError(DaemonError, err)
+	}
+	defer func() {
+		if err := os.Remove(infoFile); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+	go d.syncLoop(ctx, interval)
+
+	server := &http.Server{Handler: d}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Println(err)
+		}
+	}()
+
+	log.Printf("Daemon listening on %v with %v accounts, stop it with Ctrl+C\n", d.info.Address, len(d.accounts))
+	err = server.Serve(listener)
+	if err != nil && err != http.ErrServerClosed {
+		return newAppError(DaemonError, err)
+	}
+	log.Println("Daemon stopped")
+
+	return nil
+}
+
+// daemonStatusCmd prints the status of the running daemon.
+func daemonStatusCmd(_ *cli.Context) error {
+	dc := getDaemonClient()
+	if dc == nil {
+		return newAppError(DaemonError, fmt.Errorf("no daemon is running for this network"))
+	}
+	var res daemonStatus
+	if err := dc.call("status", nil, &res); err != nil {
+		return err
+	}
+
+	return printResult(res)
+}
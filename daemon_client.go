@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+)
+
+// errDaemonUnavailable is returned when the daemon cannot be reached, in which case the commands do the job themselves.
+var errDaemonUnavailable = errors.New("daemon unavailable")
+
+// daemonClient calls the JSON-RPC API of a running daemon.
+type daemonClient struct {
+	info       daemonInfo
+	httpClient *http.Client
+}
+
+// getDaemonClient returns a client of the daemon running for the current network, or nil if there is none.
+func getDaemonClient() *daemonClient {
+	file, err := daemonInfoFile()
+	if err != nil {
+		return nil
+	}
+	if _, err := os.Stat(file); err != nil {
+		return nil
+	}
+	var info daemonInfo
+	if err := readJSONFile(file, &info); err != nil {
+		log.Printf("Cannot read %v: %v\n", file, err)
+		return nil
+	}
+	if cfg == nil || info.Network != cfg.network || info.Host != host {
+		return nil
+	}
+
+	return &daemonClient{
+		info: info,
+		httpClient: &http.Client{Transport: &http.Transport{
+			DialContext: (&net.Dialer{Timeout: time.Second}).DialContext,
+		}},
+	}
+}
+
+// toAppError converts the error returned by the daemon back into the appError of its code.
+func (e rpcError) toAppError() error {
+	var cause error
+	if e.Data != "" {
+		cause = errors.New(e.Data)
+	}
+	for key, codeMessage := range errCodeMessages {
+		if codeMessage.Code == e.Code {
+			return newAppError(key, cause)
+		}
+	}
+
+	return newAppError(DaemonError, fmt.Errorf("%v (%v): %v", e.Message, e.Code, e.Data))
+}
+
+// call calls a method of the daemon and decodes its result into result, unless it is nil.
+func (dc *daemonClient) call(method string, params, result interface{}) error {
+	req := rpcRequest{JSONRPC: "2.0", ID: 1, Method: method}
+	var err error
+	if params != nil {
+		req.Params, err = json.Marshal(params)
+		if err != nil {
+			return newAppError(DaemonError, err)
+		}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return newAppError(DaemonError, err)
+	}
+	httpReq, err := http.NewRequest(http.MethodPost, "http://"+dc.info.Address, bytes.NewReader(body))
+	if err != nil {
+		return newAppError(DaemonError, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+dc.info.Token)
+
+	httpResp, err := dc.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errDaemonUnavailable, err)
+	}
+	defer func() {
+		if err := httpResp.Body.Close(); err != nil {
+			log.Println(err)
+		}
+	}()
+	if httpResp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %v", errDaemonUnavailable, httpResp.Status)
+	}
+
+	var resp rpcResponse
+	if err = json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
+		return newAppError(DaemonError, err)
+	}
+	if resp.Error != nil {
+		return resp.Error.toAppError()
+	}
+	if result != nil {
+		if err = json.Unmarshal(resp.Result, result); err != nil {
+			return newAppError(DaemonError, err)
+		}
+	}
+
+	return nil
+}
+
+// tryDaemon calls a method of the daemon if one is running for the current network. It returns false if the command
+// must do the job itself: no daemon is running, it cannot be reached, or the account is not registered to it.
+func tryDaemon(method string, params, result interface{}) (bool, error) {
+	dc := getDaemonClient()
+	if dc == nil {
+		return false, nil
+	}
+
+	err := dc.call(method, params, result)
+	switch {
+	case errors.Is(err, errDaemonUnavailable):
+		log.Printf("Cannot reach the daemon at %v (%v), continuing without it\n", dc.info.Address, err)
+		return false, nil
+	case isAppError(err, AccountNotFoundError):
+		return false, nil
+	}
+
+	return true, err
+}
+
+// newAccountParams returns the parameters identifying the account of a private key to the daemon.
+func newAccountParams(privateKey, tokenIDStr string) accountParams {
+	return accountParams{PaymentAddress: incclient.PrivateKeyToPaymentAddress(privateKey, -1), TokenID: tokenIDStr}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+)
+
+func TestCheckLoopbackAddress(t *testing.T) {
+	testCases := []struct {
+		address string
+		isValid bool
+	}{
+		{"127.0.0.1:9338", true},
+		{"localhost:9338", true},
+		{"[::1]:9338", true},
+		{"0.0.0.0:9338", false},
+		{"192.168.1.2:9338", false},
+		{":9338", false},
+		{"127.0.0.1", false},
+	}
+
+	for _, tc := range testCases {
+		err := checkLoopbackAddress(tc.address)
+		if (err == nil) != tc.isValid {
+			t.Fatalf("address %v: expect valid = %v, got error %v", tc.address, tc.isValid, err)
+		}
+	}
+}
+
+func TestDaemonRPC(t *testing.T) {
+	d := &daemon{
+		info:     daemonInfo{Token: "token", Network: "testnet"},
+		accounts: make(map[string]*daemonAccount),
+		syncNow:  make(chan struct{}, 1),
+	}
+	srv := httptest.NewServer(d)
+	defer srv.Close()
+	dc := &daemonClient{
+		info:       daemonInfo{Address: strings.TrimPrefix(srv.URL, "http://"), Token: "token"},
+		httpClient: srv.Client(),
+	}
+
+	var status daemonStatus
+	if err := dc.call("status", nil, &status); err != nil {
+		t.Fatal(err)
+	}
+	if status.Network != "testnet" || len(status.Accounts) != 0 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	err := dc.call("balance", accountParams{PaymentAddress: "unknown"}, new(uint64))
+	if !isAppError(err, AccountNotFoundError) {
+		t.Fatalf("expect AccountNotFoundError, got %v", err)
+	}
+
+	params := pdexQuoteParams{TokenToSell: common.PRVIDStr, TokenToBuy: common.PRVIDStr}
+	err = dc.call("pdexQuote", params, nil)
+	if !isAppError(err, InvalidSellAmountError) {
+		t.Fatalf("expect InvalidSellAmountError, got %v", err)
+	}
+
+	err = dc.call("balance", "invalid params", nil)
+	if !isAppError(err, DaemonError) {
+		t.Fatalf("expect DaemonError, got %v", err)
+	}
+
+	err = dc.call("unknown", nil, nil)
+	if !isAppError(err, DaemonError) {
+		t.Fatalf("expect DaemonError, got %v", err)
+	}
+
+	dc.info.Token = "wrong token"
+	err = dc.call("status", nil, nil)
+	if !errors.Is(err, errDaemonUnavailable) {
+		t.Fatalf("expect errDaemonUnavailable, got %v", err)
+	}
+}
+
+func TestDaemonTxHistory(t *testing.T) {
+	md := &metadata.InitTokenRequest{Amount: 100, TokenName: "token"}
+	md.Type = metadata.InitTokenRequestMeta
+	h := incclient.TxHistory{
+		TxInList:  []incclient.TxIn{{TxHash: "in", Amount: 10}},
+		TxOutList: []incclient.TxOut{{TxHash: "out", Amount: 20, Metadata: md, Receivers: []string{"receiver"}}},
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var daemonHistory rawTxHistory
+	if err = json.Unmarshal(data, &daemonHistory); err != nil {
+		t.Fatal(err)
+	}
+	res, err := daemonHistory.toTxHistory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.TxInList) != 1 || res.TxInList[0].TxHash != "in" || res.TxInList[0].Amount != 10 ||
+		res.TxInList[0].Metadata != nil {
+		t.Fatalf("unexpected TxIns %+v", res.TxInList)
+	}
+	if len(res.TxOutList) != 1 || res.TxOutList[0].TxHash != "out" || res.TxOutList[0].Receivers[0] != "receiver" {
+		t.Fatalf("unexpected TxOuts %+v", res.TxOutList)
+	}
+	parsed, ok := res.TxOutList[0].Metadata.(*metadata.InitTokenRequest)
+	if !ok || parsed.Amount != 100 || parsed.TokenName != "token" {
+		t.Fatalf("unexpected metadata %+v", res.TxOutList[0].Metadata)
+	}
+}
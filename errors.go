@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"os"
+)
 
 const (
 	UnexpectedError = iota
@@ -9,6 +13,15 @@ const (
 	InvalidAmountError
 	InvalidIncognitoTxHashError
 	UserInputError
+	LoadConfigError
+	SaveConfigError
+	ProfileNotFoundError
+	InvalidConfigKeyError
+	InvalidConfigValueError
+	NetworkStatusError
+	UserAbortedError
+	InvalidFeeError
+	InvalidDateRangeError
 
 	InvalidPrivateKeyError
 	InvalidPaymentAddressError
@@ -16,6 +29,7 @@ const (
 	InvalidOTAKeyError
 	InvalidMiningKeyError
 	InvalidTokenIDError
+	InvalidMnemonicError
 
 	GetBalanceError
 	GetAllBalancesError
@@ -32,6 +46,26 @@ const (
 	ImportMnemonicError
 	SubmitKeyError
 	InsufficientBalanceError
+	LoadKeyStoreError
+	SaveKeyStoreError
+	AccountNotFoundError
+	AccountExistedError
+	DecryptAccountError
+	WatchOnlyAccountError
+	InvalidKeyImageFileError
+	SaveKeyImagesError
+	GetPortfolioError
+	SaveOutputFileError
+	DecryptFileError
+	InvalidBackupShareError
+	InvalidConversionStateError
+	GetTokenListError
+	CacheNotFoundError
+	CacheError
+	DaemonError
+	TokenNotFoundError
+	TokenRegistryError
+	KeyIndexingTimeoutError
 
 	CreateStakingTransactionError
 	CreateUnStakingTransactionError
@@ -43,6 +77,16 @@ const (
 	GetReceivingInfoError
 	SendRawTxError
 	SendRawTxTokenError
+	WaitTransactionError
+	SaveBatchResultError
+	InvalidBatchFileError
+	TxDroppedError
+	GetTxStatusError
+	EstimateFeeError
+	PrepareTransactionError
+	InvalidTxFileError
+	SignTransactionError
+	SaveTxFileError
 
 	CentralizedShieldError
 
@@ -64,6 +108,8 @@ const (
 	CreatePRVUnShieldingTransactionError
 	EVMBurnPRVError
 	EVMMintPRVError
+	ShieldingRejectedError
+	EstimateEVMGasPriceError
 
 	GenerateShieldingAddressError
 	BTCClientNotFoundError
@@ -133,6 +179,15 @@ var errCodeMessages = map[int]struct {
 	InvalidAmountError:          {-1003, "Invalid Incognito amount"},
 	InvalidIncognitoTxHashError: {-1004, "Invalid Incognito txHash"},
 	UserInputError:              {-1005, "User input error"},
+	LoadConfigError:             {-1006, "Cannot load the CLI config"},
+	SaveConfigError:             {-1007, "Cannot save the CLI config"},
+	ProfileNotFoundError:        {-1008, "Profile not found in the CLI config"},
+	InvalidConfigKeyError:       {-1009, "Invalid config key"},
+	InvalidConfigValueError:     {-1010, "Invalid config value"},
+	NetworkStatusError:          {-1011, "Some endpoints are unreachable"},
+	UserAbortedError:            {-1012, "Aborted by the user"},
+	InvalidFeeError:             {-1013, "Invalid transaction fee"},
+	InvalidDateRangeError:       {-1014, "Invalid date range"},
 
 	InvalidPrivateKeyError:     {-2000, "Invalid Incognito private key"},
 	InvalidPaymentAddressError: {-2001, "Invalid Incognito payment address"},
@@ -140,22 +195,43 @@ var errCodeMessages = map[int]struct {
 	InvalidOTAKeyError:         {-2003, "Invalid Incognito ota key"},
 	InvalidMiningKeyError:      {-2004, "Invalid Incognito mining key"},
 	InvalidTokenIDError:        {-2005, "Invalid Incognito tokenID"},
+	InvalidMnemonicError:       {-2006, "Invalid mnemonic"},
 
-	GetBalanceError:            {-3000, "Error when retrieving balance"},
-	GetAllBalancesError:        {-3001, "Error when retrieving all balances"},
-	GetAccountInfoError:        {-3002, "Error when getting account info"},
-	ConsolidateAccountError:    {-3003, "Consolidating error"},
-	GetUnspentOutputCoinsError: {-3004, "Get UTXO error"},
-	GetOutputCoinsError:        {-3005, "Get output coin error"},
-	GetHistoryError:            {-3006, "Get account history error"},
-	SaveHistoryError:           {-3007, "Save account history error"},
-	GenerateMasterKeyError:     {-3008, "Generate master key error"},
-	InvalidNumberShardsError:   {-3009, "Invalid number of shards"},
-	InvalidShardError:          {-3010, "Invalid shard"},
-	DeriveChildError:           {-3011, "Derive child error"},
-	ImportMnemonicError:        {-3012, "Cannot import mnemonic"},
-	SubmitKeyError:             {-3013, "Submit key error"},
-	InsufficientBalanceError:   {-3014, "Insufficient Incognito balance error"},
+	GetBalanceError:             {-3000, "Error when retrieving balance"},
+	GetAllBalancesError:         {-3001, "Error when retrieving all balances"},
+	GetAccountInfoError:         {-3002, "Error when getting account info"},
+	ConsolidateAccountError:     {-3003, "Consolidating error"},
+	GetUnspentOutputCoinsError:  {-3004, "Get UTXO error"},
+	GetOutputCoinsError:         {-3005, "Get output coin error"},
+	GetHistoryError:             {-3006, "Get account history error"},
+	SaveHistoryError:            {-3007, "Save account history error"},
+	GenerateMasterKeyError:      {-3008, "Generate master key error"},
+	InvalidNumberShardsError:    {-3009, "Invalid number of shards"},
+	InvalidShardError:           {-3010, "Invalid shard"},
+	DeriveChildError:            {-3011, "Derive child error"},
+	ImportMnemonicError:         {-3012, "Cannot import mnemonic"},
+	SubmitKeyError:              {-3013, "Submit key error"},
+	InsufficientBalanceError:    {-3014, "Insufficient Incognito balance error"},
+	LoadKeyStoreError:           {-3015, "Cannot load the keystore"},
+	SaveKeyStoreError:           {-3016, "Cannot save the keystore"},
+	AccountNotFoundError:        {-3017, "Account not found in the keystore"},
+	AccountExistedError:         {-3018, "Account already existed in the keystore"},
+	DecryptAccountError:         {-3019, "Cannot decrypt the account"},
+	WatchOnlyAccountError:       {-3020, "The account is watch-only and cannot spend"},
+	InvalidKeyImageFileError:    {-3021, "Invalid key image file"},
+	SaveKeyImagesError:          {-3022, "Cannot save the key images"},
+	GetPortfolioError:           {-3023, "Cannot build the portfolio"},
+	SaveOutputFileError:         {-3024, "Cannot save the output file"},
+	DecryptFileError:            {-3025, "Cannot decrypt the file"},
+	InvalidBackupShareError:     {-3026, "Invalid backup share"},
+	InvalidConversionStateError: {-3027, "Invalid conversion state file"},
+	GetTokenListError:           {-3028, "Cannot get the list of tokens"},
+	CacheNotFoundError:          {-3029, "UTXO cache not found"},
+	CacheError:                  {-3030, "Cannot manage the UTXO cache"},
+	DaemonError:                 {-3031, "Daemon error"},
+	TokenNotFoundError:          {-3032, "Token not found in the token list"},
+	TokenRegistryError:          {-3033, "Cannot update the token list"},
+	KeyIndexingTimeoutError:     {-3034, "The full-node has not finished indexing the OTA key"},
 
 	CreateStakingTransactionError:        {-4000, "Cannot create staking transaction"},
 	CreateUnStakingTransactionError:      {-4001, "Cannot create un-staking transaction"},
@@ -167,6 +243,16 @@ var errCodeMessages = map[int]struct {
 	GetReceivingInfoError:            {-5002, "Cannot get receiving info"},
 	SendRawTxError:                   {-5003, "Error while sendRawTx"},
 	SendRawTxTokenError:              {-5004, "Error while sendRawTxToken"},
+	WaitTransactionError:             {-5005, "Transaction not confirmed"},
+	SaveBatchResultError:             {-5006, "Cannot save the batch results"},
+	InvalidBatchFileError:            {-5007, "Invalid batch payment file"},
+	TxDroppedError:                   {-5008, "Transaction rejected or dropped by the network"},
+	GetTxStatusError:                 {-5009, "Cannot get transaction status"},
+	EstimateFeeError:                 {-5010, "Cannot estimate the transaction fee"},
+	PrepareTransactionError:          {-5011, "Cannot prepare the unsigned transaction"},
+	InvalidTxFileError:               {-5012, "Invalid transaction file"},
+	SignTransactionError:             {-5013, "Cannot sign the transaction"},
+	SaveTxFileError:                  {-5014, "Cannot save the transaction file"},
 
 	CentralizedShieldError: {-6000, "Cannot create centralized shielding transaction"},
 
@@ -188,6 +274,8 @@ var errCodeMessages = map[int]struct {
 	CreatePRVUnShieldingTransactionError: {-6125, "Cannot create PRV un-shielding transaction"},
 	EVMBurnPRVError:                      {-6126, "Cannot burn PRV on EVM network"},
 	EVMMintPRVError:                      {-6127, "Cannot mint PRV on EVM network"},
+	ShieldingRejectedError:               {-6128, "The shielding request was rejected by the Incognito network"},
+	EstimateEVMGasPriceError:             {-6129, "Cannot estimate the EVM gas price"},
 
 	GenerateShieldingAddressError:           {-6200, "Cannot generate shielding address"},
 	BTCClientNotFoundError:                  {-6201, "BTC client not found"},
@@ -247,29 +335,257 @@ var errCodeMessages = map[int]struct {
 	GetLPFeeWithdrawalStatusError:            {-7309, "Cannot get LP fee withdrawal status"},
 }
 
+// errorCategory groups the errors by their origin so that scripts can react to them without knowing every code.
+type errorCategory string
+
+// supported error categories.
+const (
+	InternalCategory          errorCategory = "internal"
+	UserInputCategory         errorCategory = "userInput"
+	NetworkCategory           errorCategory = "network"
+	ChainRejectionCategory    errorCategory = "chainRejection"
+	InsufficientFundsCategory errorCategory = "insufficientFunds"
+	TxDroppedCategory         errorCategory = "txDropped"
+)
+
+// exitCodes maps each error category to the exit code of the CLI:
+//
+//	0 - success
+//	1 - internal (or unexpected) errors
+//	2 - invalid user input, including aborted confirmations
+//	3 - network errors, i.e. a full-node, an EVM or a BTC endpoint cannot be reached or returns an error
+//	4 - the transaction is rejected by the Incognito chain or an EVM network
+//	5 - insufficient funds
+//	6 - a transaction accepted by the full-node was later rejected or dropped (see the wait flag)
+var exitCodes = map[errorCategory]int{
+	InternalCategory:          1,
+	UserInputCategory:         2,
+	NetworkCategory:           3,
+	ChainRejectionCategory:    4,
+	InsufficientFundsCategory: 5,
+	TxDroppedCategory:         6,
+}
+
+// errCategories holds the category of each error key. Keys not listed here belong to the InternalCategory.
+var errCategories = map[int]errorCategory{
+	VersionError:                    UserInputCategory,
+	NumThreadsError:                 UserInputCategory,
+	InvalidAmountError:              UserInputCategory,
+	InvalidIncognitoTxHashError:     UserInputCategory,
+	UserInputError:                  UserInputCategory,
+	ProfileNotFoundError:            UserInputCategory,
+	InvalidConfigKeyError:           UserInputCategory,
+	InvalidConfigValueError:         UserInputCategory,
+	UserAbortedError:                UserInputCategory,
+	InvalidFeeError:                 UserInputCategory,
+	InvalidDateRangeError:           UserInputCategory,
+	InvalidPrivateKeyError:          UserInputCategory,
+	InvalidPaymentAddressError:      UserInputCategory,
+	InvalidReadonlyKeyError:         UserInputCategory,
+	InvalidOTAKeyError:              UserInputCategory,
+	InvalidMiningKeyError:           UserInputCategory,
+	InvalidTokenIDError:             UserInputCategory,
+	InvalidMnemonicError:            UserInputCategory,
+	InvalidNumberShardsError:        UserInputCategory,
+	InvalidShardError:               UserInputCategory,
+	ImportMnemonicError:             UserInputCategory,
+	AccountNotFoundError:            UserInputCategory,
+	AccountExistedError:             UserInputCategory,
+	DecryptAccountError:             UserInputCategory,
+	WatchOnlyAccountError:           UserInputCategory,
+	InvalidKeyImageFileError:        UserInputCategory,
+	DecryptFileError:                UserInputCategory,
+	InvalidBackupShareError:         UserInputCategory,
+	InvalidConversionStateError:     UserInputCategory,
+	CacheNotFoundError:              UserInputCategory,
+	TokenNotFoundError:              UserInputCategory,
+	InvalidEVMTokenAddressError:     UserInputCategory,
+	WrongEVMNetworkError:            UserInputCategory,
+	NewEVMAccountError:              UserInputCategory,
+	InvalidExternalAddressError:     UserInputCategory,
+	InvalidSellTokenIDError:         UserInputCategory,
+	InvalidBuyTokenIDError:          UserInputCategory,
+	InvalidSellAmountError:          UserInputCategory,
+	InvalidMinAcceptableAmountError: UserInputCategory,
+	InvalidMaxTradingPathError:      UserInputCategory,
+	InvalidTradingPathError:         UserInputCategory,
+	InvalidPRVFeeError:              UserInputCategory,
+	InvalidTradingFeeError:          UserInputCategory,
+	InvalidPoolPairIDError:          UserInputCategory,
+	InvalidPairHashError:            UserInputCategory,
+	InvalidAmplifierError:           UserInputCategory,
+	InvalidNFTError:                 UserInputCategory,
+	InvalidOrderIDError:             UserInputCategory,
+	InvalidBatchFileError:           UserInputCategory,
+	InvalidTxFileError:              UserInputCategory,
+
+	NetworkStatusError:                       NetworkCategory,
+	GetBalanceError:                          NetworkCategory,
+	GetAllBalancesError:                      NetworkCategory,
+	GetAccountInfoError:                      NetworkCategory,
+	GetUnspentOutputCoinsError:               NetworkCategory,
+	GetOutputCoinsError:                      NetworkCategory,
+	GetPortfolioError:                        NetworkCategory,
+	GetTokenListError:                        NetworkCategory,
+	DaemonError:                              NetworkCategory,
+	TokenRegistryError:                       NetworkCategory,
+	KeyIndexingTimeoutError:                  NetworkCategory,
+	GetHistoryError:                          NetworkCategory,
+	GetRewardAmountError:                     NetworkCategory,
+	GetReceivingInfoError:                    NetworkCategory,
+	WaitTransactionError:                     NetworkCategory,
+	GetTxStatusError:                         NetworkCategory,
+	EstimateFeeError:                         NetworkCategory,
+	PrepareTransactionError:                  NetworkCategory,
+	GetEVMNetworkError:                       NetworkCategory,
+	EVMTokenIDToIncognitoTokenIDError:        NetworkCategory,
+	IncognitoTokenIDToEVMTokenIDError:        NetworkCategory,
+	GetEVMTokenInfoError:                     NetworkCategory,
+	GetEVMBalanceError:                       NetworkCategory,
+	GetEVMBurnProofError:                     NetworkCategory,
+	GetEVMShieldingStatusError:               NetworkCategory,
+	EstimateEVMGasPriceError:                 NetworkCategory,
+	BTCClientNotFoundError:                   NetworkCategory,
+	GetBTCConfirmationError:                  NetworkCategory,
+	NotEnoughBTCConfirmationError:            NetworkCategory,
+	GetPortalShieldingStatusError:            NetworkCategory,
+	GetPortalUnShieldingStatusError:          NetworkCategory,
+	GetAllDexPoolPairsError:                  NetworkCategory,
+	GetDexPoolPairError:                      NetworkCategory,
+	GetPoolShareError:                        NetworkCategory,
+	GetEstimatedLPValueError:                 NetworkCategory,
+	GetAllDexNFTsError:                       NetworkCategory,
+	GetOrderByIDError:                        NetworkCategory,
+	GetTradeStatusError:                      NetworkCategory,
+	GetNFTMintingStatusError:                 NetworkCategory,
+	GetDexContributionStatusError:            NetworkCategory,
+	GetDexWithdrawalStatusError:              NetworkCategory,
+	GetOrderAddingStatusError:                NetworkCategory,
+	GetOrderWithdrawalStatusError:            NetworkCategory,
+	GetDexStakingStatusError:                 NetworkCategory,
+	GetDexUnStakingStatusError:               NetworkCategory,
+	GetDexStakingRewardWithdrawalStatusError: NetworkCategory,
+	GetLPFeeWithdrawalStatusError:            NetworkCategory,
+
+	ConsolidateAccountError:                          ChainRejectionCategory,
+	SubmitKeyError:                                   ChainRejectionCategory,
+	CreateStakingTransactionError:                    ChainRejectionCategory,
+	CreateUnStakingTransactionError:                  ChainRejectionCategory,
+	CreateWithdrawRewardTransactionError:             ChainRejectionCategory,
+	CreateTransferTransactionError:                   ChainRejectionCategory,
+	CreateConversionTransactionError:                 ChainRejectionCategory,
+	SendRawTxError:                                   ChainRejectionCategory,
+	SendRawTxTokenError:                              ChainRejectionCategory,
+	CentralizedShieldError:                           ChainRejectionCategory,
+	CreateEVMShieldingTransactionError:               ChainRejectionCategory,
+	CreateEVMUnShieldingTransactionError:             ChainRejectionCategory,
+	EVMDepositError:                                  ChainRejectionCategory,
+	EVMWithdrawError:                                 ChainRejectionCategory,
+	CreatePRVShieldingTransactionError:               ChainRejectionCategory,
+	CreatePRVUnShieldingTransactionError:             ChainRejectionCategory,
+	EVMBurnPRVError:                                  ChainRejectionCategory,
+	EVMMintPRVError:                                  ChainRejectionCategory,
+	ShieldingRejectedError:                           ChainRejectionCategory,
+	CreatePortalShieldingTransactionError:            ChainRejectionCategory,
+	CreatePortalUnShieldingTransactionError:          ChainRejectionCategory,
+	CreateDexTradeTransactionError:                   ChainRejectionCategory,
+	CreateMintNFTTransactionError:                    ChainRejectionCategory,
+	CreateDexContributionTransactionError:            ChainRejectionCategory,
+	CreateDexWithdrawalTransactionError:              ChainRejectionCategory,
+	CreateAddOrderTransactionError:                   ChainRejectionCategory,
+	CreateWithdrawOrderTransactionError:              ChainRejectionCategory,
+	CreateDexStakingTransactionError:                 ChainRejectionCategory,
+	CreateDexUnStakingTransactionError:               ChainRejectionCategory,
+	CreateDexStakingRewardWithdrawalTransactionError: ChainRejectionCategory,
+	CreateLPFeeWithdrawalTransactionError:            ChainRejectionCategory,
+
+	InsufficientBalanceError: InsufficientFundsCategory,
+
+	TxDroppedError: TxDroppedCategory,
+}
+
+// appError is the error returned by all commands. It wraps the underlying error so that it can be inspected
+// with errors.Is and errors.As; two appErrors are considered the same by errors.Is if they have the same code.
 type appError struct {
-	Code    int
-	Message string
-	Err     error
+	Code     int
+	Message  string
+	Category errorCategory
+	Err      error
 }
 
 // Error satisfies the error interface and prints human-readable errors.
-func (e appError) Error() error {
+func (e appError) Error() string {
 	if e.Err != nil {
-		return fmt.Errorf("[%d] %s: %v", e.Code, e.Message, e.Err)
+		return fmt.Sprintf("[%d] %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("[%d] %s", e.Code, e.Message)
+}
+
+// Unwrap returns the underlying error.
+func (e appError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an appError with the same code, e.g. errors.Is(err, newAppError(InsufficientBalanceError)).
+func (e appError) Is(target error) bool {
+	t, ok := target.(appError)
+	return ok && t.Code == e.Code
+}
+
+// exitCode returns the exit code of the CLI for the error (see exitCodes).
+func (e appError) exitCode() int {
+	if res, ok := exitCodes[e.Category]; ok {
+		return res
 	}
-	return fmt.Errorf("[%d] %s", e.Code, e.Message)
+	return exitCodes[InternalCategory]
 }
 
+// errorOutput is the structured form in which errors are printed.
+type errorOutput struct {
+	Code     int           `json:"code"`
+	Message  string        `json:"message"`
+	Category errorCategory `json:"category"`
+	Cause    string        `json:"cause,omitempty"`
+}
+
+// newAppError returns the appError of the given key (e.g, InsufficientBalanceError), wrapping the optional err.
 func newAppError(key int, err ...error) error {
 	res := appError{
-		Code:    errCodeMessages[key].Code,
-		Message: errCodeMessages[key].Message,
+		Code:     errCodeMessages[key].Code,
+		Message:  errCodeMessages[key].Message,
+		Category: InternalCategory,
+	}
+	if category, ok := errCategories[key]; ok {
+		res.Category = category
 	}
 
 	if len(err) > 0 {
 		res.Err = err[0]
 	}
 
-	return res.Error()
+	return res
+}
+
+// isAppError reports whether err (or any error it wraps) is the appError of the given key.
+func isAppError(err error, key int) bool {
+	return errors.Is(err, newAppError(key))
+}
+
+// handleError prints an error to the standard error in the format chosen by the global output flag,
+// and returns the exit code of the CLI.
+func handleError(err error) int {
+	var appErr appError
+	if !errors.As(err, &appErr) {
+		appErr = newAppError(UnexpectedError, err).(appError)
+	}
+
+	res := errorOutput{Code: appErr.Code, Message: appErr.Message, Category: appErr.Category}
+	if appErr.Err != nil {
+		res.Cause = appErr.Err.Error()
+	}
+	if renderErr := render(os.Stderr, res); renderErr != nil {
+		_, _ = fmt.Fprintln(os.Stderr, appErr.Error())
+	}
+
+	return appErr.exitCode()
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAppError_IsAs(t *testing.T) {
+	cause := fmt.Errorf("not enough PRV")
+	err := fmt.Errorf("sending failed: %w", newAppError(InsufficientBalanceError, cause))
+
+	if !isAppError(err, InsufficientBalanceError) {
+		t.Fatalf("expect %v to be an InsufficientBalanceError", err)
+	}
+	if isAppError(err, GetEVMNetworkError) {
+		t.Fatalf("expect %v not to be a GetEVMNetworkError", err)
+	}
+	if !errors.Is(err, cause) {
+		t.Fatalf("expect %v to wrap %v", err, cause)
+	}
+
+	var appErr appError
+	if !errors.As(err, &appErr) {
+		t.Fatalf("expect %v to be an appError", err)
+	}
+	if appErr.Code != errCodeMessages[InsufficientBalanceError].Code {
+		t.Fatalf("expect code %v, got %v", errCodeMessages[InsufficientBalanceError].Code, appErr.Code)
+	}
+}
+
+func TestAppError_ExitCode(t *testing.T) {
+	testCases := map[int]int{
+		UnexpectedError:                1,
+		SaveKeyStoreError:              1,
+		InvalidPrivateKeyError:         2,
+		UserAbortedError:               2,
+		GetEVMNetworkError:             3,
+		CreateTransferTransactionError: 4,
+		InsufficientBalanceError:       5,
+		TxDroppedError:                 6,
+	}
+	for key, expected := range testCases {
+		appErr := newAppError(key).(appError)
+		if appErr.exitCode() != expected {
+			t.Fatalf("expect exit code of %v to be %v, got %v", appErr.Message, expected, appErr.exitCode())
+		}
+	}
+
+	for key := range errCodeMessages {
+		if _, ok := exitCodes[newAppError(key).(appError).Category]; !ok {
+			t.Fatalf("no exit code for the category of %v", errCodeMessages[key].Message)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/incognitochain/go-incognito-sdk-v2/common"
 	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
@@ -9,6 +10,10 @@ import (
 	"github.com/urfave/cli/v2"
 )
 
+// tokenAmountFormats describes the formats accepted for the amount of a token (see parseAmount).
+const tokenAmountFormats = "either an integer in the smallest unit of the token (e.g, 1500000000) or a decimal number of " +
+	"tokens (e.g, 1.5, 1.5PRV)"
+
 var defaultFlags = map[string]cli.Flag{
 	networkFlag: &cli.StringFlag{
 		Name:        networkFlag,
@@ -44,11 +49,44 @@ var defaultFlags = map[string]cli.Flag{
 		Value:       0,
 		Destination: &cache,
 	},
+	accountFlag: &cli.StringFlag{
+		Name:        accountFlag,
+		Aliases:     aliases[accountFlag],
+		Usage:       "Name of a keystore account to be used in place of the privateKey flag (see the command: account keystore)",
+		Value:       "",
+		Destination: &accountName,
+	},
+	passphraseFlag: &cli.StringFlag{
+		Name: passphraseFlag,
+		Usage: "A file holding the passphrase of the keystore account given by the account flag. Without it, the " +
+			"passphrase is read from the " + keystorePassphraseEnv + " environment variable, or else asked to the user",
+		Value:       "",
+		Destination: &passphraseFile,
+	},
+	profileFlag: &cli.StringFlag{
+		Name:        profileFlag,
+		Usage:       "Name of the config profile to be used in place of the active one (see the command: config)",
+		Value:       "",
+		Destination: &profileName,
+	},
+	outputFlag: &cli.StringFlag{
+		Name:        outputFlag,
+		Aliases:     []string{"o"},
+		Usage:       "Output format of the results (json, yaml, table, text). Logs are always written to the standard error",
+		Value:       jsonOutput,
+		Destination: &outputFormat,
+	},
+	yesFlag: &cli.BoolFlag{
+		Name:        yesFlag,
+		Aliases:     []string{"y"},
+		Usage:       "Assume yes to all confirmations and never prompt for input (e.g, to run from scripts or cron jobs)",
+		Value:       false,
+		Destination: &assumeYes,
+	},
 	privateKeyFlag: &cli.StringFlag{
-		Name:     privateKeyFlag,
-		Aliases:  aliases[privateKeyFlag],
-		Usage:    "A base58-encoded Incognito private key",
-		Required: true,
+		Name:    privateKeyFlag,
+		Aliases: aliases[privateKeyFlag],
+		Usage:   "A base58-encoded Incognito private key (required unless the global account flag is set)",
 	},
 	addressFlag: &cli.StringFlag{
 		Name:     addressFlag,
@@ -72,19 +110,18 @@ var defaultFlags = map[string]cli.Flag{
 	tokenIDFlag: &cli.StringFlag{
 		Name:    tokenIDFlag,
 		Aliases: aliases[tokenIDFlag],
-		Usage:   "The Incognito ID of the token",
+		Usage:   "The Incognito ID of the token, or the symbol of a verified token (e.g, PRV, USDT, USDT:BSC)",
 		Value:   common.PRVIDStr,
 	},
-	amountFlag: &cli.Uint64Flag{
+	amountFlag: &cli.StringFlag{
 		Name:     amountFlag,
 		Aliases:  aliases[amountFlag],
-		Usage:    "The Incognito (uint64) amount of the action (e.g, 1000, 1000000, 1000000000)",
+		Usage:    "The amount of the action, " + tokenAmountFormats,
 		Required: true,
 	},
 	feeFlag: &cli.Uint64Flag{
 		Name:  feeFlag,
-		Usage: "The PRV amount for paying the transaction fee",
-		Value: incclient.DefaultPRVFee,
+		Usage: fmt.Sprintf("The PRV amount for paying the transaction fee (default: the fee of the active profile, or %v)", incclient.DefaultPRVFee),
 	},
 	versionFlag: &cli.IntFlag{
 		Name:    versionFlag,
@@ -112,6 +149,137 @@ var defaultFlags = map[string]cli.Flag{
 		Aliases: aliases[csvFileFlag],
 		Usage:   "The csv file location to store the history",
 	},
+	batchFileFlag: &cli.StringFlag{
+		Name:     batchFileFlag,
+		Aliases:  []string{"f"},
+		Usage:    "A CSV file of payments, one address,amount,tokenID row per payment (the tokenID column, a tokenID or symbol, is optional, default: PRV; amounts as for the amount flag)",
+		Required: true,
+	},
+	resultFileFlag: &cli.StringFlag{
+		Name:  resultFileFlag,
+		Usage: "The CSV file to store the result of each payment; it is also used to resume an interrupted run (default: <file>_results.csv)",
+	},
+	resendFlag: &cli.BoolFlag{
+		Name: resendFlag,
+		Usage: "Send again the payments whose transaction failed or was dropped in a previous run. Check first that " +
+			"they have not been received, a transaction not found by the full-node may still be confirmed",
+	},
+	minUTXOsFlag: &cli.IntFlag{
+		Name:  minUTXOsFlag,
+		Usage: "The number of UTXOs of a token (and version) above which it is consolidated",
+		Value: 30,
+	},
+	dryRunFlag: &cli.BoolFlag{
+		Name:  dryRunFlag,
+		Usage: "Only show the plan, without creating any transaction",
+	},
+	reportFileFlag: &cli.StringFlag{
+		Name:  reportFileFlag,
+		Usage: "The JSON file to store the report of the consolidation",
+		Value: "consolidation_report.json",
+	},
+	stateFileFlag: &cli.StringFlag{
+		Name:  stateFileFlag,
+		Usage: "The JSON file keeping track of the progress of the conversion, used to resume it after an interruption",
+		Value: "convertall_state.json",
+	},
+	minValueFlag: &cli.Uint64Flag{
+		Name:  minValueFlag,
+		Usage: "Only list the UTXOs whose value is at least this amount",
+	},
+	maxValueFlag: &cli.Uint64Flag{
+		Name:  maxValueFlag,
+		Usage: "Only list the UTXOs whose value is at most this amount (0: no limit)",
+	},
+	sortByFlag: &cli.StringFlag{
+		Name:  sortByFlag,
+		Usage: fmt.Sprintf("The order of the UTXOs, one of %v (a leading \"-\" sorts in descending order)", utxoSortOrders),
+		Value: "index",
+	},
+	histogramFlag: &cli.BoolFlag{
+		Name:  histogramFlag,
+		Usage: "Show the number of UTXOs and their balance by range of values (powers of ten)",
+	},
+	dustThresholdFlag: &cli.Uint64Flag{
+		Name:  dustThresholdFlag,
+		Usage: "The UTXOs whose value is less than this amount are reported as dust",
+		Value: incclient.DefaultPRVFee,
+	},
+	checkSpentFlag: &cli.BoolFlag{
+		Name:  checkSpentFlag,
+		Usage: "Ask the full-node whether the key images of the listed UTXOs have been spent",
+	},
+	coinSelectionFlag: &cli.StringFlag{
+		Name:  coinSelectionFlag,
+		Usage: fmt.Sprintf("The strategy choosing the coins to spend, one of %v (default: chosen by the SDK)", coinSelectionStrategies),
+	},
+	inputsFlag: &cli.Int64SliceFlag{
+		Name:  inputsFlag,
+		Usage: "The indices of the UTXOs v2 to spend, as printed by the `account utxo` command (e.g, --inputs 12 --inputs 34)",
+	},
+	exportFileFlag: &cli.StringFlag{
+		Name:  exportFileFlag,
+		Usage: "A .json or .csv file to export the UTXOs to, instead of printing them",
+	},
+	listenFlag: &cli.StringFlag{
+		Name:  listenFlag,
+		Usage: "The local address (loopback only) on which the daemon serves its JSON-RPC API",
+		Value: defaultDaemonAddress,
+	},
+	accountsFlag: &cli.StringSliceFlag{
+		Name:  accountsFlag,
+		Usage: "The names of the keystore accounts synced by the daemon (e.g, --accounts alice --accounts bob)",
+	},
+	syncIntervalFlag: &cli.DurationFlag{
+		Name:  syncIntervalFlag,
+		Usage: "The interval between two syncs of the registered accounts",
+		Value: time.Minute,
+	},
+	fromFlag: &cli.StringFlag{
+		Name:  fromFlag,
+		Usage: "Only keep the transactions from this date (YYYY-MM-DD, local time)",
+	},
+	toFlag: &cli.StringFlag{
+		Name:  toFlag,
+		Usage: "Only keep the transactions up to this date, included (YYYY-MM-DD, local time)",
+	},
+	resyncFlag: &cli.BoolFlag{
+		Name:  resyncFlag,
+		Usage: "Discard the locally stored history and retrieve it again from scratch",
+	},
+	exportProfileFlag: &cli.StringFlag{
+		Name:  exportProfileFlag,
+		Usage: fmt.Sprintf("The layout of the exported file, one of %v", exportProfiles),
+		Value: defaultExportProfile,
+	},
+	priceFileFlag: &cli.StringFlag{
+		Name: priceFileFlag,
+		Usage: "A CSV file of `Date,Token,Price` lines (date as YYYY-MM-DD, token as a tokenID, a symbol or, if " +
+			"several tokens of the history share the symbol, SYMBOL:NETWORK) giving the daily fiat prices used to value the " +
+			"transactions",
+	},
+	fiatCurrencyFlag: &cli.StringFlag{
+		Name:  fiatCurrencyFlag,
+		Usage: "The fiat currency of the prices of the priceFile",
+		Value: "USD",
+	},
+	queryFlag: &cli.StringFlag{
+		Name:     queryFlag,
+		Usage:    "A part of the symbol or name of the token, or a prefix of its tokenID",
+		Required: true,
+	},
+	verifiedOnlyFlag: &cli.BoolFlag{
+		Name:  verifiedOnlyFlag,
+		Usage: "Only list the verified tokens",
+	},
+	tokenListURLFlag: &cli.StringFlag{
+		Name:  tokenListURLFlag,
+		Usage: fmt.Sprintf("The URL of the token list (default for the mainnet: %v)", mainnetTokenListURL),
+	},
+	tokenListFileFlag: &cli.StringFlag{
+		Name:  tokenListFileFlag,
+		Usage: "A JSON token list file to import instead of downloading it (e.g, a saved coinservice response)",
+	},
 	accessTokenFlag: &cli.StringFlag{
 		Name:  accessTokenFlag,
 		Usage: "A 64-character long hex-encoded authorized access token",
@@ -133,34 +301,43 @@ var defaultFlags = map[string]cli.Flag{
 		Usage:    "An Incognito transaction hash",
 		Required: true,
 	},
+	bundleFileFlag: &cli.StringFlag{
+		Name:     bundleFileFlag,
+		Usage:    "The JSON file of an unsigned transaction bundle",
+		Required: true,
+	},
+	signedTxFileFlag: &cli.StringFlag{
+		Name:  signedTxFileFlag,
+		Usage: "The JSON file of a signed transaction (default for tx sign: <bundle>_signed.json)",
+	},
 
 	tokenIDToSellFlag: &cli.StringFlag{
 		Name:     tokenIDToSellFlag,
 		Aliases:  aliases[tokenIDToSellFlag],
-		Usage:    "ID of the token to sell",
+		Usage:    "ID or symbol of the token to sell",
 		Required: true,
 	},
 	tokenIDToBuyFlag: &cli.StringFlag{
 		Name:     tokenIDToBuyFlag,
 		Aliases:  aliases[tokenIDToBuyFlag],
-		Usage:    "ID of the token to buy",
+		Usage:    "ID or symbol of the token to buy",
 		Required: true,
 	},
-	sellingAmountFlag: &cli.Uint64Flag{
+	sellingAmountFlag: &cli.StringFlag{
 		Name:     sellingAmountFlag,
 		Aliases:  aliases[sellingAmountFlag],
-		Usage:    fmt.Sprintf("The amount of %v wished to sell", tokenIDToSellFlag),
+		Usage:    fmt.Sprintf("The amount of %v wished to sell, %v", tokenIDToSellFlag, tokenAmountFormats),
 		Required: true,
 	},
-	minAcceptableAmountFlag: &cli.Uint64Flag{
+	minAcceptableAmountFlag: &cli.StringFlag{
 		Name:    minAcceptableAmountFlag,
 		Aliases: aliases[minAcceptableAmountFlag],
-		Usage:   fmt.Sprintf("The minimum acceptable amount of %v wished to receive", tokenIDToBuyFlag),
-		Value:   0,
+		Usage:   fmt.Sprintf("The minimum acceptable amount of %v wished to receive, %v", tokenIDToBuyFlag, tokenAmountFormats),
+		Value:   "0",
 	},
-	tradingFeeFlag: &cli.Uint64Flag{
+	tradingFeeFlag: &cli.StringFlag{
 		Name:     tradingFeeFlag,
-		Usage:    "The trading fee",
+		Usage:    "The trading fee, paid in PRV if prvFee is set, in the token to sell otherwise",
 		Required: true,
 	},
 	tokenID1Flag: &cli.StringFlag{
@@ -224,9 +401,33 @@ var defaultFlags = map[string]cli.Flag{
 	mnemonicFlag: &cli.StringFlag{
 		Name:     mnemonicFlag,
 		Aliases:  []string{"m"},
-		Usage:    "A 12-word mnemonic phrase, words are separated by a \"-\", or put in \"\" (Examples: artist-decline-pepper-spend-good-enemy-caught-sister-sure-opinion-hundred-lake, \"artist decline pepper spend good enemy caught sister sure opinion hundred lake\").",
+		Usage:    "A BIP39 mnemonic phrase of 12, 15, 18, 21 or 24 words, words are separated by a \"-\", or put in \"\" (Examples: artist-decline-pepper-spend-good-enemy-caught-sister-sure-opinion-hundred-lake, \"artist decline pepper spend good enemy caught sister sure opinion hundred lake\").",
+		Required: true,
+	},
+	bip39PassphraseFlag: &cli.StringFlag{
+		Name:  bip39PassphraseFlag,
+		Usage: "An optional BIP39 passphrase (a.k.a the 25th word) used together with the mnemonic to derive the accounts",
+	},
+	thresholdFlag: &cli.IntFlag{
+		Name:     thresholdFlag,
+		Usage:    "The number of shares required to reconstruct the mnemonic",
+		Required: true,
+	},
+	numSharesFlag: &cli.IntFlag{
+		Name:     numSharesFlag,
+		Usage:    "The number of shares to create (at most 255)",
+		Required: true,
+	},
+	shareFlag: &cli.StringSliceFlag{
+		Name:     shareFlag,
+		Usage:    "A backup share, repeat the flag for each share (e.g, --share SHARE_1 --share SHARE_2)",
 		Required: true,
 	},
+	numWordsFlag: &cli.IntFlag{
+		Name:  numWordsFlag,
+		Usage: "The number of words of the mnemonic (12, 15, 18, 21 or 24)",
+		Value: 12,
+	},
 	numShardsFlag: &cli.IntFlag{
 		Name:  numShardsFlag,
 		Usage: "The number of shards",
@@ -237,12 +438,53 @@ var defaultFlags = map[string]cli.Flag{
 		Usage: "The number of accounts",
 		Value: 1,
 	},
+	gapLimitFlag: &cli.IntFlag{
+		Name:  gapLimitFlag,
+		Usage: "The number of consecutive unused derivation indexes after which the discovery stops",
+		Value: 20,
+	},
+	indexingTimeoutFlag: &cli.DurationFlag{
+		Name:  indexingTimeoutFlag,
+		Usage: "The maximum time to wait for the full-node to index the OTA key of a derivation index (e.g, 30s, 5m, 1h)",
+		Value: 30 * time.Minute,
+	},
+	prefixFlag: &cli.StringFlag{
+		Name:  prefixFlag,
+		Usage: "A base58 prefix the payment address must start with, right after the leading \"12s\" shared by all payment addresses (its first character must be between \"a\" and \"x\")",
+	},
+	outFileFlag: &cli.StringFlag{
+		Name:  outFileFlag,
+		Usage: "A file to store the result encrypted with a passphrase, instead of printing it",
+	},
+	inFileFlag: &cli.StringFlag{
+		Name:     inFileFlag,
+		Aliases:  []string{"f"},
+		Usage:    "A file written with the outFile flag of the vanity command",
+		Required: true,
+	},
 	shardIDFlag: &cli.IntFlag{
 		Name:  shardIDFlag,
 		Usage: fmt.Sprintf("A specific shardID (-2: same shard as the first account (i.e, `Anon`); -1: any shard)"),
 		Value: -2,
 	},
 
+	waitFlag: &cli.BoolFlag{
+		Name:        waitFlag,
+		Usage:       "Wait for the Incognito transaction created by the command to be confirmed, rejected or dropped",
+		Value:       false,
+		Destination: &waitTx,
+	},
+	waitTimeoutFlag: &cli.DurationFlag{
+		Name:        waitTimeoutFlag,
+		Usage:       "The maximum time to wait for a transaction when the wait flag is set (e.g, 30s, 5m, 1h)",
+		Value:       10 * time.Minute,
+		Destination: &waitTimeout,
+	},
+	evmPrivateKeyFileFlag: &cli.StringFlag{
+		Name:  evmPrivateKeyFileFlag,
+		Usage: fmt.Sprintf("Path to a file containing the EVM private key. If not set, the key is read from the %v environment variable, or prompted", evmPrivateKeyEnv),
+		Value: "",
+	},
 	evmAddressFlag: &cli.StringFlag{
 		Name:  evmAddressFlag,
 		Usage: "A hex-encoded address on ETH/BSC networks",
@@ -316,4 +558,38 @@ var defaultFlags = map[string]cli.Flag{
 		Usage:    "The name of the shielding token",
 		Required: true,
 	},
+
+	accountNameFlag: &cli.StringFlag{
+		Name:     accountNameFlag,
+		Usage:    "The name of the keystore account",
+		Required: true,
+	},
+	newAccountNameFlag: &cli.StringFlag{
+		Name:     newAccountNameFlag,
+		Usage:    "The new name of the keystore account",
+		Required: true,
+	},
+	keyImageFileFlag: &cli.StringFlag{
+		Name:     keyImageFileFlag,
+		Usage:    "The JSON file of the key images exported from a spending account",
+		Required: true,
+	},
+
+	profileNameFlag: &cli.StringFlag{
+		Name:     profileNameFlag,
+		Usage:    "The name of the config profile",
+		Required: true,
+	},
+	configKeyFlag: &cli.StringFlag{
+		Name:  configKeyFlag,
+		Usage: "A config key (e.g, network, host, evm.ETH.host)",
+	},
+	configValueFlag: &cli.StringFlag{
+		Name:  configValueFlag,
+		Usage: "The value of the config key (empty to unset the key)",
+	},
+	revealFlag: &cli.BoolFlag{
+		Name:  revealFlag,
+		Usage: "Print the secrets of the profile (i.e, btc.password) in clear text",
+	},
 }
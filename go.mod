@@ -12,10 +12,13 @@ require (
 	github.com/incognitochain/bridge-eth v0.0.0-20210429050541-edfe3725b21a
 	github.com/incognitochain/go-incognito-sdk-v2 v1.0.1-beta.0.20230510025135-93a6300287ab
 	github.com/niemeyer/pretty v0.0.0-20200227124842-a10e7caefd8e // indirect
+	github.com/syndtr/goleveldb v1.0.1-0.20210305035536-64b5b1c73954
+	github.com/tyler-smith/go-bip39 v1.1.0
 	github.com/urfave/cli/v2 v2.3.0
 	golang.org/x/crypto v0.0.0-20210616213533-5ff15b29337e
 	google.golang.org/protobuf v1.25.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20200902074654-038fdea0a05b // indirect
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 replace github.com/incognitochain/bridge-eth => github.com/levietcuong2602/bridge-eth v0.0.0-20250414034907-5bf8e5ecab79
@@ -0,0 +1,392 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// historyStoreDir is the directory, under the CLI home directory, of the history store of each network.
+	historyStoreDir = "history"
+
+	// historyDateLayout is the layout of the dates of the `from` and `to` flags.
+	historyDateLayout = "2006-01-02"
+)
+
+// Prefixes of the keys of the history store. The keys of an account w.r.t a token are of the form
+// <prefix>/<account>/<tokenID>/..., where the account is the base58-encoded public key.
+const (
+	// syncPointPrefix keys the historySyncPoint of a token.
+	syncPointPrefix = "s"
+	// txInPrefix keys the in-coming transactions, by lock-time and hash.
+	txInPrefix = "i"
+	// txOutPrefix keys the out-going transactions, by lock-time and hash.
+	txOutPrefix = "o"
+	// txHashPrefix indexes the stored transactions by direction and hash.
+	txHashPrefix = "h"
+	// outCoinPrefix marks the output coins (v2) whose transactions have been retrieved, by public key.
+	outCoinPrefix = "c"
+	// keyImagePrefix marks the spent coins whose transactions have been retrieved, by key image.
+	keyImagePrefix = "k"
+	// v1TokensPrefix keys the list of tokens of the transactions v1 of an account (<prefix>/<account>).
+	v1TokensPrefix = "v"
+)
+
+// historySyncPoint records how far the history of an account w.r.t a token has been synced.
+type historySyncPoint struct {
+	LastSync time.Time
+	// V1Synced tells whether the in-coming transactions v1 have been retrieved. They never change afterwards since
+	// transactions v1 can no longer be created.
+	V1Synced bool
+}
+
+// rawTxIn is a TxIn whose metadata is kept encoded until it is parsed by toTxIn.
+type rawTxIn struct {
+	incclient.TxIn
+	Metadata json.RawMessage
+}
+
+// rawTxOut is a TxOut whose metadata is kept encoded until it is parsed by toTxOut.
+type rawTxOut struct {
+	incclient.TxOut
+	Metadata json.RawMessage
+}
+
+// rawTxHistory is the JSON-decoded form of a TxHistory (e.g, returned by the daemon).
+type rawTxHistory struct {
+	TxInList  []rawTxIn
+	TxOutList []rawTxOut
+}
+
+// parseRawMetadata parses the metadata of a transaction, if any.
+func parseRawMetadata(raw json.RawMessage) (metadata.Metadata, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	return metadata.ParseMetadata(raw)
+}
+
+func (txIn rawTxIn) toTxIn() (incclient.TxIn, error) {
+	md, err := parseRawMetadata(txIn.Metadata)
+	if err != nil {
+		return incclient.TxIn{}, fmt.Errorf("tx %v: %v", txIn.TxHash, err)
+	}
+	txIn.TxIn.Metadata = md
+
+	return txIn.TxIn, nil
+}
+
+func (txOut rawTxOut) toTxOut() (incclient.TxOut, error) {
+	md, err := parseRawMetadata(txOut.Metadata)
+	if err != nil {
+		return incclient.TxOut{}, fmt.Errorf("tx %v: %v", txOut.TxHash, err)
+	}
+	txOut.TxOut.Metadata = md
+
+	return txOut.TxOut, nil
+}
+
+// toTxHistory parses the metadata of a rawTxHistory.
+func (h rawTxHistory) toTxHistory() (*incclient.TxHistory, error) {
+	res := &incclient.TxHistory{TxInList: make([]incclient.TxIn, 0), TxOutList: make([]incclient.TxOut, 0)}
+	for _, raw := range h.TxInList {
+		txIn, err := raw.toTxIn()
+		if err != nil {
+			return nil, err
+		}
+		res.TxInList = append(res.TxInList, txIn)
+	}
+	for _, raw := range h.TxOutList {
+		txOut, err := raw.toTxOut()
+		if err != nil {
+			return nil, err
+		}
+		res.TxOutList = append(res.TxOutList, txOut)
+	}
+
+	return res, nil
+}
+
+// historyRange is a range [From, To) of lock-times.
+type historyRange struct {
+	From int64
+	To   int64
+}
+
+// allHistory covers all the transactions.
+var allHistory = historyRange{From: 0, To: math.MaxInt64}
+
+// contains checks if a lock-time is in the range.
+func (r historyRange) contains(lockTime int64) bool {
+	return lockTime >= r.From && lockTime < r.To
+}
+
+// getHistoryRange returns the range of the `from` and `to` flags. Both dates are included, in local time.
+func getHistoryRange(c *cli.Context) (historyRange, error) {
+	res := allHistory
+	if fromStr := c.String(fromFlag); fromStr != "" {
+		from, err := time.ParseInLocation(historyDateLayout, fromStr, time.Local)
+		if err != nil {
+			return res, fmt.Errorf("expected %v as %v, got %q", fromFlag, historyDateLayout, fromStr)
+		}
+		res.From = from.Unix()
+	}
+	if toStr := c.String(toFlag); toStr != "" {
+		to, err := time.ParseInLocation(historyDateLayout, toStr, time.Local)
+		if err != nil {
+			return res, fmt.Errorf("expected %v as %v, got %q", toFlag, historyDateLayout, toStr)
+		}
+		res.To = to.AddDate(0, 0, 1).Unix()
+	}
+	if res.To <= res.From {
+		return res, fmt.Errorf("%v must not be before %v", toFlag, fromFlag)
+	}
+
+	return res, nil
+}
+
+// filterTxHistory returns the transactions of a history within a range.
+func filterTxHistory(h *incclient.TxHistory, r historyRange) *incclient.TxHistory {
+	res := &incclient.TxHistory{TxInList: make([]incclient.TxIn, 0), TxOutList: make([]incclient.TxOut, 0)}
+	for _, txIn := range h.TxInList {
+		if r.contains(txIn.LockTime) {
+			res.TxInList = append(res.TxInList, txIn)
+		}
+	}
+	for _, txOut := range h.TxOutList {
+		if r.contains(txOut.LockTime) {
+			res.TxOutList = append(res.TxOutList, txOut)
+		}
+	}
+
+	return res
+}
+
+// historyKey returns the key of the history store for an account and a token.
+func historyKey(prefix, account, tokenIDStr string, parts ...string) []byte {
+	return []byte(strings.Join(append([]string{prefix, account, tokenIDStr}, parts...), "/"))
+}
+
+// v1TokensKey returns the key of the list of tokens of the transactions v1 of an account.
+func v1TokensKey(account string) []byte {
+	return []byte(v1TokensPrefix + "/" + account)
+}
+
+// lockTimeKey encodes a lock-time so that the keys are sorted by lock-time.
+func lockTimeKey(lockTime int64) string {
+	if lockTime < 0 {
+		lockTime = 0
+	}
+	return fmt.Sprintf("%016x", lockTime)
+}
+
+// historyStore persists the transaction history of the accounts in a LevelDB database, so that only the transactions
+// newer than the last sync have to be retrieved from the full-node.
+type historyStore struct {
+	db *leveldb.DB
+}
+
+// openHistoryStore opens the history store of the current network. Only one command can use it at a time.
+func openHistoryStore() (*historyStore, error) {
+	homeDir, err := cliHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	networkDir := cfg.network
+	if host != "" {
+		networkDir = "custom"
+	}
+
+	db, err := leveldb.OpenFile(filepath.Join(homeDir, historyStoreDir, networkDir), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the history store (is another command using it?): %v", err)
+	}
+
+	return &historyStore{db: db}, nil
+}
+
+// close closes the history store.
+func (s *historyStore) close() error {
+	return s.db.Close()
+}
+
+// has checks if a key is in the history store.
+func (s *historyStore) has(key []byte) (bool, error) {
+	return s.db.Has(key, nil)
+}
+
+// getJSON decodes the value of a key into val. It returns false if the key does not exist.
+func (s *historyStore) getJSON(key []byte, val interface{}) (bool, error) {
+	data, err := s.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(data, val)
+}
+
+// getSyncPoint returns the sync point of an account w.r.t a token (empty if it has never been synced).
+func (s *historyStore) getSyncPoint(account, tokenIDStr string) (historySyncPoint, error) {
+	var res historySyncPoint
+	_, err := s.getJSON(historyKey(syncPointPrefix, account, tokenIDStr), &res)
+
+	return res, err
+}
+
+// syncedTokenIDs returns the tokens of an account whose history is stored.
+func (s *historyStore) syncedTokenIDs(account string) []string {
+	prefix := []byte(strings.Join([]string{syncPointPrefix, account, ""}, "/"))
+	iter := s.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	res := make([]string, 0)
+	for iter.Next() {
+		res = append(res, strings.TrimPrefix(string(iter.Key()), string(prefix)))
+	}
+
+	return res
+}
+
+// getHistory returns the stored history of an account w.r.t a token within a range, the latest transactions first.
+func (s *historyStore) getHistory(account, tokenIDStr string, r historyRange) (*incclient.TxHistory, error) {
+	res := &incclient.TxHistory{TxInList: make([]incclient.TxIn, 0), TxOutList: make([]incclient.TxOut, 0)}
+	rangeOf := func(prefix string) *util.Range {
+		return &util.Range{
+			Start: historyKey(prefix, account, tokenIDStr, lockTimeKey(r.From)),
+			Limit: historyKey(prefix, account, tokenIDStr, lockTimeKey(r.To)),
+		}
+	}
+
+	iter := s.db.NewIterator(rangeOf(txInPrefix), nil)
+	for iter.Next() {
+		var raw rawTxIn
+		if err := json.Unmarshal(iter.Value(), &raw); err != nil {
+			iter.Release()
+			return nil, err
+		}
+		txIn, err := raw.toTxIn()
+		if err != nil {
+			iter.Release()
+			return nil, err
+		}
+		res.TxInList = append(res.TxInList, txIn)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	iter = s.db.NewIterator(rangeOf(txOutPrefix), nil)
+	for iter.Next() {
+		var raw rawTxOut
+		if err := json.Unmarshal(iter.Value(), &raw); err != nil {
+			iter.Release()
+			return nil, err
+		}
+		txOut, err := raw.toTxOut()
+		if err != nil {
+			iter.Release()
+			return nil, err
+		}
+		res.TxOutList = append(res.TxOutList, txOut)
+	}
+	iter.Release()
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(res.TxInList, func(i, j int) bool { return res.TxInList[i].LockTime > res.TxInList[j].LockTime })
+	sort.SliceStable(res.TxOutList, func(i, j int) bool { return res.TxOutList[i].LockTime > res.TxOutList[j].LockTime })
+
+	return res, nil
+}
+
+// deleteHistory removes the stored history of an account w.r.t a token, so that it is synced again from scratch.
+func (s *historyStore) deleteHistory(account, tokenIDStr string) error {
+	batch := new(leveldb.Batch)
+	for _, prefix := range []string{txInPrefix, txOutPrefix, txHashPrefix, outCoinPrefix, keyImagePrefix} {
+		iter := s.db.NewIterator(util.BytesPrefix(historyKey(prefix, account, tokenIDStr, "")), nil)
+		for iter.Next() {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+		iter.Release()
+		if err := iter.Error(); err != nil {
+			return err
+		}
+	}
+	batch.Delete(historyKey(syncPointPrefix, account, tokenIDStr))
+
+	return s.db.Write(batch, nil)
+}
+
+// deleteV1Tokens removes the stored list of tokens of the transactions v1 of an account.
+func (s *historyStore) deleteV1Tokens(account string) error {
+	return s.db.Delete(v1TokensKey(account), nil)
+}
+
+// historyBatch groups the writes of a sync step, so that an interrupted sync resumes from the last completed step.
+type historyBatch struct {
+	batch      *leveldb.Batch
+	account    string
+	tokenIDStr string
+}
+
+func (s *historyStore) newBatch(account, tokenIDStr string) *historyBatch {
+	return &historyBatch{batch: new(leveldb.Batch), account: account, tokenIDStr: tokenIDStr}
+}
+
+// write commits a batch to the store.
+func (s *historyStore) write(b *historyBatch) error {
+	return s.db.Write(b.batch, nil)
+}
+
+func (b *historyBatch) putJSON(key []byte, val interface{}) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	b.batch.Put(key, data)
+
+	return nil
+}
+
+func (b *historyBatch) putTxIn(txIn incclient.TxIn) error {
+	key := historyKey(txInPrefix, b.account, b.tokenIDStr, lockTimeKey(txIn.LockTime), txIn.TxHash)
+	b.batch.Put(historyKey(txHashPrefix, b.account, b.tokenIDStr, txInPrefix, txIn.TxHash), key)
+
+	return b.putJSON(key, txIn)
+}
+
+func (b *historyBatch) putTxOut(txOut incclient.TxOut) error {
+	key := historyKey(txOutPrefix, b.account, b.tokenIDStr, lockTimeKey(txOut.LockTime), txOut.TxHash)
+	b.batch.Put(historyKey(txHashPrefix, b.account, b.tokenIDStr, txOutPrefix, txOut.TxHash), key)
+
+	return b.putJSON(key, txOut)
+}
+
+func (b *historyBatch) markOutCoin(publicKey string) {
+	b.batch.Put(historyKey(outCoinPrefix, b.account, b.tokenIDStr, publicKey), nil)
+}
+
+func (b *historyBatch) markKeyImage(keyImage string) {
+	b.batch.Put(historyKey(keyImagePrefix, b.account, b.tokenIDStr, keyImage), nil)
+}
+
+func (b *historyBatch) putSyncPoint(sp historySyncPoint) error {
+	return b.putJSON(historyKey(syncPointPrefix, b.account, b.tokenIDStr), sp)
+}
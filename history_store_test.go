@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+func TestHistoryStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &historyStore{db: db}
+	defer func() {
+		if err := s.close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	md := &metadata.InitTokenRequest{Amount: 100, TokenName: "token"}
+	md.Type = metadata.InitTokenRequestMeta
+	batch := s.newBatch("alice", common.PRVIDStr)
+	for i, lockTime := range []int64{100, 300, 200} {
+		txIn := incclient.TxIn{LockTime: lockTime, TxHash: string(rune('a' + i)), Amount: uint64(lockTime)}
+		if err = batch.putTxIn(txIn); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = batch.putTxOut(incclient.TxOut{LockTime: 250, TxHash: "out", Amount: 5, Metadata: md}); err != nil {
+		t.Fatal(err)
+	}
+	batch.markKeyImage("keyImage")
+	if err = batch.putSyncPoint(historySyncPoint{V1Synced: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.write(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := s.getHistory("alice", common.PRVIDStr, allHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.TxInList) != 3 || h.TxInList[0].LockTime != 300 || h.TxInList[2].LockTime != 100 {
+		t.Fatalf("unexpected TxIns %+v", h.TxInList)
+	}
+	parsed, ok := h.TxOutList[0].Metadata.(*metadata.InitTokenRequest)
+	if len(h.TxOutList) != 1 || !ok || parsed.TokenName != "token" {
+		t.Fatalf("unexpected TxOuts %+v", h.TxOutList)
+	}
+
+	h, err = s.getHistory("alice", common.PRVIDStr, historyRange{From: 150, To: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.TxInList) != 1 || h.TxInList[0].LockTime != 200 || len(h.TxOutList) != 1 {
+		t.Fatalf("unexpected history within [150, 300): %+v", h)
+	}
+
+	h, err = s.getHistory("bob", common.PRVIDStr, allHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.TxInList) != 0 || len(h.TxOutList) != 0 {
+		t.Fatalf("expect no history for another account, got %+v", h)
+	}
+
+	if tokenIDs := s.syncedTokenIDs("alice"); len(tokenIDs) != 1 || tokenIDs[0] != common.PRVIDStr {
+		t.Fatalf("unexpected synced tokens %v", tokenIDs)
+	}
+	sp, err := s.getSyncPoint("alice", common.PRVIDStr)
+	if err != nil || !sp.V1Synced {
+		t.Fatalf("unexpected sync point %+v (%v)", sp, err)
+	}
+
+	if err = s.deleteHistory("alice", common.PRVIDStr); err != nil {
+		t.Fatal(err)
+	}
+	h, err = s.getHistory("alice", common.PRVIDStr, allHistory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.TxInList) != 0 || len(h.TxOutList) != 0 || len(s.syncedTokenIDs("alice")) != 0 {
+		t.Fatalf("expect the history to be deleted, got %+v", h)
+	}
+	if ok, err := s.has(historyKey(keyImagePrefix, "alice", common.PRVIDStr, "keyImage")); err != nil || ok {
+		t.Fatalf("expect the key image to be deleted (%v)", err)
+	}
+}
+
+func TestFilterTxHistory(t *testing.T) {
+	h := &incclient.TxHistory{
+		TxInList:  []incclient.TxIn{{LockTime: 10}, {LockTime: 20}},
+		TxOutList: []incclient.TxOut{{LockTime: 19}, {LockTime: 30}},
+	}
+
+	res := filterTxHistory(h, historyRange{From: 10, To: 20})
+	if len(res.TxInList) != 1 || res.TxInList[0].LockTime != 10 || len(res.TxOutList) != 1 || res.TxOutList[0].LockTime != 19 {
+		t.Fatalf("unexpected filtered history %+v", res)
+	}
+
+	res = filterTxHistory(h, allHistory)
+	if len(res.TxInList) != 2 || len(res.TxOutList) != 2 {
+		t.Fatalf("unexpected filtered history %+v", res)
+	}
+}
@@ -0,0 +1,691 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_generic"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// historyBatchSize is the number of coins whose transactions are retrieved (and stored) at once.
+const historyBatchSize = 100
+
+// historySyncer syncs the transaction history of an account into the history store. The in-coming transactions are
+// found from the output coins not processed yet, and the out-going transactions from the spent coins not processed
+// yet, so that only the transactions newer than the last sync are retrieved.
+//
+// The transactions are parsed as the TxHistoryProcessor of the SDK does.
+type historySyncer struct {
+	store      *historyStore
+	privateKey string
+	keySet     *key.KeySet
+	account    string
+	shardID    byte
+	numThreads int
+}
+
+// newHistorySyncer creates a historySyncer for a private key.
+func newHistorySyncer(store *historyStore, privateKey string, numThreads int) (*historySyncer, error) {
+	w, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	pk := w.KeySet.PaymentAddress.Pk
+
+	return &historySyncer{
+		store:      store,
+		privateKey: privateKey,
+		keySet:     &w.KeySet,
+		account:    base58.Base58Check{}.Encode(pk, 0),
+		shardID:    common.GetShardIDFromLastByte(pk[len(pk)-1]),
+		numThreads: numThreads,
+	}, nil
+}
+
+// forEachBatch calls f on the batches of a list, numThreads batches at a time. It returns the first error.
+func forEachBatch(list []string, numThreads int, f func([]string) error) error {
+	var mtx sync.Mutex
+	var firstErr error
+	jobs := make(chan []string)
+	var wg sync.WaitGroup
+	for i := 0; i < numThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				if err := f(batch); err != nil {
+					mtx.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					mtx.Unlock()
+				}
+			}
+		}()
+	}
+	for start := 0; start < len(list); start += historyBatchSize {
+		end := start + historyBatchSize
+		if end > len(list) {
+			end = len(list)
+		}
+		jobs <- list[start:end]
+	}
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
+
+// newKeys returns the elements of a list whose key (see historyKey) is not in the store yet.
+func (hs *historySyncer) newKeys(prefix, tokenIDStr string, list []string) ([]string, error) {
+	res := make([]string, 0)
+	for _, s := range list {
+		ok, err := hs.store.has(historyKey(prefix, hs.account, tokenIDStr, s))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			res = append(res, s)
+		}
+	}
+
+	return res, nil
+}
+
+// syncToken retrieves the transactions of a token newer than the last sync, and stores them.
+func (hs *historySyncer) syncToken(tokenIDStr string) error {
+	sp, err := hs.store.getSyncPoint(hs.account, tokenIDStr)
+	if err != nil {
+		return err
+	}
+
+	if !sp.V1Synced {
+		txsIn, err := incclient.NewTxHistoryProcessor(cfg.incClient, hs.numThreads).GetTxsIn(hs.privateKey, tokenIDStr, 1)
+		if err != nil {
+			return fmt.Errorf("cannot get the in-coming txs v1: %v", err)
+		}
+		batch := hs.store.newBatch(hs.account, tokenIDStr)
+		for _, txIn := range txsIn {
+			if err = batch.putTxIn(txIn); err != nil {
+				return err
+			}
+		}
+		sp.V1Synced = true
+		if err = batch.putSyncPoint(sp); err != nil {
+			return err
+		}
+		if err = hs.store.write(batch); err != nil {
+			return err
+		}
+	}
+
+	outCoins, err := cfg.incClient.GetListDecryptedOutCoin(hs.privateKey, tokenIDStr, 0)
+	if err != nil {
+		return fmt.Errorf("cannot get the output coins: %v", err)
+	}
+	coinsByCommitment := make(map[string]coin.PlainCoin)
+	publicKeys := make([]string, 0)
+	for _, outCoin := range outCoins {
+		coinsByCommitment[base58.Base58Check{}.Encode(outCoin.GetCommitment().ToBytesS(), common.ZeroByte)] = outCoin
+		if outCoin.GetVersion() == 2 {
+			publicKeys = append(publicKeys, base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), 0))
+		}
+	}
+	publicKeys, err = hs.newKeys(outCoinPrefix, tokenIDStr, publicKeys)
+	if err != nil {
+		return err
+	}
+	if len(publicKeys) != 0 {
+		log.Printf("Token %v: retrieving the txs of %v new output coins\n", getTokenName(tokenIDStr), len(publicKeys))
+	}
+	err = forEachBatch(publicKeys, hs.numThreads, func(batch []string) error {
+		return hs.syncTxsIn(tokenIDStr, batch, outCoins, coinsByCommitment)
+	})
+	if err != nil {
+		return err
+	}
+
+	spentCoins, _, err := cfg.incClient.GetSpentOutputCoins(hs.privateKey, tokenIDStr, 0)
+	if err != nil {
+		return fmt.Errorf("cannot get the spent coins: %v", err)
+	}
+	spentCoinsByKeyImage := make(map[string]coin.PlainCoin)
+	keyImages := make([]string, 0)
+	for _, spentCoin := range spentCoins {
+		keyImage := base58.Base58Check{}.Encode(spentCoin.GetKeyImage().ToBytesS(), common.ZeroByte)
+		spentCoinsByKeyImage[keyImage] = spentCoin
+		keyImages = append(keyImages, keyImage)
+	}
+	keyImages, err = hs.newKeys(keyImagePrefix, tokenIDStr, keyImages)
+	if err != nil {
+		return err
+	}
+	if len(keyImages) != 0 {
+		log.Printf("Token %v: retrieving the txs of %v new spent coins\n", getTokenName(tokenIDStr), len(keyImages))
+	}
+	err = forEachBatch(keyImages, hs.numThreads, func(batch []string) error {
+		return hs.syncTxsOut(tokenIDStr, batch, spentCoinsByKeyImage)
+	})
+	if err != nil {
+		return err
+	}
+
+	sp.LastSync = time.Now()
+	batch := hs.store.newBatch(hs.account, tokenIDStr)
+	if err = batch.putSyncPoint(sp); err != nil {
+		return err
+	}
+
+	return hs.store.write(batch)
+}
+
+// syncTxsIn retrieves and stores the in-coming transactions of a batch of output coins (v2), given by their public
+// keys.
+func (hs *historySyncer) syncTxsIn(tokenIDStr string, publicKeys []string, outCoins map[string]coin.PlainCoin,
+	coinsByCommitment map[string]coin.PlainCoin) error {
+	txMap, err := cfg.incClient.GetTransactionsByPublicKeys(publicKeys)
+	if err != nil {
+		return fmt.Errorf("cannot get the txs of the output coins: %v", err)
+	}
+
+	batch := hs.store.newBatch(hs.account, tokenIDStr)
+	added := make(map[string]bool)
+	for _, txs := range txMap {
+		for txHash, tx := range txs {
+			if added[txHash] {
+				continue
+			}
+			added[txHash] = true
+			txIn, err := hs.parseTxIn(txHash, tx, tokenIDStr, outCoins, coinsByCommitment)
+			if err != nil {
+				return fmt.Errorf("tx %v: %v", txHash, err)
+			}
+			if txIn != nil {
+				if err = batch.putTxIn(*txIn); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, publicKey := range publicKeys {
+		batch.markOutCoin(publicKey)
+	}
+
+	return hs.store.write(batch)
+}
+
+// syncTxsOut retrieves and stores the out-going transactions of a batch of spent coins, given by their key images.
+func (hs *historySyncer) syncTxsOut(tokenIDStr string, keyImages []string, spentCoins map[string]coin.PlainCoin) error {
+	spentTxs, err := cfg.incClient.GetTxHashBySerialNumbers(keyImages, tokenIDStr, hs.shardID)
+	if err != nil {
+		if strings.Contains(err.Error(), "Method not found") {
+			return fmt.Errorf("method not supported by the remote node configurations")
+		}
+		return fmt.Errorf("cannot get the txs of the spent coins: %v", err)
+	}
+	txHashes := make([]string, 0)
+	added := make(map[string]bool)
+	for _, txHash := range spentTxs {
+		if !added[txHash] {
+			added[txHash] = true
+			txHashes = append(txHashes, txHash)
+		}
+	}
+	txs := make(map[string]metadata.Transaction)
+	if len(txHashes) != 0 {
+		txs, err = cfg.incClient.GetTxs(txHashes)
+		if err != nil {
+			return fmt.Errorf("cannot get the txs of the spent coins: %v", err)
+		}
+	}
+
+	batch := hs.store.newBatch(hs.account, tokenIDStr)
+	for _, txHash := range txHashes {
+		tx, ok := txs[txHash]
+		if !ok {
+			return fmt.Errorf("tx %v not found", txHash)
+		}
+		txOut, err := hs.parseTxOut(txHash, tx, tokenIDStr, spentCoins)
+		if err != nil {
+			return fmt.Errorf("tx %v: %v", txHash, err)
+		}
+		if txOut != nil {
+			if err = batch.putTxOut(*txOut); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyImage := range keyImages {
+		batch.markKeyImage(keyImage)
+	}
+
+	return hs.store.write(batch)
+}
+
+// parseTxIn returns the TxIn of a transaction w.r.t a token, or nil if the transaction spends coins of the account or
+// sends it nothing.
+func (hs *historySyncer) parseTxIn(txHash string, tx metadata.Transaction, tokenIDStr string,
+	outCoins map[string]coin.PlainCoin, coinsByCommitment map[string]coin.PlainCoin) (*incclient.TxIn, error) {
+	keyImages, err := getTxKeyImages(tx, tokenIDStr)
+	if err != nil {
+		return nil, err
+	}
+	for _, keyImage := range keyImages {
+		if _, ok := outCoins[keyImage]; ok {
+			return nil, nil
+		}
+	}
+
+	ownedCoins, err := getTxOwnedOutputCoins(tx, tokenIDStr, hs.keySet)
+	if err != nil {
+		return nil, err
+	}
+	pubKeys := make(map[string]uint64)
+	amount := uint64(0)
+	for cmtStr := range ownedCoins {
+		if outCoin, ok := coinsByCommitment[cmtStr]; ok {
+			amount += outCoin.GetValue()
+			pubKeys[base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), 0)] = outCoin.GetValue()
+		}
+	}
+	if amount == 0 {
+		return nil, nil
+	}
+
+	note := txMetadataNote[tx.GetMetadataType()]
+	if tx.GetType() == common.TxConversionType || tx.GetType() == common.TxTokenConversionType {
+		note = "Conversion"
+	}
+
+	return &incclient.TxIn{
+		Version:  tx.GetVersion(),
+		LockTime: tx.GetLockTime(),
+		OutCoins: pubKeys,
+		TxHash:   txHash,
+		TokenID:  tokenIDStr,
+		Amount:   amount,
+		Metadata: tx.GetMetadata(),
+		Note:     note,
+	}, nil
+}
+
+// parseTxOut returns the TxOut of a transaction spending coins of the account w.r.t a token, or nil if it sends
+// nothing (except for PRV, where the fee is reported).
+func (hs *historySyncer) parseTxOut(txHash string, tx metadata.Transaction, tokenIDStr string,
+	spentCoins map[string]coin.PlainCoin) (*incclient.TxOut, error) {
+	fee, isPRVFee := tx.GetTxFee(), true
+	if fee == 0 {
+		fee, isPRVFee = tx.GetTxFeeToken(), false
+	}
+
+	keyImages, err := getTxKeyImages(tx, tokenIDStr)
+	if err != nil {
+		return nil, err
+	}
+	inputAmount := uint64(0)
+	inputs := make(map[string]uint64)
+	for _, keyImage := range keyImages {
+		if spentCoin, ok := spentCoins[keyImage]; ok {
+			inputAmount += spentCoin.GetValue()
+			inputs[keyImage] = spentCoin.GetValue()
+		}
+	}
+
+	ownedCoins, err := getTxOwnedOutputCoins(tx, tokenIDStr, hs.keySet)
+	if err != nil {
+		return nil, err
+	}
+	outputAmount := uint64(0)
+	for _, outCoin := range ownedCoins {
+		decryptedCoin, err := outCoin.Decrypt(hs.keySet)
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt an output coin: %v", err)
+		}
+		outputAmount += decryptedCoin.GetValue()
+	}
+
+	amount := inputAmount - outputAmount
+	if isPRVFee == (tokenIDStr == common.PRVIDStr) {
+		amount -= fee
+	}
+	if amount == 0 && tokenIDStr != common.PRVIDStr {
+		return nil, nil
+	}
+
+	proof, err := getTxProof(tx, tokenIDStr, false)
+	if err != nil {
+		return nil, err
+	}
+	receivers := make([]string, 0)
+	if proof != nil {
+		for _, outCoin := range proof.GetOutputCoins() {
+			receivers = append(receivers, base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), common.ZeroByte))
+		}
+	}
+
+	note := txMetadataNote[tx.GetMetadataType()]
+	if tokenIDStr == common.PRVIDStr && amount == 0 {
+		note += " (Tx Fee)"
+	}
+	res := &incclient.TxOut{
+		Version:    tx.GetVersion(),
+		LockTime:   tx.GetLockTime(),
+		TxHash:     txHash,
+		TokenID:    tokenIDStr,
+		SpentCoins: inputs,
+		Receivers:  receivers,
+		Amount:     amount,
+		Metadata:   tx.GetMetadata(),
+		PRVFee:     fee,
+		Note:       strings.TrimSpace(note),
+	}
+	if !isPRVFee {
+		res.PRVFee, res.TokenFee = 0, fee
+	}
+
+	return res, nil
+}
+
+// getTxProof returns the proof of a transaction w.r.t a token, or nil if the transaction does not transfer the token.
+// For the output coins of a token transaction v2, whose tokenID is hidden, anyTokenV2 returns the token proof anyway.
+func getTxProof(tx metadata.Transaction, tokenIDStr string, anyTokenV2 bool) (privacy.Proof, error) {
+	switch tx.GetType() {
+	case common.TxNormalType, common.TxRewardType, common.TxReturnStakingType, common.TxConversionType:
+		if tokenIDStr != common.PRVIDStr {
+			return nil, nil
+		}
+		return tx.GetProof(), nil
+
+	case common.TxCustomTokenPrivacyType, common.TxTokenConversionType:
+		tokenTx, ok := tx.(tx_generic.TransactionToken)
+		if !ok {
+			return nil, fmt.Errorf("cannot parse the transaction as a transaction token")
+		}
+		if tokenIDStr == common.PRVIDStr {
+			return tokenTx.GetTxBase().GetProof(), nil
+		}
+		if tokenIDStr == tokenTx.GetTokenID().String() || (anyTokenV2 && tokenTx.GetVersion() == 2) {
+			return tokenTx.GetTxNormal().GetProof(), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getTxKeyImages returns the base58-encoded key images of the input coins of a transaction w.r.t a token.
+func getTxKeyImages(tx metadata.Transaction, tokenIDStr string) ([]string, error) {
+	proof, err := getTxProof(tx, tokenIDStr, false)
+	if err != nil || proof == nil {
+		return nil, err
+	}
+
+	res := make([]string, 0)
+	for _, inCoin := range proof.GetInputCoins() {
+		res = append(res, base58.Base58Check{}.Encode(inCoin.GetKeyImage().ToBytesS(), common.ZeroByte))
+	}
+
+	return res, nil
+}
+
+// getTxOwnedOutputCoins returns the output coins of a transaction w.r.t a token belonging to a key-set, by their
+// base58-encoded commitments.
+func getTxOwnedOutputCoins(tx metadata.Transaction, tokenIDStr string, keySet *key.KeySet) (map[string]coin.Coin, error) {
+	proof, err := getTxProof(tx, tokenIDStr, true)
+	if err != nil || proof == nil {
+		return nil, err
+	}
+
+	res := make(map[string]coin.Coin)
+	for _, outCoin := range proof.GetOutputCoins() {
+		if isOwned, _ := outCoin.DoesCoinBelongToKeySet(keySet); isOwned {
+			res[base58.Base58Check{}.Encode(outCoin.GetCommitment().ToBytesS(), common.ZeroByte)] = outCoin
+		}
+	}
+
+	return res, nil
+}
+
+// getV1TxTokenIDs returns the tokens of the transactions v1 involving an account (its base58-encoded public key). The
+// transactions are retrieved numThreads batches at a time.
+func getV1TxTokenIDs(account string, numThreads int) ([]string, error) {
+	txHashes, err := cfg.incClient.GetTxHashByPublicKeys([]string{account})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the txs v1: %v", err)
+	}
+	var mtx sync.Mutex
+	v1TokenIDs := make(map[string]bool)
+	err = forEachBatch(txHashes[account], numThreads, func(batch []string) error {
+		txs, err := cfg.incClient.GetTxs(batch)
+		if err != nil {
+			return err
+		}
+		mtx.Lock()
+		defer mtx.Unlock()
+		for _, tx := range txs {
+			if tx.GetVersion() == 1 {
+				v1TokenIDs[tx.GetTokenID().String()] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the txs v1: %v", err)
+	}
+
+	res := make([]string, 0)
+	for tokenID := range v1TokenIDs {
+		res = append(res, tokenID)
+	}
+
+	return res, nil
+}
+
+// discoverHistoryTokens returns the tokens for which an account has received coins: PRV, the tokens of its
+// transactions v1 (retrieved once and stored), those of its output coins v2, and those already in the store.
+func (hs *historySyncer) discoverHistoryTokens() ([]string, error) {
+	tokenIDs := map[string]bool{common.PRVIDStr: true}
+
+	v1Tokens := make([]string, 0)
+	found, err := hs.store.getJSON(v1TokensKey(hs.account), &v1Tokens)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		v1Tokens, err = getV1TxTokenIDs(hs.account, 1)
+		if err != nil {
+			return nil, err
+		}
+		batch := hs.store.newBatch(hs.account, "")
+		if err = batch.putJSON(v1TokensKey(hs.account), v1Tokens); err != nil {
+			return nil, err
+		}
+		if err = hs.store.write(batch); err != nil {
+			return nil, err
+		}
+	}
+	for _, tokenID := range v1Tokens {
+		tokenIDs[tokenID] = true
+	}
+
+	tokenCoins, err := cfg.incClient.GetListDecryptedOutCoin(hs.privateKey, common.ConfidentialAssetID.String(), 0)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get the output coins of the tokens: %v", err)
+	}
+	if len(tokenCoins) != 0 {
+		rawAssetTags, err := cfg.incClient.GetAllAssetTags()
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the asset tags: %v", err)
+		}
+		for _, tokenCoin := range tokenCoins {
+			v2Coin, ok := tokenCoin.(*coin.CoinV2)
+			if !ok || tokenCoin.GetValue() == 0 {
+				continue
+			}
+			tokenID, err := v2Coin.GetTokenId(hs.keySet, rawAssetTags)
+			if err != nil || tokenID == nil {
+				continue
+			}
+			tokenIDs[tokenID.String()] = true
+		}
+	}
+
+	for _, tokenID := range hs.store.syncedTokenIDs(hs.account) {
+		tokenIDs[tokenID] = true
+	}
+	delete(tokenIDs, common.ConfidentialAssetID.String())
+
+	res := make([]string, 0)
+	for tokenID := range tokenIDs {
+		res = append(res, tokenID)
+	}
+
+	return res, nil
+}
+
+// getSyncedHistories syncs the stored history of a private key w.r.t a list of tokens (all the tokens of the account
+// if empty), then returns the histories within a range, by tokenID. With resync, the stored history is discarded first.
+func getSyncedHistories(privateKey string, tokenIDs []string, numThreads int, r historyRange,
+	resync bool) (map[string]*incclient.TxHistory, error) {
+	store, err := openHistoryStore()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := store.close()
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+
+	hs, err := newHistorySyncer(store, privateKey, numThreads)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokenIDs) == 0 {
+		if resync {
+			if err = store.deleteV1Tokens(hs.account); err != nil {
+				return nil, err
+			}
+		}
+		tokenIDs, err = hs.discoverHistoryTokens()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	res := make(map[string]*incclient.TxHistory)
+	for _, tokenIDStr := range tokenIDs {
+		if resync {
+			if err = store.deleteHistory(hs.account, tokenIDStr); err != nil {
+				return nil, err
+			}
+		}
+		if err = hs.syncToken(tokenIDStr); err != nil {
+			return nil, fmt.Errorf("token %v: %v", tokenIDStr, err)
+		}
+		res[tokenIDStr], err = store.getHistory(hs.account, tokenIDStr, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// txMetadataNote describes the transactions by their metadata type, as in the history of the SDK.
+var txMetadataNote = map[int]string{
+	metadata.InvalidMeta: "",
+
+	metadata.InitTokenRequestMeta:  "Init Token Request",
+	metadata.InitTokenResponseMeta: "Init Token Response",
+
+	metadata.ShardStakingMeta:           "[Committee] Staking",
+	metadata.BeaconStakingMeta:          "[Committee] Staking",
+	metadata.UnStakingMeta:              "[Committee] Un-staking",
+	metadata.StopAutoStakingMeta:        "[Committee] Stop-staking",
+	metadata.WithDrawRewardRequestMeta:  "[Committee] Withdraw Reward Request",
+	metadata.WithDrawRewardResponseMeta: "[Committee] Withdraw Reward Response",
+
+	metadata.IssuingRequestMeta:                   "[Bridge] Shield Request",
+	metadata.IssuingResponseMeta:                  "[Bridge] Shield Response",
+	metadata.IssuingETHRequestMeta:                "[Bridge] Shield Request",
+	metadata.IssuingETHResponseMeta:               "[Bridge] Shield Response",
+	metadata.IssuingBSCRequestMeta:                "[Bridge] Shield Request",
+	metadata.IssuingBSCResponseMeta:               "[Bridge] Shield Response",
+	metadata.IssuingPLGRequestMeta:                "[Bridge] Shield Request",
+	metadata.IssuingPLGResponseMeta:               "[Bridge] Shield Response",
+	metadata.IssuingPRVERC20RequestMeta:           "[Bridge] Shield Request",
+	metadata.IssuingPRVERC20ResponseMeta:          "[Bridge] Shield Response",
+	metadata.IssuingPRVBEP20RequestMeta:           "[Bridge] Shield Request",
+	metadata.IssuingPRVBEP20ResponseMeta:          "[Bridge] Shield Response",
+	metadata.BurningRequestMeta:                   "[Bridge] Unshield Request",
+	metadata.BurningRequestMetaV2:                 "[Bridge] Unshield Request",
+	metadata.ContractingRequestMeta:               "[Bridge] Unshield Request",
+	metadata.BurningPBSCRequestMeta:               "[Bridge] Unshield Request",
+	metadata.BurningPLGRequestMeta:                "[Bridge] Unshield Request",
+	metadata.BurningForDepositToSCRequestMeta:     "[Bridge] Unshield Request",
+	metadata.BurningForDepositToSCRequestMetaV2:   "[Bridge] Unshield Request",
+	metadata.BurningPBSCForDepositToSCRequestMeta: "[Bridge] Unshield Request",
+	metadata.BurningPLGForDepositToSCRequestMeta:  "[Bridge] Unshield Request",
+
+	metadata.PortalV4ShieldingRequestMeta:      "[Portal] Shield Request",
+	metadata.PortalV4ShieldingResponseMeta:     "[Portal] Shield Response",
+	metadata.PortalV4UnshieldingRequestMeta:    "[Portal] Unshield Request",
+	metadata.PortalV4UnshieldingResponseMeta:   "[Portal] Unshield Response",
+	metadata.PortalV4ConvertVaultRequestMeta:   "[Portal] Convert Vault",
+	metadata.PortalV4SubmitConfirmedTxMeta:     "[Portal] Submit Confirmed Tx",
+	metadata.PortalV4UnshieldBatchingMeta:      "[Portal] Batch Unshield",
+	metadata.PortalV4FeeReplacementRequestMeta: "[Portal] Fee Replacement Request",
+
+	metadata.PDETradeRequestMeta:                   "[pDEX v2] Trade Request",
+	metadata.PDETradeResponseMeta:                  "[pDEX v2] Trade Response",
+	metadata.PDECrossPoolTradeRequestMeta:          "[pDEX v2] Trade Request",
+	metadata.PDECrossPoolTradeResponseMeta:         "[pDEX v2] Trade Response",
+	metadata.PDEContributionMeta:                   "[pDEX v2] Contribution Request",
+	metadata.PDEPRVRequiredContributionRequestMeta: "[pDEX v2] Contribution Request",
+	metadata.PDEContributionResponseMeta:           "[pDEX v2] Contribution Response",
+	metadata.PDEWithdrawalRequestMeta:              "[pDEX v2] Withdrawal Request",
+	metadata.PDEWithdrawalResponseMeta:             "[pDEX v2] Withdrawal Response",
+	metadata.PDEFeeWithdrawalRequestMeta:           "[pDEX v2] Fee Withdrawal Request",
+	metadata.PDEFeeWithdrawalResponseMeta:          "[pDEX v2] Fee Withdrawal Response",
+
+	metadataCommon.Pdexv3ModifyParamsMeta:                  "[pDEX v3] Modify Params",
+	metadataCommon.Pdexv3AddLiquidityRequestMeta:           "[pDEX v3] Contribution Request",
+	metadataCommon.Pdexv3AddLiquidityResponseMeta:          "[pDEX v3] Contribution Response",
+	metadataCommon.Pdexv3WithdrawLiquidityRequestMeta:      "[pDEX v3] Withdrawal Request",
+	metadataCommon.Pdexv3WithdrawLiquidityResponseMeta:     "[pDEX v3] Withdrawal Response",
+	metadataCommon.Pdexv3TradeRequestMeta:                  "[pDEX v3] Trade Request",
+	metadataCommon.Pdexv3TradeResponseMeta:                 "[pDEX v3] Trade Response",
+	metadataCommon.Pdexv3AddOrderRequestMeta:               "[pDEX v3] Add Order Request",
+	metadataCommon.Pdexv3AddOrderResponseMeta:              "[pDEX v3] Add Order Response",
+	metadataCommon.Pdexv3WithdrawOrderRequestMeta:          "[pDEX v3] Remove Order Request",
+	metadataCommon.Pdexv3WithdrawOrderResponseMeta:         "[pDEX v3] Remove Order Response",
+	metadataCommon.Pdexv3UserMintNftRequestMeta:            "[pDEX v3] Mint NFT Request",
+	metadataCommon.Pdexv3UserMintNftResponseMeta:           "[pDEX v3] Mint NFT Response",
+	metadataCommon.Pdexv3MintNftRequestMeta:                "[pDEX v3] Mint NFT Request",
+	metadataCommon.Pdexv3MintNftResponseMeta:               "[pDEX v3] Mint NFT Response",
+	metadataCommon.Pdexv3StakingRequestMeta:                "[pDEX v3] Staking Request",
+	metadataCommon.Pdexv3StakingResponseMeta:               "[pDEX v3] Staking Response",
+	metadataCommon.Pdexv3UnstakingRequestMeta:              "[pDEX v3] Staking Request",
+	metadataCommon.Pdexv3UnstakingResponseMeta:             "[pDEX v3] Staking Response",
+	metadataCommon.Pdexv3WithdrawLPFeeRequestMeta:          "[pDEX v3] Withdraw LP Fee Request",
+	metadataCommon.Pdexv3WithdrawLPFeeResponseMeta:         "[pDEX v3] Withdraw LP Fee Response",
+	metadataCommon.Pdexv3WithdrawProtocolFeeRequestMeta:    "[pDEX v3] Withdraw Protocol Fee Request",
+	metadataCommon.Pdexv3WithdrawProtocolFeeResponseMeta:   "[pDEX v3] Withdraw Protocol Fee Response",
+	metadataCommon.Pdexv3MintBlockRewardMeta:               "[pDEX v3] Block Reward",
+	metadataCommon.Pdexv3DistributeStakingRewardMeta:       "[pDEX v3] Distribute Staking Reward",
+	metadataCommon.Pdexv3WithdrawStakingRewardRequestMeta:  "[pDEX v3] Withdraw Staking Reward Request",
+	metadataCommon.Pdexv3WithdrawStakingRewardResponseMeta: "[pDEX v3] Withdraw Staking Reward Response",
+}
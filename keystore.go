@@ -0,0 +1,538 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	cliHomeDirName   = ".incognito-cli"
+	keyStoreFileName = "keystore.json"
+	keyStoreVersion  = 1
+
+	defaultScryptN      = 1 << 18
+	defaultScryptR      = 8
+	defaultScryptP      = 1
+	defaultScryptKeyLen = 32
+	scryptSaltLen       = 32
+)
+
+// scryptParams holds the parameters of the scrypt key-derivation function.
+type scryptParams struct {
+	N      int    `json:"N"`
+	R      int    `json:"R"`
+	P      int    `json:"P"`
+	KeyLen int    `json:"KeyLen"`
+	Salt   string `json:"Salt"`
+}
+
+// encryptedData represents a piece of data encrypted with a passphrase using scrypt + AES-GCM.
+type encryptedData struct {
+	KDF        string       `json:"KDF"`
+	KDFParams  scryptParams `json:"KDFParams"`
+	Cipher     string       `json:"Cipher"`
+	Nonce      string       `json:"Nonce"`
+	CipherText string       `json:"CipherText"`
+}
+
+// keyStoreAccount represents an account stored in the local keystore. A watch-only account holds the OTA key and the
+// read-only key of an account instead of its encrypted private key.
+type keyStoreAccount struct {
+	Name           string         `json:"Name"`
+	PaymentAddress string         `json:"PaymentAddress"`
+	WatchOnly      bool           `json:"WatchOnly,omitempty"`
+	OTAKey         string         `json:"OTAKey,omitempty"`
+	ReadonlyKey    string         `json:"ReadonlyKey,omitempty"`
+	Crypto         *encryptedData `json:"Crypto,omitempty"`
+}
+
+// keyStore represents the local keystore file.
+type keyStore struct {
+	Version  int                `json:"Version"`
+	Accounts []*keyStoreAccount `json:"Accounts"`
+
+	path string
+}
+
+// cliHomeDir returns the directory where the CLI stores its local data (e.g, keystore, config).
+func cliHomeDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, cliHomeDirName), nil
+}
+
+// encryptWithPassphrase encrypts the given data with a passphrase using scrypt + AES-256-GCM.
+func encryptWithPassphrase(data, passphrase []byte, n, r, p int) (*encryptedData, error) {
+	salt := make([]byte, scryptSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := scrypt.Key(passphrase, salt, n, r, p, defaultScryptKeyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return &encryptedData{
+		KDF: "scrypt",
+		KDFParams: scryptParams{
+			N:      n,
+			R:      r,
+			P:      p,
+			KeyLen: defaultScryptKeyLen,
+			Salt:   hex.EncodeToString(salt),
+		},
+		Cipher:     "aes-256-gcm",
+		Nonce:      hex.EncodeToString(nonce),
+		CipherText: hex.EncodeToString(aesGCM.Seal(nil, nonce, data, nil)),
+	}, nil
+}
+
+// decryptWithPassphrase decrypts an encryptedData with the given passphrase.
+func decryptWithPassphrase(e *encryptedData, passphrase []byte) ([]byte, error) {
+	if e == nil {
+		return nil, fmt.Errorf("no encrypted data found")
+	}
+	if e.KDF != "scrypt" || e.Cipher != "aes-256-gcm" {
+		return nil, fmt.Errorf("unsupported kdf %v or cipher %v", e.KDF, e.Cipher)
+	}
+
+	salt, err := hex.DecodeString(e.KDFParams.Salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(e.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(e.CipherText)
+	if err != nil {
+		return nil, err
+	}
+
+	derivedKey, err := scrypt.Key(passphrase, salt, e.KDFParams.N, e.KDFParams.R, e.KDFParams.P, e.KDFParams.KeyLen)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, err
+	}
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	plainText, err := aesGCM.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return nil, fmt.Errorf("wrong passphrase or corrupted data")
+	}
+
+	return plainText, nil
+}
+
+// loadKeyStore loads the keystore from the CLI home directory. If the keystore file does not exist,
+// an empty keystore is returned.
+func loadKeyStore() (*keyStore, error) {
+	homeDir, err := cliHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return loadKeyStoreFromFile(filepath.Join(homeDir, keyStoreFileName))
+}
+
+// loadKeyStoreFromFile loads the keystore from the given file path.
+func loadKeyStoreFromFile(path string) (*keyStore, error) {
+	ks := &keyStore{Version: keyStoreVersion, Accounts: make([]*keyStoreAccount, 0), path: path}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ks, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, ks)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse keystore file %v: %v", path, err)
+	}
+	ks.path = path
+
+	return ks, nil
+}
+
+// save writes the keystore to its file.
+func (ks *keyStore) save() error {
+	err := os.MkdirAll(filepath.Dir(ks.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(ks.Accounts, func(i, j int) bool {
+		return ks.Accounts[i].Name < ks.Accounts[j].Name
+	})
+
+	data, err := json.MarshalIndent(ks, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(ks.path, data, 0600)
+}
+
+// getAccount returns the account with the given name.
+func (ks *keyStore) getAccount(name string) (*keyStoreAccount, error) {
+	for _, acc := range ks.Accounts {
+		if acc.Name == name {
+			return acc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("account `%v` not found", name)
+}
+
+// addAccount encrypts the private key with the passphrase and adds it to the keystore.
+func (ks *keyStore) addAccount(name, privateKey string, passphrase []byte, n, r, p int) (*keyStoreAccount, error) {
+	if name == "" {
+		return nil, fmt.Errorf("account name must not be empty")
+	}
+	if _, err := ks.getAccount(name); err == nil {
+		return nil, fmt.Errorf("account `%v` already exists", name)
+	}
+
+	e, err := encryptWithPassphrase([]byte(privateKey), passphrase, n, r, p)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := &keyStoreAccount{
+		Name:           name,
+		PaymentAddress: incclient.PrivateKeyToPaymentAddress(privateKey, -1),
+		Crypto:         e,
+	}
+	ks.Accounts = append(ks.Accounts, acc)
+
+	return acc, nil
+}
+
+// addWatchOnlyAccount adds a watch-only account to the keystore. The OTA key and the read-only key must belong to the
+// given payment address.
+func (ks *keyStore) addWatchOnlyAccount(name, address, otaKey, readonlyKey string) (*keyStoreAccount, error) {
+	if name == "" {
+		return nil, fmt.Errorf("account name must not be empty")
+	}
+	if _, err := ks.getAccount(name); err == nil {
+		return nil, fmt.Errorf("account `%v` already exists", name)
+	}
+
+	keySet, err := newViewKeySet(otaKey, readonlyKey)
+	if err != nil {
+		return nil, err
+	}
+	addrWallet, err := wallet.Base58CheckDeserialize(address)
+	if err != nil {
+		return nil, err
+	}
+	addr := addrWallet.KeySet.PaymentAddress
+	if !bytes.Equal(addr.Pk, keySet.PaymentAddress.Pk) || !bytes.Equal(addr.Tk, keySet.PaymentAddress.Tk) {
+		return nil, fmt.Errorf("the OTA key and the read-only key do not belong to payment address %v", address)
+	}
+
+	acc := &keyStoreAccount{
+		Name:           name,
+		PaymentAddress: address,
+		WatchOnly:      true,
+		OTAKey:         otaKey,
+		ReadonlyKey:    readonlyKey,
+	}
+	ks.Accounts = append(ks.Accounts, acc)
+
+	return acc, nil
+}
+
+// removeAccount removes the account with the given name from the keystore.
+func (ks *keyStore) removeAccount(name string) error {
+	for i, acc := range ks.Accounts {
+		if acc.Name == name {
+			ks.Accounts = append(ks.Accounts[:i], ks.Accounts[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("account `%v` not found", name)
+}
+
+// renameAccount changes the name of an account in the keystore.
+func (ks *keyStore) renameAccount(oldName, newName string) error {
+	if newName == "" {
+		return fmt.Errorf("account name must not be empty")
+	}
+	if _, err := ks.getAccount(newName); err == nil {
+		return fmt.Errorf("account `%v` already exists", newName)
+	}
+	acc, err := ks.getAccount(oldName)
+	if err != nil {
+		return err
+	}
+	acc.Name = newName
+
+	return nil
+}
+
+// decryptPrivateKey decrypts the private key of the account with the given passphrase.
+func (acc *keyStoreAccount) decryptPrivateKey(passphrase []byte) (string, error) {
+	plainText, err := decryptWithPassphrase(acc.Crypto, passphrase)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plainText), nil
+}
+
+// unlockAccount loads the account with the given name from the keystore and decrypts its private key with the
+// passphrase returned by getKeystorePassphrase.
+func unlockAccount(name string) (string, error) {
+	ks, err := loadKeyStore()
+	if err != nil {
+		return "", newAppError(LoadKeyStoreError, err)
+	}
+	acc, err := ks.getAccount(name)
+	if err != nil {
+		return "", newAppError(AccountNotFoundError, err)
+	}
+	if acc.WatchOnly {
+		return "", newAppError(WatchOnlyAccountError,
+			fmt.Errorf("account `%v` is watch-only, this action requires its private key", name))
+	}
+
+	accountPassphrase, err := getKeystorePassphrase(name)
+	if err != nil {
+		return "", newAppError(UserInputError, err)
+	}
+
+	privateKey, err := acc.decryptPrivateKey([]byte(accountPassphrase))
+	if err != nil {
+		return "", newAppError(DecryptAccountError, err)
+	}
+
+	return privateKey, nil
+}
+
+// getPrivateKey returns the private key for a command. The private key is taken from the `privateKey` flag if provided;
+// otherwise, it is resolved from the local keystore via the global `account` flag.
+func getPrivateKey(c *cli.Context) (string, error) {
+	var err error
+	privateKey := c.String(privateKeyFlag)
+	if privateKey == "" && accountName != "" {
+		privateKey, err = unlockAccount(accountName)
+		if err != nil {
+			return "", err
+		}
+	}
+	if !isValidPrivateKey(privateKey) {
+		return "", newAppError(InvalidPrivateKeyError)
+	}
+
+	return privateKey, nil
+}
+
+// promptNewPassphrase asks the user for a new passphrase to encrypt the given target twice and makes sure they match.
+func promptNewPassphrase(target string) ([]byte, error) {
+	passphrase, err := promptInput(fmt.Sprintf("Enter a passphrase to encrypt %v", target), new(string), true)
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+	confirmed, err := promptInput("Re-enter the passphrase", new(string), true)
+	if err != nil {
+		return nil, err
+	}
+	if string(passphrase) != string(confirmed) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+
+	return passphrase, nil
+}
+
+// keyStoreAdd encrypts a private key and adds it to the local keystore.
+func keyStoreAdd(c *cli.Context) error {
+	name := c.String(accountNameFlag)
+
+	privateKey := c.String(privateKeyFlag)
+	if privateKey == "" {
+		input, err := promptInput("Enter the private key", new(string), true)
+		if err != nil {
+			return newAppError(UserInputError, err)
+		}
+		privateKey = string(input)
+	}
+	if !isValidPrivateKey(privateKey) {
+		return newAppError(InvalidPrivateKeyError)
+	}
+
+	ks, err := loadKeyStore()
+	if err != nil {
+		return newAppError(LoadKeyStoreError, err)
+	}
+	if _, err = ks.getAccount(name); err == nil {
+		return newAppError(AccountExistedError, fmt.Errorf("account `%v` already exists", name))
+	}
+
+	passphrase, err := promptNewPassphrase("the private key")
+	if err != nil {
+		return newAppError(UserInputError, err)
+	}
+
+	acc, err := ks.addAccount(name, privateKey, passphrase, defaultScryptN, defaultScryptR, defaultScryptP)
+	if err != nil {
+		return newAppError(AccountExistedError, err)
+	}
+	err = ks.save()
+	if err != nil {
+		return newAppError(SaveKeyStoreError, err)
+	}
+
+	return printResult(map[string]string{"Name": acc.Name, "PaymentAddress": acc.PaymentAddress})
+}
+
+// keyStoreAddWatch adds a watch-only account to the local keystore.
+func keyStoreAddWatch(c *cli.Context) error {
+	name := c.String(accountNameFlag)
+
+	address := c.String(addressFlag)
+	if !isValidAddress(address) {
+		return newAppError(InvalidPaymentAddressError)
+	}
+
+	otaKey := c.String(otaKeyFlag)
+	if !isValidOtaKey(otaKey) {
+		return newAppError(InvalidOTAKeyError)
+	}
+
+	readonlyKey := c.String(readonlyKeyFlag)
+	if !isValidReadonlyKey(readonlyKey) {
+		return newAppError(InvalidReadonlyKeyError)
+	}
+
+	ks, err := loadKeyStore()
+	if err != nil {
+		return newAppError(LoadKeyStoreError, err)
+	}
+	if _, err = ks.getAccount(name); err == nil {
+		return newAppError(AccountExistedError, fmt.Errorf("account `%v` already exists", name))
+	}
+
+	acc, err := ks.addWatchOnlyAccount(name, address, otaKey, readonlyKey)
+	if err != nil {
+		return newAppError(InvalidReadonlyKeyError, err)
+	}
+	err = ks.save()
+	if err != nil {
+		return newAppError(SaveKeyStoreError, err)
+	}
+
+	return printResult(map[string]interface{}{"Name": acc.Name, "PaymentAddress": acc.PaymentAddress, "WatchOnly": true})
+}
+
+// keyStoreList lists all accounts in the local keystore.
+func keyStoreList(_ *cli.Context) error {
+	ks, err := loadKeyStore()
+	if err != nil {
+		return newAppError(LoadKeyStoreError, err)
+	}
+
+	type accountEntry struct {
+		Name           string
+		PaymentAddress string
+		WatchOnly      bool
+	}
+	res := make([]accountEntry, 0)
+	for _, acc := range ks.Accounts {
+		res = append(res, accountEntry{Name: acc.Name, PaymentAddress: acc.PaymentAddress, WatchOnly: acc.WatchOnly})
+	}
+
+	return printResult(res)
+}
+
+// keyStoreRemove removes an account from the local keystore.
+func keyStoreRemove(c *cli.Context) error {
+	name := c.String(accountNameFlag)
+
+	ks, err := loadKeyStore()
+	if err != nil {
+		return newAppError(LoadKeyStoreError, err)
+	}
+	if _, err = ks.getAccount(name); err != nil {
+		return newAppError(AccountNotFoundError, err)
+	}
+
+	err = yesNoPrompt(fmt.Sprintf("Account `%v` will be removed from the keystore. Make sure you have backed up its private key. Continue?", name))
+	if err != nil {
+		return err
+	}
+	_ = ks.removeAccount(name)
+	err = ks.save()
+	if err != nil {
+		return newAppError(SaveKeyStoreError, err)
+	}
+
+	return nil
+}
+
+// keyStoreRename renames an account in the local keystore.
+func keyStoreRename(c *cli.Context) error {
+	name := c.String(accountNameFlag)
+	newName := c.String(newAccountNameFlag)
+
+	ks, err := loadKeyStore()
+	if err != nil {
+		return newAppError(LoadKeyStoreError, err)
+	}
+	if _, err = ks.getAccount(name); err != nil {
+		return newAppError(AccountNotFoundError, err)
+	}
+	err = ks.renameAccount(name, newName)
+	if err != nil {
+		return newAppError(AccountExistedError, err)
+	}
+	err = ks.save()
+	if err != nil {
+		return newAppError(SaveKeyStoreError, err)
+	}
+
+	return nil
+}
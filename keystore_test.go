@@ -0,0 +1,144 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+func TestKeyStore_EncryptDecrypt(t *testing.T) {
+	passphrase := []byte("a strong passphrase")
+	e, err := encryptWithPassphrase([]byte(testIncPrivateKey), passphrase, 1<<10, 8, 1)
+	if err != nil {
+		panic(err)
+	}
+
+	plainText, err := decryptWithPassphrase(e, passphrase)
+	if err != nil {
+		panic(err)
+	}
+	if string(plainText) != testIncPrivateKey {
+		t.Fatalf("expect decrypted data to be %v, got %v", testIncPrivateKey, string(plainText))
+	}
+
+	_, err = decryptWithPassphrase(e, []byte("wrong passphrase"))
+	if err == nil {
+		t.Fatalf("expect an error when decrypting with a wrong passphrase")
+	}
+}
+
+func TestKeyStore_AddRenameRemove(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, keyStoreFileName)
+	ks, err := loadKeyStoreFromFile(path)
+	if err != nil {
+		panic(err)
+	}
+
+	passphrase := []byte("123456")
+	_, err = ks.addAccount("treasury", testIncPrivateKey, passphrase, 1<<10, 8, 1)
+	if err != nil {
+		panic(err)
+	}
+	_, err = ks.addAccount("treasury", testIncPrivateKey, passphrase, 1<<10, 8, 1)
+	if err == nil {
+		t.Fatalf("expect an error when adding a duplicated account")
+	}
+	err = ks.save()
+	if err != nil {
+		panic(err)
+	}
+
+	ks, err = loadKeyStoreFromFile(path)
+	if err != nil {
+		panic(err)
+	}
+	err = ks.renameAccount("treasury", "cold")
+	if err != nil {
+		panic(err)
+	}
+	acc, err := ks.getAccount("cold")
+	if err != nil {
+		panic(err)
+	}
+	privateKey, err := acc.decryptPrivateKey(passphrase)
+	if err != nil {
+		panic(err)
+	}
+	if privateKey != testIncPrivateKey {
+		t.Fatalf("expect private key %v, got %v", testIncPrivateKey, privateKey)
+	}
+
+	err = ks.removeAccount("cold")
+	if err != nil {
+		panic(err)
+	}
+	if len(ks.Accounts) != 0 {
+		t.Fatalf("expect an empty keystore, got %v accounts", len(ks.Accounts))
+	}
+}
+
+func TestKeyStore_AddWatchOnlyAccount(t *testing.T) {
+	ks := &keyStore{Version: keyStoreVersion, Accounts: make([]*keyStoreAccount, 0)}
+	otaKey := incclient.PrivateKeyToPrivateOTAKey(testIncPrivateKey)
+	readonlyKey := incclient.PrivateKeyToReadonlyKey(testIncPrivateKey)
+
+	for _, address := range []string{
+		incclient.PrivateKeyToPaymentAddress(testIncPrivateKey, -1),
+		incclient.PrivateKeyToPaymentAddress(testIncPrivateKey, 0),
+	} {
+		acc, err := ks.addWatchOnlyAccount(address[:8], address, otaKey, readonlyKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !acc.WatchOnly || acc.Crypto != nil {
+			t.Fatalf("expect a watch-only account without encrypted data")
+		}
+	}
+
+	otherKey := wallet.KeyWallet{KeySet: *new(key.KeySet).GenerateKey([]byte("other"))}
+	otherAddress := otherKey.Base58CheckSerialize(wallet.PaymentAddressType)
+	_, err := ks.addWatchOnlyAccount("other", otherAddress, otaKey, readonlyKey)
+	if err == nil {
+		t.Fatalf("expect an error when the keys do not belong to the payment address")
+	}
+}
+
+func TestGetKeystorePassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer func(oldPrompter prompter) { appPrompter = oldPrompter; passphraseFile = "" }(appPrompter)
+	defer os.Setenv(keystorePassphraseEnv, os.Getenv(keystorePassphraseEnv))
+	appPrompter = nonInteractivePrompter{}
+
+	os.Unsetenv(keystorePassphraseEnv)
+	if _, err = getKeystorePassphrase("test"); err == nil {
+		t.Fatal("expect an error without a passphrase in non-interactive mode")
+	}
+
+	os.Setenv(keystorePassphraseEnv, "from env")
+	if res, err := getKeystorePassphrase("test"); err != nil || res != "from env" {
+		t.Fatalf("expect the passphrase of the environment, got (%q, %v)", res, err)
+	}
+
+	passphraseFile = filepath.Join(dir, "passphrase")
+	if err = ioutil.WriteFile(passphraseFile, []byte(" from file \n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := getKeystorePassphrase("test"); err != nil || res != " from file " {
+		t.Fatalf("expect the passphrase of the file, got (%q, %v)", res, err)
+	}
+}
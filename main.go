@@ -1,8 +1,8 @@
 package main
 
 import (
+	"fmt"
 	"github.com/urfave/cli/v2"
-	"log"
 	"os"
 	"sort"
 )
@@ -30,11 +30,40 @@ func main() {
 	app.Flags = []cli.Flag{
 		defaultFlags[networkFlag],
 		defaultFlags[hostFlag],
+		defaultFlags[clientVersionFlag],
 		defaultFlags[debugFlag],
 		defaultFlags[cacheFlag],
+		defaultFlags[accountFlag],
+		defaultFlags[passphraseFlag],
+		defaultFlags[profileFlag],
+		defaultFlags[outputFlag],
+		defaultFlags[yesFlag],
+		defaultFlags[waitFlag],
+		defaultFlags[waitTimeoutFlag],
+	}
+	app.Before = func(c *cli.Context) error {
+		switch outputFormat {
+		case jsonOutput, yamlOutput, tableOutput, textOutput:
+		default:
+			err := fmt.Errorf("expect output to be one of json, yaml, table, text; got %v", outputFormat)
+			outputFormat = jsonOutput
+			return newAppError(UserInputError, err)
+		}
+
+		if assumeYes {
+			askUser = false
+			appPrompter = nonInteractivePrompter{}
+		}
+
+		return applyProfile(c)
 	}
 
 	app.Commands = make([]*cli.Command, 0)
+	app.Commands = append(app.Commands, configCommands...)
+	app.Commands = append(app.Commands, networkCommands...)
+	app.Commands = append(app.Commands, cacheCommands...)
+	app.Commands = append(app.Commands, daemonCommands...)
+	app.Commands = append(app.Commands, tokenCommands...)
 	app.Commands = append(app.Commands, accountCommands...)
 	app.Commands = append(app.Commands, committeeCommands...)
 	app.Commands = append(app.Commands, txCommands...)
@@ -46,6 +75,12 @@ func main() {
 			sort.Sort(cli.CommandsByName(command.Subcommands))
 			for _, subCommand := range command.Subcommands {
 				buildUsageTextFromCommand(subCommand, command.Name)
+				if len(subCommand.Subcommands) > 0 {
+					sort.Sort(cli.CommandsByName(subCommand.Subcommands))
+					for _, subSubCommand := range subCommand.Subcommands {
+						buildUsageTextFromCommand(subSubCommand, fmt.Sprintf("%v %v", command.Name, subCommand.Name))
+					}
+				}
 			}
 		}
 		buildUsageTextFromCommand(command)
@@ -58,6 +93,6 @@ func main() {
 
 	err := app.Run(os.Args)
 	if err != nil {
-		log.Fatal(err)
+		os.Exit(handleError(err))
 	}
 }
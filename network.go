@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/urfave/cli/v2"
+)
+
+// probeTimeout is the maximum time spent probing an EVM endpoint.
+const probeTimeout = 15 * time.Second
+
+// endpointStatus represents the result of probing an endpoint.
+type endpointStatus struct {
+	Name    string
+	Host    string
+	Latency string `json:",omitempty"`
+	ChainID string `json:",omitempty"`
+	Height  uint64 `json:",omitempty"`
+	Error   string `json:",omitempty"`
+}
+
+// networkBeforeFunc sets up the global cfg without connecting to any endpoint.
+func networkBeforeFunc(_ *cli.Context) error {
+	return initNetWork()
+}
+
+// getFullNodeHost returns the Incognito full-node the CLI is connecting to.
+func getFullNodeHost() string {
+	if host != "" {
+		return host
+	}
+	switch cfg.network {
+	case "mainnet":
+		return incclient.MainNetFullNode
+	case "testnet":
+		return incclient.TestNetFullNode
+	case "testnet1":
+		return incclient.TestNet1FullNode
+	default:
+		return incclient.LocalFullNode
+	}
+}
+
+// probeIncognito checks the connection to the Incognito full-node and retrieves the beacon height.
+func probeIncognito() endpointStatus {
+	res := endpointStatus{Name: "Incognito", Host: getFullNodeHost(), ChainID: cfg.network}
+
+	incClient, err := cfg.getIncClient()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	start := time.Now()
+	bestBlocks, err := incClient.GetBestBlock()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Latency = time.Since(start).Round(time.Millisecond).String()
+	res.Height = bestBlocks[-1]
+
+	return res
+}
+
+// probeEVM checks the connection to the RPC endpoint of an EVM network and retrieves its chain-id and block height.
+func probeEVM(evmNetwork string, evmNetworkID int) endpointStatus {
+	res := endpointStatus{Name: evmNetwork, Host: cfg.evmEndpoints[evmNetworkID].Host}
+
+	evmClient, err := cfg.getEVMClient(evmNetworkID)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	chainID, err := evmClient.ChainID(ctx)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Latency = time.Since(start).Round(time.Millisecond).String()
+	res.ChainID = chainID.String()
+
+	res.Height, err = evmClient.BlockNumber(ctx)
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	return res
+}
+
+// probeBTC checks the connection to the BTC backend and retrieves its block height.
+func probeBTC() endpointStatus {
+	res := endpointStatus{Name: "BTC", Host: btcBlockCypherBackend}
+	if activeProfile != nil && activeProfile.BTC != nil && activeProfile.BTC.Backend == btcRPCBackend {
+		res.Host = activeProfile.BTC.Host
+	}
+
+	btcClient, err := cfg.getBTCClient()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	start := time.Now()
+	res.Height, err = btcClient.GetBlockHeight()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Latency = time.Since(start).Round(time.Millisecond).String()
+
+	return res
+}
+
+// networkStatus probes all configured endpoints concurrently and reports their latency, chain-id and height.
+func networkStatus(_ *cli.Context) error {
+	probes := []func() endpointStatus{
+		probeIncognito,
+		func() endpointStatus { return probeEVM("ETH", rpc.ETHNetworkID) },
+		func() endpointStatus { return probeEVM("BSC", rpc.BSCNetworkID) },
+		func() endpointStatus { return probeEVM("PLG", rpc.PLGNetworkID) },
+		func() endpointStatus { return probeEVM("FTM", rpc.FTMNetworkID) },
+		probeBTC,
+	}
+
+	res := make([]endpointStatus, len(probes))
+	var wg sync.WaitGroup
+	for i, probe := range probes {
+		wg.Add(1)
+		go func(i int, probe func() endpointStatus) {
+			defer wg.Done()
+			res[i] = probe()
+		}(i, probe)
+	}
+	wg.Wait()
+
+	numFailed := 0
+	for _, status := range res {
+		if status.Error != "" {
+			numFailed++
+		}
+	}
+	err := printResult(res)
+	if err != nil {
+		return err
+	}
+	if numFailed > 0 {
+		return newAppError(NetworkStatusError, fmt.Errorf("%v/%v endpoints unreachable", numFailed, len(res)))
+	}
+
+	return nil
+}
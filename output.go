@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+// supported output formats.
+const (
+	jsonOutput  = "json"
+	yamlOutput  = "yaml"
+	tableOutput = "table"
+	textOutput  = "text"
+)
+
+// printResultWithKey prints a result consisting of a single key.
+func printResultWithKey(key string, val interface{}) error {
+	return printResult(map[string]interface{}{key: val})
+}
+
+// printResult prints the result of a command to the standard output in the format chosen by the global output flag.
+// Progress and log lines must go to the standard error so that the standard output only consists of results.
+func printResult(val interface{}) error {
+	return render(os.Stdout, val)
+}
+
+// render writes val to w in the format chosen by the global output flag.
+func render(w io.Writer, val interface{}) error {
+	if outputFormat == jsonOutput || outputFormat == "" {
+		jsb, err := json.MarshalIndent(val, "", "\t")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsb))
+		return err
+	}
+
+	// go through the JSON form of the value so that all formats use the same field names.
+	jsb, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(jsb))
+	decoder.UseNumber()
+	generic, err := decodeOrdered(decoder)
+	if err != nil {
+		return err
+	}
+
+	switch outputFormat {
+	case yamlOutput:
+		yb, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(yb)
+		return err
+	case tableOutput:
+		return renderTable(w, generic)
+	case textOutput:
+		return renderText(w, generic, "")
+	}
+
+	return fmt.Errorf("output format %v not supported", outputFormat)
+}
+
+// decodeOrdered decodes the next JSON value from the decoder. Unlike json.Unmarshal into an interface{}, objects are
+// decoded into yaml.MapSlice values to keep the order of their keys.
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+	switch delim {
+	case '{':
+		res := make(yaml.MapSlice, 0)
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, yaml.MapItem{Key: keyToken, Value: value})
+		}
+		_, err = decoder.Token()
+		return res, err
+	case '[':
+		res := make([]interface{}, 0)
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, value)
+		}
+		_, err = decoder.Token()
+		return res, err
+	}
+
+	return nil, fmt.Errorf("unexpected delimiter %v", delim)
+}
+
+// formatValue returns the single-line form of a decoded value. Nested objects and lists are printed in compact JSON.
+func formatValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprintf("%v", v)
+	case yaml.MapSlice:
+		items := make([]string, 0)
+		for _, item := range v {
+			key, _ := json.Marshal(fmt.Sprintf("%v", item.Key))
+			items = append(items, fmt.Sprintf("%s:%v", key, formatJSONValue(item.Value)))
+		}
+		return "{" + strings.Join(items, ",") + "}"
+	case []interface{}:
+		items := make([]string, 0)
+		for _, item := range v {
+			items = append(items, formatJSONValue(item))
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+
+	return fmt.Sprintf("%v", val)
+}
+
+// formatJSONValue is the same as formatValue except that strings are quoted.
+func formatJSONValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "null"
+	case string:
+		jsb, _ := json.Marshal(v)
+		return string(jsb)
+	}
+
+	return formatValue(val)
+}
+
+// renderTable writes a decoded value as a table. A list of objects is printed with one row per object; an object is
+// printed as KEY/VALUE rows, unless it only wraps a single list (e.g, {"TxList": [...]}) in which case the list is printed.
+func renderTable(w io.Writer, val interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	if m, ok := val.(yaml.MapSlice); ok && len(m) == 1 {
+		if list, ok := m[0].Value.([]interface{}); ok {
+			val = list
+		}
+	}
+
+	switch v := val.(type) {
+	case yaml.MapSlice:
+		_, _ = fmt.Fprintln(tw, "KEY\tVALUE")
+		for _, item := range v {
+			_, _ = fmt.Fprintf(tw, "%v\t%v\n", item.Key, formatValue(item.Value))
+		}
+	case []interface{}:
+		columns := make([]string, 0)
+		seen := make(map[string]bool)
+		for _, row := range v {
+			m, ok := row.(yaml.MapSlice)
+			if !ok {
+				continue
+			}
+			for _, item := range m {
+				key := fmt.Sprintf("%v", item.Key)
+				if !seen[key] {
+					seen[key] = true
+					columns = append(columns, key)
+				}
+			}
+		}
+		if len(columns) == 0 {
+			_, _ = fmt.Fprintln(tw, "VALUE")
+			for _, row := range v {
+				_, _ = fmt.Fprintln(tw, formatValue(row))
+			}
+			break
+		}
+
+		_, _ = fmt.Fprintln(tw, strings.ToUpper(strings.Join(columns, "\t")))
+		for _, row := range v {
+			cells := make([]string, len(columns))
+			if m, ok := row.(yaml.MapSlice); ok {
+				for _, item := range m {
+					for i, column := range columns {
+						if column == fmt.Sprintf("%v", item.Key) {
+							cells[i] = formatValue(item.Value)
+						}
+					}
+				}
+			}
+			_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+		}
+	default:
+		_, _ = fmt.Fprintln(tw, formatValue(v))
+	}
+
+	return tw.Flush()
+}
+
+// renderText writes a decoded value in a human-readable form, one "key: value" per line.
+func renderText(w io.Writer, val interface{}, indent string) error {
+	var err error
+	switch v := val.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			switch item.Value.(type) {
+			case yaml.MapSlice, []interface{}:
+				_, err = fmt.Fprintf(w, "%v%v:\n", indent, item.Key)
+				if err != nil {
+					return err
+				}
+				err = renderText(w, item.Value, indent+"  ")
+			default:
+				_, err = fmt.Fprintf(w, "%v%v: %v\n", indent, item.Key, formatValue(item.Value))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if _, ok := item.(yaml.MapSlice); ok {
+				if i > 0 {
+					_, err = fmt.Fprintln(w)
+					if err != nil {
+						return err
+					}
+				}
+				err = renderText(w, item, indent)
+			} else {
+				_, err = fmt.Fprintf(w, "%v- %v\n", indent, formatValue(item))
+			}
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		_, err = fmt.Fprintf(w, "%v%v\n", indent, formatValue(v))
+	}
+
+	return err
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
 	"github.com/incognitochain/incognito-cli/pdex_v3"
 	"github.com/urfave/cli/v2"
 	"strings"
@@ -9,28 +10,43 @@ import (
 
 // pDEXTrade creates and sends a trade to the pDEX.
 func pDEXTrade(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
-	tokenIdToSell := c.String(tokenIDToSellFlag)
-	if !isValidTokenID(tokenIdToSell) {
-		return newAppError(InvalidSellTokenIDError)
+	tokenIdToSell, err := getTokenIDFlag(c, tokenIDToSellFlag)
+	if err != nil {
+		return newAppError(InvalidSellTokenIDError, err)
 	}
 
-	tokenIdToBuy := c.String(tokenIDToBuyFlag)
-	if !isValidTokenID(tokenIdToBuy) {
-		return newAppError(InvalidBuyTokenIDError)
+	tokenIdToBuy, err := getTokenIDFlag(c, tokenIDToBuyFlag)
+	if err != nil {
+		return newAppError(InvalidBuyTokenIDError, err)
 	}
 
-	sellingAmount := c.Uint64(sellingAmountFlag)
+	sellingAmount, err := getAmountFlag(c, sellingAmountFlag, tokenIdToSell)
+	if err != nil {
+		return newAppError(InvalidSellAmountError, err)
+	}
 	if sellingAmount == 0 {
 		return newAppError(InvalidSellAmountError)
 	}
 
-	minAcceptableAmount := c.Uint64(minAcceptableAmountFlag)
-	tradingFee := c.Uint64(tradingFeeFlag)
+	minAcceptableAmount, err := getAmountFlag(c, minAcceptableAmountFlag, tokenIdToBuy)
+	if err != nil {
+		return newAppError(InvalidMinAcceptableAmountError, err)
+	}
+
+	prvFee := c.Int(prvFeeFlag)
+	tradingFeeTokenID := tokenIdToSell
+	if prvFee != 0 {
+		tradingFeeTokenID = common.PRVIDStr
+	}
+	tradingFee, err := getAmountFlag(c, tradingFeeFlag, tradingFeeTokenID)
+	if err != nil {
+		return newAppError(InvalidTradingFeeError, err)
+	}
 	if tradingFee == 0 {
 		return newAppError(InvalidTradingFeeError)
 	}
@@ -63,9 +79,12 @@ func pDEXTrade(c *cli.Context) error {
 		return newAppError(InvalidTradingPathError, fmt.Errorf("maximum trading path length %v, got %v", maxPaths, len(tradingPath)))
 	}
 
-	prvFee := c.Int(prvFeeFlag)
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
 
-	txHash, err := cfg.incClient.CreateAndSendPdexv3TradeTransaction(
+	encodedTx, txHash, err := createPdexv3TradeTx(
 		privateKey,
 		tradingPath,
 		tokenIdToSell,
@@ -74,38 +93,48 @@ func pDEXTrade(c *cli.Context) error {
 		minAcceptableAmount,
 		tradingFee,
 		prvFee != 0,
+		fee,
 	)
 	if err != nil {
 		return newAppError(CreateDexTradeTransactionError, err)
 	}
+	err = sendRawTx(encodedTx, tokenIdToSell != common.PRVIDStr)
+	if err != nil {
+		return newAppError(CreateDexTradeTransactionError, err)
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXMintNFT creates and sends a transaction that mints a new C-NFT for a given user.
 func pDEXMintNFT(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
 	}
 
-	encodedTx, txHash, err := cfg.incClient.CreatePdexv3MintNFT(privateKey)
+	encodedTx, txHash, err := createPdexv3MintNFTTx(privateKey, fee)
 	if err != nil {
 		return newAppError(CreateMintNFTTransactionError, err)
 	}
-	err = cfg.incClient.SendRawTx(encodedTx)
+	err = sendRawTx(encodedTx, false)
 	if err != nil {
 		return newAppError(SendRawTxError)
 	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXContribute contributes a token to the pDEX.
 func pDEXContribute(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	nftID := c.String(nftIDFlag)
@@ -115,11 +144,6 @@ func pDEXContribute(c *cli.Context) error {
 		return newAppError(InvalidTokenIDError)
 	}
 
-	amount := c.Uint64(amountFlag)
-	if amount == 0 {
-		return newAppError(InvalidAmountError)
-	}
-
 	amplifier := c.Uint64(amplifierFlag)
 	if amplifier == 0 {
 		return newAppError(InvalidAmplifierError)
@@ -127,12 +151,25 @@ func pDEXContribute(c *cli.Context) error {
 
 	pairID := c.String(pairIDFlag)
 
-	tokenId := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenId) {
-		return newAppError(InvalidTokenIDError)
+	tokenId, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
-	txHash, err := cfg.incClient.CreateAndSendPdexv3ContributeTransaction(
+	amount, err := getAmountFlag(c, amountFlag, tokenId)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
+	if amount == 0 {
+		return newAppError(InvalidAmountError)
+	}
+
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createPdexv3ContributeTx(
 		privateKey,
 		pairID,
 		pairHash,
@@ -140,19 +177,24 @@ func pDEXContribute(c *cli.Context) error {
 		nftID,
 		amount,
 		amplifier,
+		fee,
 	)
 	if err != nil {
 		return newAppError(CreateDexContributionTransactionError, err)
 	}
+	err = sendRawTx(encodedTx, tokenId != common.PRVIDStr)
+	if err != nil {
+		return newAppError(CreateDexContributionTransactionError, err)
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXWithdraw withdraws a pair of tokens from the pDEX.
 func pDEXWithdraw(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	pairID := c.String(pairIDFlag)
@@ -174,26 +216,36 @@ func pDEXWithdraw(c *cli.Context) error {
 		return fmt.Errorf("maximum share allowed to withdraw: %v", myShare)
 	}
 
-	txHash, err := cfg.incClient.CreateAndSendPdexv3WithdrawLiquidityTransaction(
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createPdexv3WithdrawLiquidityTx(
 		privateKey,
 		pairID,
 		tmpTokenIDs[0],
 		tmpTokenIDs[1],
 		nftID,
 		shareAmount,
+		fee,
 	)
 	if err != nil {
 		return err
 	}
+	err = sendRawTx(encodedTx, true)
+	if err != nil {
+		return err
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXAddOrder places an order to the pDEX.
 func pDEXAddOrder(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	pairID := c.String(pairIDFlag)
@@ -218,9 +270,9 @@ func pDEXAddOrder(c *cli.Context) error {
 		return newAppError(InvalidNFTError, fmt.Errorf("nftID %v does not belong to the private key %v", nftID, privateKey))
 	}
 
-	tokenIdToSell := c.String(tokenIDToSellFlag)
-	if !isValidTokenID(tokenIdToSell) {
-		return newAppError(InvalidSellTokenIDError)
+	tokenIdToSell, err := getTokenIDFlag(c, tokenIDToSellFlag)
+	if err != nil {
+		return newAppError(InvalidSellTokenIDError, err)
 	}
 	if tokenIdToSell != tokenIDs[0] && tokenIdToSell != tokenIDs[1] {
 		return newAppError(InvalidSellTokenIDError, fmt.Errorf("tokenToSell %v not belong to pool pair %v", tokenIdToSell, pairID))
@@ -230,16 +282,27 @@ func pDEXAddOrder(c *cli.Context) error {
 		tokenIdToBuy = tokenIDs[0]
 	}
 
-	sellingAmount := c.Uint64(sellingAmountFlag)
+	sellingAmount, err := getAmountFlag(c, sellingAmountFlag, tokenIdToSell)
+	if err != nil {
+		return newAppError(InvalidSellAmountError, err)
+	}
 	if sellingAmount == 0 {
 		return newAppError(InvalidSellAmountError)
 	}
 
-	minAcceptableAmount := c.Uint64(minAcceptableAmountFlag)
+	minAcceptableAmount, err := getAmountFlag(c, minAcceptableAmountFlag, tokenIdToBuy)
+	if err != nil {
+		return newAppError(InvalidMinAcceptableAmountError, err)
+	}
 	if minAcceptableAmount == 0 {
 		return newAppError(InvalidMinAcceptableAmountError)
 	}
-	txHash, err := cfg.incClient.CreateAndSendPdexv3AddOrderTransaction(
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createPdexv3AddOrderTx(
 		privateKey,
 		pairID,
 		tokenIdToSell,
@@ -247,19 +310,24 @@ func pDEXAddOrder(c *cli.Context) error {
 		nftID,
 		sellingAmount,
 		minAcceptableAmount,
+		fee,
 	)
 	if err != nil {
 		return newAppError(CreateAddOrderTransactionError, err)
 	}
+	err = sendRawTx(encodedTx, tokenIdToSell != common.PRVIDStr)
+	if err != nil {
+		return newAppError(CreateAddOrderTransactionError, err)
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXWithdrawOrder withdraws an order from the pDEX.
 func pDEXWithdrawOrder(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	pairID := c.String(pairIDFlag)
@@ -270,12 +338,18 @@ func pDEXWithdrawOrder(c *cli.Context) error {
 	nftID := c.String(nftIDFlag)
 	orderID := c.String(orderIDFlag)
 
-	tokenId1 := c.String(tokenID1Flag)
+	tokenId1, err := resolveTokenID(c.String(tokenID1Flag))
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
 	if !isValidTokenID(tokenId1) && tokenId1 != tmpTokenIDs[0] && tokenId1 != tmpTokenIDs[1] {
 		return newAppError(InvalidTokenIDError, fmt.Errorf("%v is invalid", tokenID1Flag))
 	}
 
-	tokenId2 := c.String(tokenID2Flag)
+	tokenId2, err := resolveTokenID(c.String(tokenID2Flag))
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
 	if tokenId2 != "" && !isValidTokenID(tokenId2) && tokenId2 != tmpTokenIDs[0] && tokenId2 != tmpTokenIDs[1] {
 		return newAppError(InvalidTokenIDError, fmt.Errorf("%v is invalid", tokenID2Flag))
 	}
@@ -286,124 +360,170 @@ func pDEXWithdrawOrder(c *cli.Context) error {
 	if tokenId2 != "" {
 		tokenIDs = append(tokenIDs, tokenId2)
 	}
-	txHash, err := cfg.incClient.CreateAndSendPdexv3WithdrawOrderTransaction(
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createPdexv3WithdrawOrderTx(
 		privateKey,
 		pairID,
 		orderID,
 		nftID,
 		amount,
-		tokenIDs...,
+		tokenIDs,
+		fee,
 	)
 	if err != nil {
 		return newAppError(CreateWithdrawOrderTransactionError, err)
 	}
+	err = sendRawTx(encodedTx, true)
+	if err != nil {
+		return newAppError(CreateWithdrawOrderTransactionError, err)
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXStake creates a pDEX staking transaction.
 func pDEXStake(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	nftID := c.String(nftIDFlag)
 
-	tokenID := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenID) {
-		return newAppError(InvalidTokenIDError)
+	tokenID, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
-	amount := c.Uint64(amountFlag)
+	amount, err := getAmountFlag(c, amountFlag, tokenID)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
 	if amount == 0 {
 		return newAppError(InvalidAmountError)
 	}
 
-	txHash, err := cfg.incClient.CreateAndSendPdexv3StakingTransaction(
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createPdexv3StakingTx(
 		privateKey,
 		tokenID,
 		nftID,
 		amount,
+		fee,
 	)
 	if err != nil {
 		return newAppError(CreateDexStakingTransactionError, err)
 	}
+	err = sendRawTx(encodedTx, tokenID != common.PRVIDStr)
+	if err != nil {
+		return newAppError(CreateDexStakingTransactionError, err)
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXUnStake creates a pDEX un-staking transaction.
 func pDEXUnStake(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	nftID := c.String(nftIDFlag)
 
-	tokenID := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenID) {
-		return newAppError(InvalidTokenIDError)
+	tokenID, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
-	amount := c.Uint64(amountFlag)
+	amount, err := getAmountFlag(c, amountFlag, tokenID)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
 	if amount == 0 {
 		return newAppError(InvalidAmountError)
 	}
 
-	txHash, err := cfg.incClient.CreateAndSendPdexv3UnstakingTransaction(
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createPdexv3UnStakingTx(
 		privateKey,
 		tokenID,
 		nftID,
 		amount,
+		fee,
 	)
 	if err != nil {
 		return newAppError(CreateDexUnStakingTransactionError, err)
 	}
+	err = sendRawTx(encodedTx, true)
+	if err != nil {
+		return newAppError(CreateDexUnStakingTransactionError, err)
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // CheckDEXStakingReward returns the estimated pDEX staking rewards.
 func CheckDEXStakingReward(c *cli.Context) error {
 	nftID := c.String(nftIDFlag)
-	tokenID := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenID) {
-		return newAppError(InvalidTokenIDError)
+	tokenID, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	res, err := cfg.incClient.GetEstimatedDEXStakingReward(0, tokenID, nftID)
 	if err != nil {
 		return newAppError(EstimateDEXStakingRewardError, err)
 	}
-	return jsonPrint(res)
+	return printResult(res)
 }
 
 // pDEXWithdrawStakingReward creates a transaction withdrawing the staking rewards from the pDEX.
 func pDEXWithdrawStakingReward(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	nftID := c.String(nftIDFlag)
 
-	tokenID := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenID) {
-		return newAppError(InvalidTokenIDError)
+	tokenID, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
-	txHash, err := cfg.incClient.CreateAndSendPdexv3WithdrawStakeRewardTransaction(
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createPdexv3WithdrawStakeRewardTx(
 		privateKey,
 		tokenID,
 		nftID,
+		fee,
 	)
 	if err != nil {
 		return newAppError(CreateDexStakingRewardWithdrawalTransactionError, err)
 	}
+	err = sendRawTx(encodedTx, true)
+	if err != nil {
+		return newAppError(CreateDexStakingRewardWithdrawalTransactionError, err)
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXGetShare returns the share amount of a pDEX nftID with-in a given poolID.
@@ -416,14 +536,14 @@ func pDEXGetShare(c *cli.Context) error {
 		return newAppError(GetPoolShareError, err)
 	}
 
-	return jsonPrintWithKey("Share", share)
+	return printResultWithKey("Share", share)
 }
 
 // pDEXWithdrawLPFee creates a transaction withdrawing the LP fees for an nftID from the pDEX.
 func pDEXWithdrawLPFee(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	nftID := c.String(nftIDFlag)
@@ -441,16 +561,26 @@ func pDEXWithdrawLPFee(c *cli.Context) error {
 		return newAppError(CreateLPFeeWithdrawalTransactionError, fmt.Errorf("not enough reward to withdraw"))
 	}
 
-	txHash, err := cfg.incClient.CreateAndSendPdexv3WithdrawLPFeeTransaction(
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, txHash, err := createPdexv3WithdrawLPFeeTx(
 		privateKey,
 		pairID,
 		nftID,
+		fee,
 	)
 	if err != nil {
 		return newAppError(CreateLPFeeWithdrawalTransactionError, err)
 	}
+	err = sendRawTx(encodedTx, true)
+	if err != nil {
+		return newAppError(CreateLPFeeWithdrawalTransactionError, err)
+	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // pDEXGetEstimatedLPValue returns the estimated LP values of an LP in a given pool.
@@ -466,22 +596,25 @@ func pDEXGetEstimatedLPValue(c *cli.Context) error {
 		return newAppError(GetEstimatedLPValueError, err)
 	}
 
-	return jsonPrint(res)
+	return printResult(res)
 }
 
 // pDEXFindPath finds a proper trading path.
 func pDEXFindPath(c *cli.Context) error {
-	tokenIdToSell := c.String(tokenIDToSellFlag)
-	if !isValidTokenID(tokenIdToSell) {
-		return newAppError(InvalidSellTokenIDError)
+	tokenIdToSell, err := getTokenIDFlag(c, tokenIDToSellFlag)
+	if err != nil {
+		return newAppError(InvalidSellTokenIDError, err)
 	}
 
-	tokenIdToBuy := c.String(tokenIDToBuyFlag)
-	if !isValidTokenID(tokenIdToBuy) {
-		return newAppError(InvalidBuyTokenIDError)
+	tokenIdToBuy, err := getTokenIDFlag(c, tokenIDToBuyFlag)
+	if err != nil {
+		return newAppError(InvalidBuyTokenIDError, err)
 	}
 
-	sellingAmount := c.Uint64(sellingAmountFlag)
+	sellingAmount, err := getAmountFlag(c, sellingAmountFlag, tokenIdToSell)
+	if err != nil {
+		return newAppError(InvalidSellAmountError, err)
+	}
 	if sellingAmount == 0 {
 		return newAppError(InvalidSellAmountError)
 	}
@@ -501,33 +634,56 @@ func pDEXFindPath(c *cli.Context) error {
 			fmt.Errorf("no trading path is found for the pair %v-%v with maxPaths = %v", tokenIdToSell, tokenIdToBuy, maxPaths))
 	}
 
-	return jsonPrint(map[string]interface{}{"MaxReceived": maxReceived, "TradingPath": tradingPath})
+	return printResult(map[string]interface{}{"MaxReceived": maxReceived, "TradingPath": tradingPath})
 }
 
 // pDEXCheckPrice checks the price of two tokenIds.
 func pDEXCheckPrice(c *cli.Context) error {
-	var err error
-	tokenIdToSell := c.String(tokenIDToSellFlag)
-	if !isValidTokenID(tokenIdToSell) {
-		return newAppError(InvalidSellTokenIDError)
+	tokenIdToSell, err := getTokenIDFlag(c, tokenIDToSellFlag)
+	if err != nil {
+		return newAppError(InvalidSellTokenIDError, err)
 	}
 
-	tokenIdToBuy := c.String(tokenIDToBuyFlag)
-	if !isValidTokenID(tokenIdToBuy) {
-		return newAppError(InvalidBuyTokenIDError)
+	tokenIdToBuy, err := getTokenIDFlag(c, tokenIDToBuyFlag)
+	if err != nil {
+		return newAppError(InvalidBuyTokenIDError, err)
 	}
 
-	sellingAmount := c.Uint64(sellingAmountFlag)
+	sellingAmount, err := getAmountFlag(c, sellingAmountFlag, tokenIdToSell)
+	if err != nil {
+		return newAppError(InvalidSellAmountError, err)
+	}
 	if sellingAmount == 0 {
 		return newAppError(InvalidSellAmountError)
 	}
 
-	pairID := c.String(pairIDFlag)
+	var quote pdexQuoteResult
+	params := pdexQuoteParams{TokenToSell: tokenIdToSell, TokenToBuy: tokenIdToBuy, SellingAmount: sellingAmount,
+		PairID: c.String(pairIDFlag)}
+	if ok, err := tryDaemon("pdexQuote", params, &quote); ok {
+		if err != nil {
+			return err
+		}
+		return printResult(map[string]interface{}{"BestPairID": quote.BestPairID, "BestReceived": quote.BestReceived})
+	}
+
+	pairID, bestExpectedReceive, err := checkBestPrice(tokenIdToSell, tokenIdToBuy, sellingAmount, c.String(pairIDFlag))
+	if err != nil {
+		return err
+	}
+
+	return printResult(map[string]interface{}{"BestPairID": pairID, "BestReceived": bestExpectedReceive})
+}
+
+// checkBestPrice returns the amount received by selling an amount of a token in the given pool pair. If no pair is
+// given, all the pool pairs of the two tokens are checked and the best one is returned.
+func checkBestPrice(tokenIdToSell, tokenIdToBuy string, sellingAmount uint64, pairID string) (string, uint64, error) {
+	var err error
 	bestExpectedReceive := uint64(0)
-	if pairID != "" {
+	if pairID == "" {
 		pairs, err := cfg.incClient.GetPdexPoolPair(0, tokenIdToSell, tokenIdToBuy)
 		if err != nil {
-			return newAppError(GetDexPoolPairError, err)
+			return "", 0, newAppError(GetDexPoolPairError, err)
 		}
 		for path := range pairs {
 			expectedPrice, err := cfg.incClient.CheckPrice(path, tokenIdToSell, sellingAmount)
@@ -542,27 +698,27 @@ func pDEXCheckPrice(c *cli.Context) error {
 	} else {
 		bestExpectedReceive, err = cfg.incClient.CheckPrice(pairID, tokenIdToSell, sellingAmount)
 		if err != nil {
-			return newAppError(DexPriceCheckingError, err)
+			return "", 0, newAppError(DexPriceCheckingError, err)
 		}
 	}
 
 	if bestExpectedReceive == 0 {
-		return newAppError(DexPriceCheckingError, fmt.Errorf("cannot find a proper path"))
+		return "", 0, newAppError(DexPriceCheckingError, fmt.Errorf("cannot find a proper path"))
 	}
 
-	return jsonPrint(map[string]interface{}{"BestPairID": pairID, "BestReceived": bestExpectedReceive})
+	return pairID, bestExpectedReceive, nil
 }
 
 // pDEXGetAllNFTs returns the list of NFTs for a given private key.
 func pDEXGetAllNFTs(c *cli.Context) error {
-	err := initNetWork()
+	err := defaultBeforeFunc(c)
 	if err != nil {
 		return err
 	}
 
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	allNFTs, err := cfg.incClient.GetMyNFTs(privateKey)
@@ -570,12 +726,12 @@ func pDEXGetAllNFTs(c *cli.Context) error {
 		return newAppError(GetAllDexNFTsError, err)
 	}
 
-	return jsonPrint(allNFTs)
+	return printResult(allNFTs)
 }
 
 // pDEXGetOrderByID returns the detail of an order given its id.
 func pDEXGetOrderByID(c *cli.Context) error {
-	err := initNetWork()
+	err := defaultBeforeFunc(c)
 	if err != nil {
 		return err
 	}
@@ -590,5 +746,5 @@ func pDEXGetOrderByID(c *cli.Context) error {
 		return newAppError(GetOrderByIDError, err)
 	}
 
-	return jsonPrint(order)
+	return printResult(order)
 }
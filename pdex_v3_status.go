@@ -15,7 +15,7 @@ func pDEXTradeStatus(c *cli.Context) error {
 		return newAppError(GetTradeStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXContributionStatus retrieves the status of a pDEX liquidity contribution.
@@ -26,7 +26,7 @@ func pDEXContributionStatus(c *cli.Context) error {
 		return newAppError(GetDexContributionStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXOrderAddingStatus retrieves the status of an order-book adding transaction.
@@ -40,7 +40,7 @@ func pDEXOrderAddingStatus(c *cli.Context) error {
 		return newAppError(GetOrderAddingStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXWithdrawalStatus retrieves the status of a pDEX liquidity withdrawal.
@@ -54,7 +54,7 @@ func pDEXWithdrawalStatus(c *cli.Context) error {
 		return newAppError(GetDexWithdrawalStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXOrderWithdrawalStatus retrieves the status of an order-book withdrawal.
@@ -68,7 +68,7 @@ func pDEXOrderWithdrawalStatus(c *cli.Context) error {
 		return newAppError(GetOrderWithdrawalStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXStakingStatus retrieves the status of a staking transaction.
@@ -79,7 +79,7 @@ func pDEXStakingStatus(c *cli.Context) error {
 		return newAppError(GetDexStakingStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXUnStakingStatus retrieves the status of a pDEX un-staking transaction.
@@ -93,7 +93,7 @@ func pDEXUnStakingStatus(c *cli.Context) error {
 		return newAppError(GetDexUnStakingStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXWithdrawStakingRewardStatus retrieves the status of a pDEX staking reward withdrawal transaction.
@@ -104,7 +104,7 @@ func pDEXWithdrawStakingRewardStatus(c *cli.Context) error {
 		return newAppError(GetDexStakingRewardWithdrawalStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXWithdrawLPFeeStatus retrieves the status of a pDEX LP fee withdrawal transaction.
@@ -118,7 +118,7 @@ func pDEXWithdrawLPFeeStatus(c *cli.Context) error {
 		return newAppError(GetLPFeeWithdrawalStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // pDEXMintNFTStatus gets the status of a pDEx NFT minting transaction.
@@ -132,5 +132,5 @@ func pDEXMintNFTStatus(c *cli.Context) error {
 		return newAppError(GetNFTMintingStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
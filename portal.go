@@ -13,9 +13,9 @@ func getPortalDepositAddress(c *cli.Context) error {
 		return newAppError(InvalidPaymentAddressError)
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	shieldAddress, err := cfg.incClient.GeneratePortalShieldingAddress(address, tokenIDStr)
@@ -23,18 +23,19 @@ func getPortalDepositAddress(c *cli.Context) error {
 		return newAppError(GenerateShieldingAddressError, err)
 	}
 
-	return jsonPrintWithKey("ShieldAddress", shieldAddress)
+	return printResultWithKey("ShieldAddress", shieldAddress)
 }
 
 // portalShield deposits a portal token (e.g, BTC) into the Incognito chain.
 func portalShield(c *cli.Context) error {
-	if cfg.btcClient == nil {
-		return newAppError(BTCClientNotFoundError)
+	btcClient, err := cfg.getBTCClient()
+	if err != nil {
+		return newAppError(BTCClientNotFoundError, err)
 	}
 
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	address := c.String(addressFlag)
@@ -47,13 +48,13 @@ func portalShield(c *cli.Context) error {
 
 	portalTxHashStr := c.String(externalTxIDFlag)
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	// check if the transaction has enough confirmations.
-	isConfirmed, blkHeight, err := cfg.btcClient.IsConfirmedTx(portalTxHashStr)
+	isConfirmed, blkHeight, err := btcClient.IsConfirmedTx(portalTxHashStr)
 	if err != nil {
 		return newAppError(GetBTCConfirmationError, err)
 	}
@@ -62,7 +63,7 @@ func portalShield(c *cli.Context) error {
 	}
 
 	// generate the shielding proof.
-	shieldingProof, err := cfg.btcClient.BuildProof(portalTxHashStr, blkHeight)
+	shieldingProof, err := btcClient.BuildProof(portalTxHashStr, blkHeight)
 	if err != nil {
 		return newAppError(BuildBTCProofError, err)
 	}
@@ -73,7 +74,7 @@ func portalShield(c *cli.Context) error {
 		return newAppError(CreatePortalShieldingTransactionError, err)
 	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // getPortalShieldStatus returns the status of a portal shielding request.
@@ -88,22 +89,25 @@ func getPortalShieldStatus(c *cli.Context) error {
 		return newAppError(GetPortalShieldingStatusError)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
 
 // portalUnShield creates and sends a port un-shielding transaction.
 func portalUnShield(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
-	unShieldAmount := c.Uint64(amountFlag)
+	unShieldAmount, err := getAmountFlag(c, amountFlag, tokenIDStr)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
 	if unShieldAmount == 0 {
 		return newAppError(InvalidAmountError)
 	}
@@ -126,11 +130,10 @@ func portalUnShield(c *cli.Context) error {
 		return newAppError(CreatePortalUnShieldingTransactionError, err)
 	}
 
-	log.Printf("TxHash: %v\n", txHash)
 	log.Println("Please wait for ~ 30-60 minutes for the fund to be released!!")
 	log.Println("Use command `portalunshieldstatus` to check the status of the request.")
 
-	return nil
+	return printTxHash(txHash)
 }
 
 // getPortalUnShieldStatus returns the status of a portal un-shielding request.
@@ -145,5 +148,5 @@ func getPortalUnShieldStatus(c *cli.Context) error {
 		return newAppError(GetPortalUnShieldingStatusError, err)
 	}
 
-	return jsonPrint(status)
+	return printResult(status)
 }
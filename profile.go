@@ -0,0 +1,491 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	configFileName = "config.yaml"
+
+	btcBlockCypherBackend = "blockcypher"
+	btcRPCBackend         = "rpc"
+)
+
+// profileKeys lists all keys that can be set in a profile. The `<evm>` part of a key is one of ETH, BSC, PLG, FTM.
+var profileKeys = []string{
+	"network",
+	"host",
+	"clientVersion",
+	"utxoCache",
+	"fee",
+	"evm.<evm>.host",
+	"evm.<evm>.vault",
+	"btc.backend",
+	"btc.host",
+	"btc.user",
+	"btc.password",
+	"btc.disableTLS",
+}
+
+// maskedSecret replaces the secrets of a profile when it is printed.
+const maskedSecret = "********"
+
+// btcBackendConfig specifies how the CLI retrieves information from the BTC network.
+type btcBackendConfig struct {
+	// Backend is either blockcypher (default) or rpc (a BTC full-node).
+	Backend    string `yaml:"backend,omitempty" json:"backend,omitempty"`
+	Host       string `yaml:"host,omitempty" json:"host,omitempty"`
+	User       string `yaml:"user,omitempty" json:"user,omitempty"`
+	Password   string `yaml:"password,omitempty" json:"password,omitempty"`
+	DisableTLS bool   `yaml:"disableTLS,omitempty" json:"disableTLS,omitempty"`
+}
+
+// cliProfile is a named set of settings used in place of the global flags. Empty fields fall back to the flags' defaults.
+type cliProfile struct {
+	Network       string                  `yaml:"network,omitempty" json:"network,omitempty"`
+	Host          string                  `yaml:"host,omitempty" json:"host,omitempty"`
+	ClientVersion int                     `yaml:"clientVersion,omitempty" json:"clientVersion,omitempty"`
+	UTXOCache     *bool                   `yaml:"utxoCache,omitempty" json:"utxoCache,omitempty"`
+	Fee           uint64                  `yaml:"fee,omitempty" json:"fee,omitempty"`
+	EVM           map[string]*evmEndpoint `yaml:"evm,omitempty" json:"evm,omitempty"`
+	BTC           *btcBackendConfig       `yaml:"btc,omitempty" json:"btc,omitempty"`
+}
+
+// cliConfig represents the CLI config file.
+type cliConfig struct {
+	ActiveProfile string                 `yaml:"activeProfile,omitempty"`
+	Profiles      map[string]*cliProfile `yaml:"profiles,omitempty"`
+
+	path string
+}
+
+// loadCLIConfig loads the CLI config from the CLI home directory. If the config file does not exist,
+// an empty config is returned.
+func loadCLIConfig() (*cliConfig, error) {
+	homeDir, err := cliHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return loadCLIConfigFromFile(filepath.Join(homeDir, configFileName))
+}
+
+// loadCLIConfigFromFile loads the CLI config from the given file path.
+func loadCLIConfigFromFile(path string) (*cliConfig, error) {
+	conf := &cliConfig{Profiles: make(map[string]*cliProfile), path: path}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return conf, nil
+		}
+		return nil, err
+	}
+
+	err = yaml.Unmarshal(data, conf)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse config file %v: %v", path, err)
+	}
+	if conf.Profiles == nil {
+		conf.Profiles = make(map[string]*cliProfile)
+	}
+	conf.path = path
+
+	return conf, nil
+}
+
+// save writes the CLI config to its file. The file may contain credentials (e.g, of a BTC full-node),
+// so it is only readable by the current user.
+func (conf *cliConfig) save() error {
+	err := os.MkdirAll(filepath.Dir(conf.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(conf)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(conf.path, data, 0600)
+}
+
+// getProfile returns the profile with the given name. If name is empty, the active profile is returned.
+func (conf *cliConfig) getProfile(name string) (*cliProfile, error) {
+	if name == "" {
+		name = conf.ActiveProfile
+	}
+	if name == "" {
+		return nil, fmt.Errorf("no profile specified and no active profile set")
+	}
+	p, ok := conf.Profiles[name]
+	if !ok || p == nil {
+		return nil, fmt.Errorf("profile %v not found", name)
+	}
+
+	return p, nil
+}
+
+// parseEVMKey parses a key of the form evm.<evm>.<field> and returns the (upper-case) EVM network name and the field.
+func parseEVMKey(key string) (string, string, error) {
+	parts := strings.Split(key, ".")
+	if len(parts) != 3 || parts[0] != "evm" {
+		return "", "", fmt.Errorf("invalid key %v", key)
+	}
+	evmNetwork := strings.ToUpper(parts[1])
+	if _, err := getEVMNetworkIDFromName(evmNetwork); err != nil {
+		return "", "", err
+	}
+	if parts[2] != "host" && parts[2] != "vault" {
+		return "", "", fmt.Errorf("invalid key %v", key)
+	}
+
+	return evmNetwork, parts[2], nil
+}
+
+// get returns the value of the given key in string form.
+func (p *cliProfile) get(key string) (string, error) {
+	switch key {
+	case "network":
+		return p.Network, nil
+	case "host":
+		return p.Host, nil
+	case "clientVersion":
+		if p.ClientVersion == 0 {
+			return "", nil
+		}
+		return fmt.Sprintf("%v", p.ClientVersion), nil
+	case "utxoCache":
+		if p.UTXOCache == nil {
+			return "", nil
+		}
+		return fmt.Sprintf("%v", *p.UTXOCache), nil
+	case "fee":
+		if p.Fee == 0 {
+			return "", nil
+		}
+		return fmt.Sprintf("%v", p.Fee), nil
+	}
+
+	if strings.HasPrefix(key, "btc.") {
+		btc := btcBackendConfig{}
+		if p.BTC != nil {
+			btc = *p.BTC
+		}
+		switch strings.TrimPrefix(key, "btc.") {
+		case "backend":
+			return btc.Backend, nil
+		case "host":
+			return btc.Host, nil
+		case "user":
+			return btc.User, nil
+		case "password":
+			return btc.Password, nil
+		case "disableTLS":
+			return fmt.Sprintf("%v", btc.DisableTLS), nil
+		}
+		return "", fmt.Errorf("invalid key %v", key)
+	}
+
+	evmNetwork, field, err := parseEVMKey(key)
+	if err != nil {
+		return "", err
+	}
+	endpoint, ok := p.EVM[evmNetwork]
+	if !ok || endpoint == nil {
+		return "", nil
+	}
+	if field == "host" {
+		return endpoint.Host, nil
+	}
+	return endpoint.Vault, nil
+}
+
+// set validates and sets the value of the given key. An empty value unsets the key.
+func (p *cliProfile) set(key, value string) error {
+	var err error
+	switch key {
+	case "network":
+		if _, ok := defaultEVMEndpoints[value]; !ok && value != "" {
+			return fmt.Errorf("expect network to be one of mainnet, testnet, testnet1, local; got %v", value)
+		}
+		p.Network = value
+		return nil
+	case "host":
+		p.Host = value
+		return nil
+	case "clientVersion":
+		if value == "" {
+			p.ClientVersion = 0
+			return nil
+		}
+		version, err := strconv.Atoi(value)
+		if err != nil || (version != 1 && version != 2) {
+			return fmt.Errorf("expect clientVersion to be either 1 or 2; got %v", value)
+		}
+		p.ClientVersion = version
+		return nil
+	case "utxoCache":
+		if value == "" {
+			p.UTXOCache = nil
+			return nil
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		p.UTXOCache = &enabled
+		return nil
+	case "fee":
+		if value == "" {
+			p.Fee = 0
+			return nil
+		}
+		p.Fee, err = strconv.ParseUint(value, 10, 64)
+		return err
+	}
+
+	if strings.HasPrefix(key, "btc.") {
+		if p.BTC == nil {
+			p.BTC = new(btcBackendConfig)
+		}
+		switch strings.TrimPrefix(key, "btc.") {
+		case "backend":
+			if value != "" && value != btcBlockCypherBackend && value != btcRPCBackend {
+				return fmt.Errorf("expect btc.backend to be either %v or %v; got %v", btcBlockCypherBackend, btcRPCBackend, value)
+			}
+			p.BTC.Backend = value
+		case "host":
+			p.BTC.Host = value
+		case "user":
+			p.BTC.User = value
+		case "password":
+			p.BTC.Password = value
+		case "disableTLS":
+			if value == "" {
+				p.BTC.DisableTLS = false
+				break
+			}
+			p.BTC.DisableTLS, err = strconv.ParseBool(value)
+			if err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("invalid key %v", key)
+		}
+		if *p.BTC == (btcBackendConfig{}) {
+			p.BTC = nil
+		}
+		return nil
+	}
+
+	evmNetwork, field, err := parseEVMKey(key)
+	if err != nil {
+		return err
+	}
+	if p.EVM == nil {
+		p.EVM = make(map[string]*evmEndpoint)
+	}
+	endpoint, ok := p.EVM[evmNetwork]
+	if !ok || endpoint == nil {
+		endpoint = new(evmEndpoint)
+	}
+	if field == "host" {
+		endpoint.Host = value
+	} else {
+		if value != "" && !isValidEVMAddress(value) {
+			return fmt.Errorf("invalid vault address %v", value)
+		}
+		endpoint.Vault = value
+	}
+	p.EVM[evmNetwork] = endpoint
+	if *endpoint == (evmEndpoint{}) {
+		delete(p.EVM, evmNetwork)
+	}
+
+	return nil
+}
+
+// isInvalidKeyError checks if an error returned by cliProfile.set is caused by the key rather than the value.
+func isInvalidKeyError(key string) bool {
+	_, err := new(cliProfile).get(key)
+	return err != nil
+}
+
+// applyProfile loads the selected profile (the global profile flag, or the active profile of the CLI config)
+// and uses its values for all global flags which have not been explicitly set. The config commands are skipped
+// so that they keep working when the selected profile does not exist yet.
+func applyProfile(c *cli.Context) error {
+	if c.Args().First() == "config" {
+		return nil
+	}
+
+	conf, err := loadCLIConfig()
+	if err != nil {
+		return newAppError(LoadConfigError, err)
+	}
+	if profileName == "" && conf.ActiveProfile == "" {
+		return nil
+	}
+	p, err := conf.getProfile(profileName)
+	if err != nil {
+		return newAppError(ProfileNotFoundError, err)
+	}
+
+	if p.Network != "" && !c.IsSet(networkFlag) {
+		network = p.Network
+	}
+	if p.Host != "" && !c.IsSet(hostFlag) {
+		host = p.Host
+	}
+	if p.ClientVersion != 0 && !c.IsSet(clientVersionFlag) {
+		clientVersion = p.ClientVersion
+	}
+	if p.UTXOCache != nil && !c.IsSet(cacheFlag) {
+		cache = 0
+		if *p.UTXOCache {
+			cache = 1
+		}
+	}
+	activeProfile = p
+
+	return nil
+}
+
+// configList lists all profiles of the CLI config.
+func configList(_ *cli.Context) error {
+	conf, err := loadCLIConfig()
+	if err != nil {
+		return newAppError(LoadConfigError, err)
+	}
+
+	names := make([]string, 0)
+	for name := range conf.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	type profileEntry struct {
+		Name     string
+		IsActive bool
+		Profile  *cliProfile
+	}
+	res := make([]profileEntry, 0)
+	for _, name := range names {
+		res = append(res, profileEntry{Name: name, IsActive: name == conf.ActiveProfile, Profile: maskSecrets(conf.Profiles[name])})
+	}
+
+	return printResult(res)
+}
+
+// maskSecrets returns a copy of a profile whose secrets (i.e, the BTC password) are masked, to be printed.
+func maskSecrets(p *cliProfile) *cliProfile {
+	res := *p
+	if res.BTC != nil && res.BTC.Password != "" {
+		btc := *res.BTC
+		btc.Password = maskedSecret
+		res.BTC = &btc
+	}
+
+	return &res
+}
+
+// configGet prints a key (or all keys if no key is given) of a profile.
+func configGet(c *cli.Context) error {
+	conf, err := loadCLIConfig()
+	if err != nil {
+		return newAppError(LoadConfigError, err)
+	}
+	p, err := conf.getProfile(profileName)
+	if err != nil {
+		return newAppError(ProfileNotFoundError, err)
+	}
+
+	reveal := c.Bool(revealFlag)
+	key := c.String(configKeyFlag)
+	if key == "" {
+		if !reveal {
+			p = maskSecrets(p)
+		}
+		return printResult(p)
+	}
+	value, err := p.get(key)
+	if err != nil {
+		return newAppError(InvalidConfigKeyError, err)
+	}
+	if key == "btc.password" && value != "" && !reveal {
+		value = maskedSecret
+	}
+
+	return printResultWithKey(key, value)
+}
+
+// configSet sets a key of a profile. The profile is created if it does not exist yet.
+func configSet(c *cli.Context) error {
+	conf, err := loadCLIConfig()
+	if err != nil {
+		return newAppError(LoadConfigError, err)
+	}
+
+	name := profileName
+	if name == "" {
+		name = conf.ActiveProfile
+	}
+	if name == "" {
+		return newAppError(ProfileNotFoundError, fmt.Errorf("no profile specified and no active profile set"))
+	}
+	p, ok := conf.Profiles[name]
+	if !ok || p == nil {
+		p = new(cliProfile)
+		conf.Profiles[name] = p
+	}
+
+	key := c.String(configKeyFlag)
+	err = p.set(key, c.String(configValueFlag))
+	if err != nil {
+		if isInvalidKeyError(key) {
+			return newAppError(InvalidConfigKeyError, err)
+		}
+		return newAppError(InvalidConfigValueError, err)
+	}
+	if conf.ActiveProfile == "" {
+		conf.ActiveProfile = name
+	}
+
+	err = conf.save()
+	if err != nil {
+		return newAppError(SaveConfigError, err)
+	}
+
+	return nil
+}
+
+// configUse sets the active profile of the CLI config.
+func configUse(c *cli.Context) error {
+	conf, err := loadCLIConfig()
+	if err != nil {
+		return newAppError(LoadConfigError, err)
+	}
+
+	name := c.String(profileNameFlag)
+	if _, err = conf.getProfile(name); err != nil {
+		return newAppError(ProfileNotFoundError, err)
+	}
+	conf.ActiveProfile = name
+
+	err = conf.save()
+	if err != nil {
+		return newAppError(SaveConfigError, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	// evmPrivateKeyEnv is the environment variable from which the EVM private key is read if the evmPrivateKeyFile
+	// flag is not set.
+	evmPrivateKeyEnv = "INCOGNITO_EVM_PRIVATE_KEY"
+
+	// keystorePassphraseEnv is the environment variable from which the passphrase of the keystore accounts is read if
+	// the passphraseFile flag is not set.
+	keystorePassphraseEnv = "INCOGNITO_KEYSTORE_PASSPHRASE"
+)
+
+// prompter abstracts away the interaction with the user so that commands can run without a terminal.
+type prompter interface {
+	// Confirm asks for a yes/no decision and returns true if the answer is yes.
+	Confirm(message string) (bool, error)
+
+	// Input asks for an input. If isSecret is true, the input is not echoed.
+	Input(message string, isSecret bool) (string, error)
+}
+
+// terminalPrompter reads the user's answers from the standard input. Prompts are written to the standard error.
+type terminalPrompter struct{}
+
+// Confirm implements the prompter interface.
+func (terminalPrompter) Confirm(message string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%v %v (y/n): ", time.Now().Format("2006/01/02 15:04:05"), message)
+
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+	input = parseInput(input)
+
+	return strings.Contains(input, "y") || strings.Contains(input, "Y"), nil
+}
+
+// Input implements the prompter interface.
+func (terminalPrompter) Input(message string, isSecret bool) (string, error) {
+	fmt.Fprintf(os.Stderr, "%v %v: ", time.Now().Format("2006/01/02 15:04:05"), message)
+
+	if isSecret {
+		input, err := terminal.ReadPassword(int(os.Stdin.Fd()))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintln(os.Stderr)
+		return string(input), nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return parseInput(input), nil
+}
+
+// nonInteractivePrompter answers yes to all confirmations and fails on all inputs. It is used when the global yes
+// flag is set, e.g. in scripts or scheduled jobs.
+type nonInteractivePrompter struct{}
+
+// Confirm implements the prompter interface.
+func (nonInteractivePrompter) Confirm(message string) (bool, error) {
+	log.Printf("%v (y/n): y (auto-confirmed)\n", message)
+	return true, nil
+}
+
+// Input implements the prompter interface.
+func (nonInteractivePrompter) Input(message string, _ bool) (string, error) {
+	return "", fmt.Errorf("cannot ask for input in non-interactive mode: %v", message)
+}
+
+// appPrompter is the prompter used by all commands.
+var appPrompter prompter = terminalPrompter{}
+
+// getEVMPrivateKey returns the EVM private key used in a bridge command. The key is read from the file given by the
+// evmPrivateKeyFile flag, the INCOGNITO_EVM_PRIVATE_KEY environment variable, or the user's input, in that order.
+func getEVMPrivateKey(c *cli.Context, evmNetwork string) (string, error) {
+	if keyFile := c.String(evmPrivateKeyFileFlag); keyFile != "" {
+		data, err := readSecretFile(keyFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(data), nil
+	}
+
+	if privateEVMKey := os.Getenv(evmPrivateKeyEnv); privateEVMKey != "" {
+		return strings.TrimSpace(privateEVMKey), nil
+	}
+
+	return appPrompter.Input(fmt.Sprintf("Enter your %v private key", evmNetwork), true)
+}
+
+// hasKeystorePassphrase returns true if the passphrase of the keystore accounts is given without the user's input.
+func hasKeystorePassphrase() bool {
+	return passphraseFile != "" || os.Getenv(keystorePassphraseEnv) != ""
+}
+
+// getKeystorePassphrase returns the passphrase of a keystore account. The passphrase is read from the file given by
+// the passphraseFile flag, the INCOGNITO_KEYSTORE_PASSPHRASE environment variable, or the user's input, in that order.
+func getKeystorePassphrase(name string) (string, error) {
+	if passphraseFile != "" {
+		data, err := readSecretFile(passphraseFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(data, "\r\n"), nil
+	}
+
+	if envPassphrase, ok := os.LookupEnv(keystorePassphraseEnv); ok && envPassphrase != "" {
+		return envPassphrase, nil
+	}
+
+	res, err := appPrompter.Input(fmt.Sprintf("Enter the passphrase of account `%v`", name), true)
+	if err != nil {
+		return "", fmt.Errorf("%v (use the %v flag or the %v environment variable to give it without a prompt)",
+			err, passphraseFlag, keystorePassphraseEnv)
+	}
+
+	return res, nil
+}
+
+// readSecretFile reads a file holding a secret, warning if other users can access it.
+func readSecretFile(file string) (string, error) {
+	if info, err := os.Stat(file); err == nil && info.Mode().Perm()&0077 != 0 {
+		log.Printf("WARNING: %v is accessible by other users, consider restricting its permissions to 0600\n", file)
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,26 @@
+package main
+
+// defaultMainnetTokens is the token list bundled with the CLI for the main-net, used until a token list is saved with
+// `token refresh`. It only holds the main bridged tokens; the full list is retrieved from the coinservice.
+var defaultMainnetTokens = []TokenInfo{
+	{
+		TokenID:   "b832e5d3b1f01a4f0623f7fe91d6673461e1f5d37d91fe78c5c2e6183ff39696",
+		Name:      "Bitcoin",
+		Symbol:    "BTC",
+		PSymbol:   "pBTC",
+		IsBridge:  true,
+		Verified:  true,
+		PDecimals: 9,
+		Network:   "BTC",
+	},
+	{
+		TokenID:   "ffd8d42dc40a8d166ea4848baf8b5f6e9fe0e9c30d60062eb7d44a8df9e00854",
+		Name:      "Ethereum",
+		Symbol:    "ETH",
+		PSymbol:   "pETH",
+		IsBridge:  true,
+		Verified:  true,
+		PDecimals: 9,
+		Network:   "ETH",
+	},
+}
@@ -0,0 +1,507 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	// tokenRegistryDir is the directory, under the CLI home directory, of the token list of each network.
+	tokenRegistryDir = "tokens"
+
+	// mainnetTokenListURL is the default source of the token list of the main-net.
+	mainnetTokenListURL = "https://api-coinservice.incognito.org/coins/tokenlist?all=true"
+)
+
+// TokenInfo describes a token of the registry, as listed by the coinservice.
+type TokenInfo struct {
+	TokenID   string `json:"TokenID"`
+	Name      string `json:"Name"`
+	Symbol    string `json:"Symbol"`
+	PSymbol   string `json:"PSymbol"`
+	IsBridge  bool   `json:"IsBridge"`
+	Verified  bool   `json:"Verified"`
+	PDecimals int    `json:"PDecimals"`
+	Network   string `json:"Network"`
+}
+
+// prvTokenInfo is built in the registry of every network.
+var prvTokenInfo = TokenInfo{
+	TokenID:   common.PRVIDStr,
+	Name:      "Privacy",
+	Symbol:    "PRV",
+	PSymbol:   "PRV",
+	Verified:  true,
+	PDecimals: 9,
+}
+
+// tokenRegistryFile is the content of the local token list file of a network.
+type tokenRegistryFile struct {
+	UpdatedAt time.Time
+	Source    string
+	Tokens    []TokenInfo
+}
+
+var (
+	// listTokenInfo holds the tokens of the registry by tokenID. It is loaded by getTokenRegistry on first use.
+	listTokenInfo    map[string]TokenInfo
+	tokenRegistryMtx sync.Mutex
+)
+
+// getTokenRegistryFile returns the path of the token list file of the current network.
+func getTokenRegistryFile() (string, error) {
+	homeDir, err := cliHomeDir()
+	if err != nil {
+		return "", err
+	}
+	networkName := cfg.network
+	if host != "" {
+		networkName = "custom"
+	}
+
+	return filepath.Join(homeDir, tokenRegistryDir, networkName+".json"), nil
+}
+
+// getTokenRegistry returns the tokens of the registry of the current network, by tokenID. The local token list file
+// is read once; without it, the registry holds PRV and, on the main-net, the bundled defaultMainnetTokens.
+func getTokenRegistry() map[string]TokenInfo {
+	tokenRegistryMtx.Lock()
+	defer tokenRegistryMtx.Unlock()
+	if listTokenInfo != nil {
+		return listTokenInfo
+	}
+
+	listTokenInfo = map[string]TokenInfo{common.PRVIDStr: prvTokenInfo}
+	if cfg == nil {
+		return listTokenInfo
+	}
+	for _, tokenInfo := range loadTokenRegistryFile() {
+		listTokenInfo[tokenInfo.TokenID] = tokenInfo
+	}
+
+	return listTokenInfo
+}
+
+// loadTokenRegistryFile returns the tokens of the local token list file of the current network, or the bundled
+// token list of the network if the file does not exist or cannot be read.
+func loadTokenRegistryFile() []TokenInfo {
+	var defaultTokens []TokenInfo
+	if cfg.network == "mainnet" && host == "" {
+		defaultTokens = defaultMainnetTokens
+	}
+
+	file, err := getTokenRegistryFile()
+	if err != nil {
+		return defaultTokens
+	}
+	if _, err = os.Stat(file); os.IsNotExist(err) {
+		return defaultTokens
+	}
+	var registry tokenRegistryFile
+	if err = readJSONFile(file, &registry); err != nil {
+		log.Printf("Cannot read the token list %v: %v\n", file, err)
+		return defaultTokens
+	}
+
+	return registry.Tokens
+}
+
+// parseTokenList parses a token list either in the format of the coinservice ({"Result": [...]}), in that of the
+// local token list file, or as a plain JSON array.
+func parseTokenList(data []byte) ([]TokenInfo, error) {
+	var list []TokenInfo
+	if err := json.Unmarshal(data, &list); err == nil {
+		return list, nil
+	}
+
+	var tmpRes struct {
+		Result []TokenInfo `json:"Result"`
+		Tokens []TokenInfo `json:"Tokens"`
+		Error  interface{} `json:"Error"`
+	}
+	if err := json.Unmarshal(data, &tmpRes); err != nil {
+		return nil, err
+	}
+	if tmpRes.Error != nil && tmpRes.Error != "" {
+		return nil, fmt.Errorf("retrieving token info encountered an error: %v", tmpRes.Error)
+	}
+	if tmpRes.Result != nil {
+		return tmpRes.Result, nil
+	}
+	if tmpRes.Tokens != nil {
+		return tmpRes.Tokens, nil
+	}
+
+	return nil, fmt.Errorf("no token found")
+}
+
+// fetchTokenList retrieves a token list from a URL.
+func fetchTokenList(url string) ([]TokenInfo, error) {
+	httpClient := &http.Client{Timeout: time.Minute}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err := resp.Body.Close()
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%v returned %v", url, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseTokenList(body)
+}
+
+// isFullTokenID checks if a string is a tokenID written with all its 64 hex characters. Unlike isValidTokenID, it
+// rejects the shorter hex strings that could also be token symbols (e.g, ADA).
+func isFullTokenID(tokenIDStr string) bool {
+	return len(tokenIDStr) == common.MaxHashStringSize && isValidTokenID(tokenIDStr)
+}
+
+// resolveTokenID returns the tokenID of a token given by its tokenID or by the symbol of a verified token of the
+// registry (e.g, PRV, USDT). A symbol shared by tokens of several networks is either suffixed by the network
+// (e.g, USDT:BSC), or resolved to the only one without a network (i.e, the unified token).
+func resolveTokenID(token string) (string, error) {
+	if token == "" || isFullTokenID(token) {
+		return token, nil
+	}
+
+	symbol, tokenNetwork := token, ""
+	if i := strings.LastIndex(token, ":"); i > 0 {
+		symbol, tokenNetwork = token[:i], token[i+1:]
+	}
+	candidates := make([]TokenInfo, 0)
+	for _, tokenInfo := range getTokenRegistry() {
+		if !tokenInfo.Verified {
+			continue
+		}
+		if !strings.EqualFold(tokenInfo.Symbol, symbol) && !strings.EqualFold(tokenInfo.PSymbol, symbol) {
+			continue
+		}
+		if tokenNetwork != "" && !strings.EqualFold(tokenInfo.Network, tokenNetwork) {
+			continue
+		}
+		candidates = append(candidates, tokenInfo)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", fmt.Errorf("%q is neither a tokenID nor the symbol of a verified token (see the `token search` command)", token)
+	case 1:
+		return candidates[0].TokenID, nil
+	}
+
+	noNetwork := make([]TokenInfo, 0)
+	descriptions := make([]string, 0)
+	for _, tokenInfo := range candidates {
+		if tokenInfo.Network == "" {
+			noNetwork = append(noNetwork, tokenInfo)
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%v:%v (%v)", tokenInfo.Symbol, tokenInfo.Network, tokenInfo.TokenID))
+	}
+	if len(noNetwork) == 1 {
+		return noNetwork[0].TokenID, nil
+	}
+	sort.Strings(descriptions)
+
+	return "", fmt.Errorf("symbol %q is ambiguous, use one of %v", token, strings.Join(descriptions, ", "))
+}
+
+// getTokenIDFlag returns the tokenID of a flag given either as a tokenID or as a symbol (see resolveTokenID).
+func getTokenIDFlag(c *cli.Context, flag string) (string, error) {
+	tokenIDStr, err := resolveTokenID(c.String(flag))
+	if err != nil {
+		return "", err
+	}
+	if !isValidTokenID(tokenIDStr) {
+		return "", fmt.Errorf("invalid %v %q", flag, c.String(flag))
+	}
+
+	return tokenIDStr, nil
+}
+
+func getTokenName(tokenID string) string {
+	tokenInfo, ok := getTokenRegistry()[tokenID]
+	if !ok || !tokenInfo.Verified {
+		return tokenID
+	}
+
+	res := tokenInfo.Symbol
+	if tokenInfo.Network != "" {
+		res = fmt.Sprintf("%v (%v)", res, tokenInfo.Network)
+	}
+
+	return res
+}
+
+// getTokenSymbol returns the symbol of a verified token, or its tokenID otherwise.
+func getTokenSymbol(tokenID string) string {
+	if tokenInfo, ok := getTokenRegistry()[tokenID]; ok && tokenInfo.Verified && tokenInfo.Symbol != "" {
+		return tokenInfo.Symbol
+	}
+
+	return tokenID
+}
+
+func getTokenDecimals(tokenID string) int {
+	if tokenInfo, ok := getTokenRegistry()[tokenID]; ok && tokenInfo.Verified {
+		return tokenInfo.PDecimals
+	}
+
+	return 0
+}
+
+// formatAmount formats an amount in the smallest unit of a token as an exact decimal number with the given number of
+// decimals, without trailing zeros (e.g, 1234500 with 6 decimals gives "1.2345").
+func formatAmount(amount uint64, decimals int) string {
+	res := fmt.Sprintf("%v", amount)
+	if decimals <= 0 {
+		return res
+	}
+	if len(res) <= decimals {
+		res = strings.Repeat("0", decimals-len(res)+1) + res
+	}
+
+	intPart, fracPart := res[:len(res)-decimals], strings.TrimRight(res[len(res)-decimals:], "0")
+	if fracPart == "" {
+		return intPart
+	}
+
+	return intPart + "." + fracPart
+}
+
+// formatTokenAmount formats an amount of a token with its decimals and symbol (e.g, "1.5 PRV").
+func formatTokenAmount(amount uint64, tokenIDStr string) string {
+	return fmt.Sprintf("%v %v", formatAmount(amount, getTokenDecimals(tokenIDStr)), getTokenSymbol(tokenIDStr))
+}
+
+// parseAmount parses an amount of a token. An integer is an amount in the smallest unit of the token (e.g, 1500000000
+// for 1.5 PRV); an amount with a decimal point or followed by the symbol of the token is a number of tokens, converted
+// with the decimals of the token (e.g, 1.5, 1.5PRV or "1.5 PRV"). Without a tokenID, only integers are accepted.
+func parseAmount(amountStr, tokenIDStr string) (uint64, error) {
+	s := strings.TrimSpace(amountStr)
+	if s == "" {
+		return 0, nil
+	}
+
+	inTokens := false
+	if tokenIDStr != "" {
+		symbol := getTokenSymbol(tokenIDStr)
+		if symbol != tokenIDStr && len(s) > len(symbol) && strings.EqualFold(s[len(s)-len(symbol):], symbol) {
+			s, inTokens = strings.TrimSpace(s[:len(s)-len(symbol)]), true
+		}
+	}
+	intPart, fracPart := s, ""
+	if i := strings.Index(s, "."); i >= 0 {
+		intPart, fracPart, inTokens = s[:i], s[i+1:], true
+	}
+	if inTokens && tokenIDStr == "" {
+		return 0, fmt.Errorf("expected an integer amount, got %q", amountStr)
+	}
+	if intPart == "" {
+		intPart = "0"
+	}
+	for _, part := range []string{intPart, fracPart} {
+		if strings.TrimLeft(part, "0123456789") != "" {
+			return 0, fmt.Errorf("invalid amount %q", amountStr)
+		}
+	}
+
+	digits := intPart
+	if inTokens {
+		decimals := getTokenDecimals(tokenIDStr)
+		fracPart = strings.TrimRight(fracPart, "0")
+		if len(fracPart) > decimals {
+			return 0, fmt.Errorf("amount %q has more than the %v decimals of %v", amountStr, decimals, getTokenName(tokenIDStr))
+		}
+		digits += fracPart + strings.Repeat("0", decimals-len(fracPart))
+	}
+	res, ok := new(big.Int).SetString(digits, 10)
+	if !ok || !res.IsUint64() {
+		return 0, fmt.Errorf("amount %q is out of range", amountStr)
+	}
+
+	return res.Uint64(), nil
+}
+
+// getAmountFlag returns the amount of a token given by a flag (see parseAmount).
+func getAmountFlag(c *cli.Context, flag, tokenIDStr string) (uint64, error) {
+	amount, err := parseAmount(c.String(flag), tokenIDStr)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %v", flag, err)
+	}
+
+	return amount, nil
+}
+
+// tokenListItem is a token as printed by the token commands.
+type tokenListItem struct {
+	TokenID  string
+	Symbol   string
+	Name     string
+	Network  string `json:",omitempty"`
+	Decimals int
+	Verified bool
+}
+
+func newTokenListItem(tokenInfo TokenInfo) tokenListItem {
+	return tokenListItem{
+		TokenID:  tokenInfo.TokenID,
+		Symbol:   tokenInfo.Symbol,
+		Name:     tokenInfo.Name,
+		Network:  tokenInfo.Network,
+		Decimals: tokenInfo.PDecimals,
+		Verified: tokenInfo.Verified,
+	}
+}
+
+// sortedTokenListItems returns the tokens of the registry accepted by a filter, the verified ones first, then by
+// symbol.
+func sortedTokenListItems(accept func(TokenInfo) bool) []tokenListItem {
+	res := make([]tokenListItem, 0)
+	for _, tokenInfo := range getTokenRegistry() {
+		if accept(tokenInfo) {
+			res = append(res, newTokenListItem(tokenInfo))
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Verified != res[j].Verified {
+			return res[i].Verified
+		}
+		if !strings.EqualFold(res[i].Symbol, res[j].Symbol) {
+			return strings.ToUpper(res[i].Symbol) < strings.ToUpper(res[j].Symbol)
+		}
+		return res[i].TokenID < res[j].TokenID
+	})
+
+	return res
+}
+
+func tokenList(c *cli.Context) error {
+	verifiedOnly := c.Bool(verifiedOnlyFlag)
+
+	return printResult(sortedTokenListItems(func(tokenInfo TokenInfo) bool {
+		return tokenInfo.Verified || !verifiedOnly
+	}))
+}
+
+func tokenSearch(c *cli.Context) error {
+	query := strings.ToLower(strings.TrimSpace(c.String(queryFlag)))
+	if query == "" {
+		return newAppError(UserInputError, fmt.Errorf("%v must not be empty", queryFlag))
+	}
+	verifiedOnly := c.Bool(verifiedOnlyFlag)
+
+	return printResult(sortedTokenListItems(func(tokenInfo TokenInfo) bool {
+		if verifiedOnly && !tokenInfo.Verified {
+			return false
+		}
+		return strings.HasPrefix(tokenInfo.TokenID, query) ||
+			strings.Contains(strings.ToLower(tokenInfo.Symbol), query) ||
+			strings.Contains(strings.ToLower(tokenInfo.PSymbol), query) ||
+			strings.Contains(strings.ToLower(tokenInfo.Name), query)
+	}))
+}
+
+func tokenInfo(c *cli.Context) error {
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	tokenInfo, ok := getTokenRegistry()[tokenIDStr]
+	if !ok {
+		return newAppError(TokenNotFoundError, fmt.Errorf("tokenID %v is not in the token list, try `token refresh`", tokenIDStr))
+	}
+
+	return printResult(newTokenListItem(tokenInfo))
+}
+
+func tokenRefresh(c *cli.Context) error {
+	listFile, url := c.String(tokenListFileFlag), c.String(tokenListURLFlag)
+	if listFile != "" && url != "" {
+		return newAppError(UserInputError, fmt.Errorf("expected either %v or %v, not both", tokenListFileFlag, tokenListURLFlag))
+	}
+	if listFile == "" && url == "" {
+		if cfg.network != "mainnet" || host != "" {
+			return newAppError(UserInputError, fmt.Errorf("no default token list for this network, use %v or %v",
+				tokenListURLFlag, tokenListFileFlag))
+		}
+		url = mainnetTokenListURL
+	}
+
+	var tokens []TokenInfo
+	source := url
+	if listFile != "" {
+		data, err := ioutil.ReadFile(listFile)
+		if err != nil {
+			return newAppError(GetTokenListError, err)
+		}
+		tokens, err = parseTokenList(data)
+		if err != nil {
+			return newAppError(GetTokenListError, fmt.Errorf("cannot parse %v: %v", listFile, err))
+		}
+		source, _ = filepath.Abs(listFile)
+	} else {
+		log.Printf("Retrieving the token list from %v\n", url)
+		var err error
+		tokens, err = fetchTokenList(url)
+		if err != nil {
+			return newAppError(GetTokenListError, err)
+		}
+	}
+
+	registry := tokenRegistryFile{UpdatedAt: time.Now().UTC(), Source: source, Tokens: make([]TokenInfo, 0)}
+	numVerified := 0
+	for _, tokenInfo := range tokens {
+		if !isFullTokenID(tokenInfo.TokenID) {
+			continue
+		}
+		if tokenInfo.Verified {
+			numVerified++
+		}
+		registry.Tokens = append(registry.Tokens, tokenInfo)
+	}
+	if len(registry.Tokens) == 0 {
+		return newAppError(GetTokenListError, fmt.Errorf("the token list is empty"))
+	}
+	sort.Slice(registry.Tokens, func(i, j int) bool { return registry.Tokens[i].TokenID < registry.Tokens[j].TokenID })
+
+	file, err := getTokenRegistryFile()
+	if err != nil {
+		return newAppError(TokenRegistryError, err)
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return newAppError(TokenRegistryError, err)
+	}
+	if err = writeJSONFile(file, registry); err != nil {
+		return newAppError(TokenRegistryError, err)
+	}
+
+	return printResult(struct {
+		File        string
+		NumTokens   int
+		NumVerified int
+	}{file, len(registry.Tokens), numVerified})
+}
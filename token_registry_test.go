@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+)
+
+// setTestTokenRegistry replaces the token registry with PRV and the given tokens until the returned function is called.
+func setTestTokenRegistry(tokens ...TokenInfo) func() {
+	listTokenInfo = map[string]TokenInfo{common.PRVIDStr: prvTokenInfo}
+	for _, tokenInfo := range tokens {
+		listTokenInfo[tokenInfo.TokenID] = tokenInfo
+	}
+
+	return func() { listTokenInfo = nil }
+}
+
+func TestResolveTokenID(t *testing.T) {
+	usdt := strings.Repeat("01", 32)
+	usdtETH := strings.Repeat("02", 32)
+	usdtBSC := strings.Repeat("03", 32)
+	fake := strings.Repeat("04", 32)
+	btc1 := strings.Repeat("05", 32)
+	btc2 := strings.Repeat("06", 32)
+	ada := strings.Repeat("07", 32)
+	defer setTestTokenRegistry(
+		TokenInfo{TokenID: usdt, Symbol: "USDT", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: usdtETH, Symbol: "USDT", PSymbol: "pUSDT", Network: "ETH", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: usdtBSC, Symbol: "USDT", Network: "BSC", Verified: true, PDecimals: 6},
+		TokenInfo{TokenID: fake, Symbol: "FAKE", PDecimals: 9},
+		TokenInfo{TokenID: btc1, Symbol: "BTC", Network: "BTC", Verified: true, PDecimals: 9},
+		TokenInfo{TokenID: btc2, Symbol: "BTC", Network: "BSC", Verified: true, PDecimals: 9},
+		TokenInfo{TokenID: ada, Symbol: "ADA", Verified: true, PDecimals: 6},
+	)()
+
+	testCases := []struct {
+		token    string
+		expected string
+		isValid  bool
+	}{
+		{"", "", true},
+		{common.PRVIDStr, common.PRVIDStr, true},
+		{fake, fake, true},
+		{"prv", common.PRVIDStr, true},
+		{"USDT", usdt, true},
+		{"usdt:bsc", usdtBSC, true},
+		{"pUSDT", usdtETH, true},
+		{"BTC:BTC", btc1, true},
+		{"ada", ada, true},
+		{"BTC", "", false},
+		{"FAKE", "", false},
+		{"USDT:SOL", "", false},
+		{"unknown", "", false},
+	}
+
+	for _, tc := range testCases {
+		res, err := resolveTokenID(tc.token)
+		if tc.isValid != (err == nil) || res != tc.expected {
+			t.Fatalf("resolveTokenID(%q): expect (%v, valid=%v), got (%v, %v)", tc.token, tc.expected, tc.isValid, res, err)
+		}
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	usdt := strings.Repeat("01", 32)
+	unknown := strings.Repeat("02", 32)
+	defer setTestTokenRegistry(TokenInfo{TokenID: usdt, Symbol: "USDT", Verified: true, PDecimals: 6})()
+
+	testCases := []struct {
+		amount   string
+		tokenID  string
+		expected uint64
+		isValid  bool
+	}{
+		{"", common.PRVIDStr, 0, true},
+		{"1000", common.PRVIDStr, 1000, true},
+		{"1.5", common.PRVIDStr, 1500000000, true},
+		{"1.5PRV", common.PRVIDStr, 1500000000, true},
+		{" 2 prv ", common.PRVIDStr, 2000000000, true},
+		{".000000001", common.PRVIDStr, 1, true},
+		{"1.0000000010", common.PRVIDStr, 1000000001, true},
+		{"0.0000000001", common.PRVIDStr, 0, false},
+		{"12.345678USDT", usdt, 12345678, true},
+		{"1USDT", common.PRVIDStr, 0, false},
+		{"1.5", unknown, 0, false},
+		{"15", unknown, 15, true},
+		{"1.5", "", 0, false},
+		{"-1", common.PRVIDStr, 0, false},
+		{"1e9", common.PRVIDStr, 0, false},
+		{"18446744073709551615", "", 18446744073709551615, true},
+		{"18446744073709551616", "", 0, false},
+		{"18446744074PRV", common.PRVIDStr, 0, false},
+	}
+
+	for _, tc := range testCases {
+		res, err := parseAmount(tc.amount, tc.tokenID)
+		if tc.isValid != (err == nil) || res != tc.expected {
+			t.Fatalf("parseAmount(%q, %v): expect (%v, valid=%v), got (%v, %v)", tc.amount, tc.tokenID, tc.expected, tc.isValid, res, err)
+		}
+	}
+}
+
+func TestParseTokenList(t *testing.T) {
+	tokenID := strings.Repeat("01", 32)
+	inputs := []string{
+		`{"Result":[{"TokenID":"` + tokenID + `","Symbol":"USDT","PDecimals":6,"Verified":true}],"Error":null}`,
+		`{"UpdatedAt":"2024-01-01T00:00:00Z","Source":"test","Tokens":[{"TokenID":"` + tokenID + `","Symbol":"USDT"}]}`,
+		`[{"TokenID":"` + tokenID + `","Symbol":"USDT"}]`,
+	}
+	for _, input := range inputs {
+		tokens, err := parseTokenList([]byte(input))
+		if err != nil {
+			t.Fatalf("cannot parse %v: %v", input, err)
+		}
+		if len(tokens) != 1 || tokens[0].TokenID != tokenID || tokens[0].Symbol != "USDT" {
+			t.Fatalf("unexpected tokens %+v for %v", tokens, input)
+		}
+	}
+
+	if _, err := parseTokenList([]byte(`{"Result":null,"Error":"server error"}`)); err == nil {
+		t.Fatal("expect an error for a failed response")
+	}
+}
+
+func TestGetTokenRegistryDefault(t *testing.T) {
+	homeDir, err := ioutil.TempDir("", "token-registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(homeDir)
+	defer os.Setenv("HOME", os.Getenv("HOME"))
+	os.Setenv("HOME", homeDir)
+	defer func(oldCfg *Config) { cfg = oldCfg; listTokenInfo = nil }(cfg)
+
+	for _, network := range []string{"mainnet", "testnet"} {
+		cfg = &Config{network: network}
+		listTokenInfo = nil
+		registry := getTokenRegistry()
+		for _, tokenInfo := range defaultMainnetTokens {
+			if _, ok := registry[tokenInfo.TokenID]; ok != (network == "mainnet") {
+				t.Fatalf("%v: token %v in the registry: %v", network, tokenInfo.Symbol, ok)
+			}
+		}
+		if _, ok := registry[common.PRVIDStr]; !ok {
+			t.Fatalf("%v: PRV not in the registry", network)
+		}
+	}
+}
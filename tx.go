@@ -1,18 +1,24 @@
 package main
 
 import (
-	"fmt"
+	"log"
+
 	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
 	"github.com/urfave/cli/v2"
 )
 
 // send creates and sends a transaction from one wallet to another w.r.t a tokenID.
+// The network is initialized here rather than in a Before function, which would also run for the subcommands.
 func send(c *cli.Context) error {
-	var err error
+	err := defaultBeforeFunc(c)
+	if err != nil {
+		return err
+	}
 
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
 	address := c.String(addressFlag)
@@ -20,12 +26,15 @@ func send(c *cli.Context) error {
 		return newAppError(InvalidPaymentAddressError)
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if !isValidTokenID(tokenIDStr) {
-		return newAppError(InvalidTokenIDError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
-	amount := c.Uint64(amountFlag)
+	amount, err := getAmountFlag(c, amountFlag, tokenIDStr)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
 	if amount == 0 {
 		return newAppError(InvalidAmountError)
 	}
@@ -35,26 +44,51 @@ func send(c *cli.Context) error {
 		return newAppError(VersionError)
 	}
 
-	fmt.Printf("Send %v of token %v from %v to %v with version %v\n", amount, tokenIDStr, privateKey, address, version)
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
 
-	var txHash string
-	if tokenIDStr == common.PRVIDStr {
-		txHash, err = cfg.incClient.CreateAndSendRawTransaction(privateKey,
-			[]string{address},
-			[]uint64{amount},
-			int8(version), nil)
-	} else {
-		txHash, err = cfg.incClient.CreateAndSendRawTokenTransaction(privateKey,
-			[]string{address},
-			[]uint64{amount},
-			tokenIDStr,
-			int8(version), nil)
+	log.Printf("Send %v to %v with version %v, fee %v\n", formatTokenAmount(amount, tokenIDStr), address, version, fee)
+
+	if !c.IsSet(coinSelectionFlag) && !c.IsSet(inputsFlag) {
+		var txHash string
+		params := sendParams{
+			PaymentAddress: incclient.PrivateKeyToPaymentAddress(privateKey, -1),
+			Receiver:       address,
+			Amount:         amount,
+			TokenID:        tokenIDStr,
+			Fee:            fee,
+			Version:        int8(version),
+		}
+		if ok, err := tryDaemon("send", params, &txHash); ok {
+			if err != nil {
+				return err
+			}
+			return printTxHash(txHash)
+		}
+
+		txHash, err = createAndSendRawTx(privateKey, []string{address}, []uint64{amount}, tokenIDStr, fee, nil, int8(version))
+		if err != nil {
+			return newAppError(CreateTransferTransactionError, err)
+		}
+		return printTxHash(txHash)
+	}
+
+	tokenCoins, prvCoins, err := chooseSendInputs(c, privateKey, tokenIDStr, amount, fee, int8(version))
+	if err != nil {
+		return err
+	}
+	encodedTx, txHash, err := createRawTxWithInputs(privateKey, []string{address}, []uint64{amount}, tokenIDStr, fee,
+		tokenCoins, prvCoins)
+	if err == nil {
+		err = sendRawTx(encodedTx, tokenIDStr != common.PRVIDStr)
 	}
 	if err != nil {
 		return newAppError(CreateTransferTransactionError, err)
 	}
 
-	return jsonPrintWithKey("TxHash", txHash)
+	return printTxHash(txHash)
 }
 
 // checkReceiver if a user is a receiver of a transaction.
@@ -93,5 +127,5 @@ func checkReceiver(c *cli.Context) error {
 		ReceivingInfo map[string]uint64 `json:"ReceivingInfo"`
 	}
 
-	return jsonPrint(receivingInfo{Received: received, ReceivingInfo: res})
+	return printResult(receivingInfo{Received: received, ReceivingInfo: res})
 }
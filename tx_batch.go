@@ -0,0 +1,454 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/urfave/cli/v2"
+)
+
+// maxBatchReceivers is the maximum number of receivers of a transaction in a batch; one output is kept for the change.
+const maxBatchReceivers = incclient.MaxOutputSize - 1
+
+// batchTxTimeout is the maximum time to wait for a transaction of a batch to be confirmed. Transactions are sent
+// one after another so that the change of a transaction can be spent by the next one.
+const batchTxTimeout = 10 * time.Minute
+
+// statuses of a batch payment.
+const (
+	batchPending   = "pending"
+	batchSent      = "sent"
+	batchConfirmed = "confirmed"
+	batchFailed    = "failed"
+	batchDropped   = "dropped"
+)
+
+// batchResultHeader is the header of the result file of a batch payment.
+var batchResultHeader = []string{"Row", "Address", "Amount", "TokenID", "TxHash", "Status", "Error"}
+
+// batchPayment represents a row of a batch payment file.
+type batchPayment struct {
+	Row     int
+	Address string
+	Amount  uint64
+	TokenID string
+	TxHash  string
+	Status  string
+	Error   string `json:",omitempty"`
+}
+
+// batchTx represents a group of payments of the same token sent in a single transaction.
+type batchTx struct {
+	TokenID  string
+	Payments []*batchPayment
+}
+
+// batchSummary summarizes the pending payments of a token.
+type batchSummary struct {
+	TokenID     string
+	NumPayments int
+	TotalAmount uint64
+	NumTxs      int
+	Fee         uint64
+}
+
+// readBatchPayments reads and validates the payments in a CSV file of address,amount,tokenID rows. The tokenID
+// column is optional (default: PRV), and so is a header row. Tokens and amounts are parsed as their flags are (see
+// resolveTokenID and parseAmount).
+func readBatchPayments(file string) ([]*batchPayment, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	res := make([]*batchPayment, 0)
+	row := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		row++
+		if len(record) == 0 || (len(record) == 1 && strings.TrimSpace(record[0]) == "") {
+			continue
+		}
+		if row == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+		if len(record) < 2 || len(record) > 3 {
+			return nil, fmt.Errorf("row %v: expect address,amount[,tokenID], got %v columns", row, len(record))
+		}
+
+		address := strings.TrimSpace(record[0])
+		if !isValidAddress(address) {
+			return nil, fmt.Errorf("row %v: invalid payment address %v", row, address)
+		}
+		tokenIDStr := common.PRVIDStr
+		if len(record) == 3 && strings.TrimSpace(record[2]) != "" {
+			tokenIDStr, err = resolveTokenID(strings.TrimSpace(record[2]))
+			if err != nil {
+				return nil, fmt.Errorf("row %v: %v", row, err)
+			}
+		}
+		if !isValidTokenID(tokenIDStr) {
+			return nil, fmt.Errorf("row %v: invalid tokenID %v", row, tokenIDStr)
+		}
+		amount, err := parseAmount(record[1], tokenIDStr)
+		if err != nil || amount == 0 {
+			return nil, fmt.Errorf("row %v: invalid amount %v", row, record[1])
+		}
+
+		res = append(res, &batchPayment{
+			Row:     row,
+			Address: address,
+			Amount:  amount,
+			TokenID: tokenIDStr,
+			Status:  batchPending,
+		})
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("no payment found in %v", file)
+	}
+
+	return res, nil
+}
+
+// loadBatchResults restores the state of the payments from a previous run stored in resultFile, if any.
+func loadBatchResults(resultFile string, payments []*batchPayment) error {
+	f, err := os.Open(resultFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return err
+	}
+	paymentByRow := make(map[int]*batchPayment)
+	for _, payment := range payments {
+		paymentByRow[payment.Row] = payment
+	}
+	for i, record := range records {
+		if i == 0 || len(record) != len(batchResultHeader) {
+			continue
+		}
+		row, err := strconv.Atoi(record[0])
+		if err != nil {
+			return fmt.Errorf("invalid row %v in %v", record[0], resultFile)
+		}
+		payment, ok := paymentByRow[row]
+		if !ok || payment.Address != record[1] || strconv.FormatUint(payment.Amount, 10) != record[2] || payment.TokenID != record[3] {
+			return fmt.Errorf("row %v of %v does not match the payment file", row, resultFile)
+		}
+		payment.TxHash = record[4]
+		payment.Status = record[5]
+		payment.Error = record[6]
+	}
+
+	return nil
+}
+
+// saveBatchResults writes the state of the payments to resultFile. The file is replaced atomically so that an
+// interrupted run never leaves a corrupted state behind.
+func saveBatchResults(resultFile string, payments []*batchPayment) error {
+	tmpFile := resultFile + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(f)
+	records := [][]string{batchResultHeader}
+	for _, payment := range payments {
+		records = append(records, []string{
+			strconv.Itoa(payment.Row),
+			payment.Address,
+			strconv.FormatUint(payment.Amount, 10),
+			payment.TokenID,
+			payment.TxHash,
+			payment.Status,
+			payment.Error,
+		})
+	}
+	err = writer.WriteAll(records)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile, resultFile)
+}
+
+// isResendable checks if a payment whose transaction has been broadcast can be sent again, i.e. if its transaction
+// failed or was dropped. Such payments are only resent on demand, once checked on-chain.
+func isResendable(payment *batchPayment) bool {
+	return payment.TxHash != "" && (payment.Status == batchFailed || payment.Status == batchDropped)
+}
+
+// groupBatchPayments groups the payments not sent yet (and, if resend is set, those whose transaction failed or was
+// dropped) into transactions of at most maxBatchReceivers receivers of the same token. Tokens are processed in the
+// order they first appear in the file.
+func groupBatchPayments(payments []*batchPayment, resend bool) []batchTx {
+	tokenIDs := make([]string, 0)
+	paymentsByToken := make(map[string][]*batchPayment)
+	for _, payment := range payments {
+		if payment.TxHash != "" && !(resend && isResendable(payment)) {
+			continue
+		}
+		if _, ok := paymentsByToken[payment.TokenID]; !ok {
+			tokenIDs = append(tokenIDs, payment.TokenID)
+		}
+		paymentsByToken[payment.TokenID] = append(paymentsByToken[payment.TokenID], payment)
+	}
+
+	res := make([]batchTx, 0)
+	for _, tokenIDStr := range tokenIDs {
+		tokenPayments := paymentsByToken[tokenIDStr]
+		for start := 0; start < len(tokenPayments); start += maxBatchReceivers {
+			end := start + maxBatchReceivers
+			if end > len(tokenPayments) {
+				end = len(tokenPayments)
+			}
+			res = append(res, batchTx{TokenID: tokenIDStr, Payments: tokenPayments[start:end]})
+		}
+	}
+
+	return res
+}
+
+// summarizeBatchTxs returns the total amount, the number of transactions and the PRV fee of each token, given the
+// fee of a transaction.
+func summarizeBatchTxs(txs []batchTx, fee uint64) []batchSummary {
+	res := make([]batchSummary, 0)
+	indices := make(map[string]int)
+	for _, tx := range txs {
+		index, ok := indices[tx.TokenID]
+		if !ok {
+			index = len(res)
+			indices[tx.TokenID] = index
+			res = append(res, batchSummary{TokenID: tx.TokenID})
+		}
+		res[index].NumTxs++
+		res[index].Fee += fee
+		for _, payment := range tx.Payments {
+			res[index].NumPayments++
+			res[index].TotalAmount += payment.Amount
+		}
+	}
+
+	return res
+}
+
+// settleBatchTx waits for the transaction of the given payments (all sent in the same transaction), and saves their
+// new status. The hash of a dropped transaction is kept, its payments are only resent on demand.
+func settleBatchTx(resultFile string, payments, txPayments []*batchPayment) error {
+	txHash := txPayments[0].TxHash
+	status, err := waitTxStatus(txHash, batchTxTimeout)
+	if err != nil {
+		if status == nil || status.Status != txDropped {
+			return err
+		}
+		for _, payment := range txPayments {
+			payment.Status = batchDropped
+			payment.Error = err.Error()
+		}
+	} else {
+		for _, payment := range txPayments {
+			payment.Status = batchConfirmed
+		}
+	}
+	if saveErr := saveBatchResults(resultFile, payments); saveErr != nil {
+		return newAppError(SaveBatchResultError, saveErr)
+	}
+
+	return err
+}
+
+// settleSentBatchTxs settles the transactions broadcast by a previous run and not confirmed yet.
+func settleSentBatchTxs(resultFile string, payments []*batchPayment) error {
+	txHashes := make([]string, 0)
+	paymentsByTx := make(map[string][]*batchPayment)
+	for _, payment := range payments {
+		if payment.Status != batchSent || payment.TxHash == "" {
+			continue
+		}
+		if _, ok := paymentsByTx[payment.TxHash]; !ok {
+			txHashes = append(txHashes, payment.TxHash)
+		}
+		paymentsByTx[payment.TxHash] = append(paymentsByTx[payment.TxHash], payment)
+	}
+
+	for _, txHash := range txHashes {
+		log.Printf("Checking transaction %v sent by a previous run\n", txHash)
+		if err := settleBatchTx(resultFile, payments, paymentsByTx[txHash]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendBatch sends the payments listed in a CSV file, grouping them into as few transactions as possible.
+func sendBatch(c *cli.Context) error {
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	version := c.Int(versionFlag)
+	if !isSupportedVersion(int8(version)) {
+		return newAppError(VersionError)
+	}
+
+	file := c.String(batchFileFlag)
+	payments, err := readBatchPayments(file)
+	if err != nil {
+		return newAppError(InvalidBatchFileError, err)
+	}
+	resultFile := c.String(resultFileFlag)
+	if resultFile == "" {
+		resultFile = strings.TrimSuffix(file, filepath.Ext(file)) + "_results.csv"
+	}
+	err = loadBatchResults(resultFile, payments)
+	if err != nil {
+		return newAppError(InvalidBatchFileError, err)
+	}
+
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	// the transactions broadcast by a previous run must be settled first, their payments may have been made.
+	if err = settleSentBatchTxs(resultFile, payments); err != nil {
+		return err
+	}
+	resend := c.Bool(resendFlag)
+	if !resend {
+		for _, payment := range payments {
+			if isResendable(payment) {
+				log.Printf("Row %v: transaction %v %v, check it on-chain and use %v to send it again\n",
+					payment.Row, payment.TxHash, payment.Status, resendFlag)
+			}
+		}
+	}
+
+	txs := groupBatchPayments(payments, resend)
+	if len(txs) == 0 {
+		log.Printf("No payment left to send, see %v\n", resultFile)
+		return printResult(payments)
+	}
+
+	// check the balances and ask for a confirmation
+	summaries := summarizeBatchTxs(txs, fee)
+	totalFee := uint64(0)
+	numPayments := 0
+	for _, summary := range summaries {
+		log.Printf("TokenID: %v, NumPayments: %v, TotalAmount: %v, NumTxs: %v, Fee: %v PRV\n",
+			summary.TokenID, summary.NumPayments, summary.TotalAmount, summary.NumTxs, summary.Fee)
+		totalFee += summary.Fee
+		numPayments += summary.NumPayments
+	}
+	requiredPRV := totalFee
+	for _, summary := range summaries {
+		if summary.TokenID == common.PRVIDStr {
+			requiredPRV += summary.TotalAmount
+			continue
+		}
+		_, err = checkSufficientIncBalance(privateKey, summary.TokenID, summary.TotalAmount)
+		if err != nil {
+			return newAppError(InsufficientBalanceError, err)
+		}
+	}
+	_, err = checkSufficientIncBalance(privateKey, common.PRVIDStr, requiredPRV)
+	if err != nil {
+		return newAppError(InsufficientBalanceError, err)
+	}
+	err = yesNoPrompt(fmt.Sprintf("Send %v payments in %v transactions with a total fee of %v PRV?",
+		numPayments, len(txs), totalFee))
+	if err != nil {
+		return err
+	}
+
+	for i, tx := range txs {
+		addresses := make([]string, 0)
+		amounts := make([]uint64, 0)
+		for _, payment := range tx.Payments {
+			addresses = append(addresses, payment.Address)
+			amounts = append(amounts, payment.Amount)
+		}
+
+		log.Printf("[%v/%v] Send %v payments of token %v\n", i+1, len(txs), len(tx.Payments), tx.TokenID)
+		encodedTx, txHash, err := createRawTx(privateKey, addresses, amounts, tx.TokenID, fee, nil, int8(version))
+		if err != nil {
+			// nothing has been broadcast, the payments stay without a transaction.
+			for _, payment := range tx.Payments {
+				payment.Status = batchFailed
+				payment.Error = err.Error()
+			}
+			if saveErr := saveBatchResults(resultFile, payments); saveErr != nil {
+				return newAppError(SaveBatchResultError, saveErr)
+			}
+			return newAppError(CreateTransferTransactionError, fmt.Errorf("row %v: %v", tx.Payments[0].Row, err))
+		}
+
+		// the hash is saved before broadcasting the transaction, so that an interrupted run never pays twice.
+		for _, payment := range tx.Payments {
+			payment.Error = ""
+			if payment.TxHash != "" {
+				payment.Error = fmt.Sprintf("resent, previous tx %v", payment.TxHash)
+			}
+			payment.TxHash = txHash
+			payment.Status = batchSent
+		}
+		if err = saveBatchResults(resultFile, payments); err != nil {
+			return newAppError(SaveBatchResultError, err)
+		}
+		err = sendRawTx(encodedTx, tx.TokenID != common.PRVIDStr)
+		if err != nil {
+			for _, payment := range tx.Payments {
+				payment.Status = batchFailed
+				payment.Error = err.Error()
+			}
+			if saveErr := saveBatchResults(resultFile, payments); saveErr != nil {
+				return newAppError(SaveBatchResultError, saveErr)
+			}
+			return newAppError(SendRawTxError, fmt.Errorf("row %v: %v", tx.Payments[0].Row, err))
+		}
+
+		log.Printf("TxHash: %v, waiting for confirmation...\n", txHash)
+		if err = settleBatchTx(resultFile, payments, tx.Payments); err != nil {
+			return err
+		}
+	}
+	log.Printf("Results saved to %v\n", resultFile)
+
+	return printResult(payments)
+}
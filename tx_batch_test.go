@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+)
+
+func TestBatchPayments(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	if err != nil {
+		panic(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	address := incclient.PrivateKeyToPaymentAddress(testIncPrivateKey, -1)
+	lines := []string{"address,amount,tokenID"}
+	for i := 0; i < maxBatchReceivers+1; i++ {
+		lines = append(lines, fmt.Sprintf("%v,%v", address, i+1))
+	}
+	lines = append(lines, fmt.Sprintf("%v,100,%v", address, pETH))
+	file := filepath.Join(dir, "payments.csv")
+	err = ioutil.WriteFile(file, []byte(strings.Join(lines, "\n")), 0600)
+	if err != nil {
+		panic(err)
+	}
+
+	payments, err := readBatchPayments(file)
+	if err != nil {
+		panic(err)
+	}
+	if len(payments) != maxBatchReceivers+2 {
+		t.Fatalf("expect %v payments, got %v", maxBatchReceivers+2, len(payments))
+	}
+
+	txs := groupBatchPayments(payments, false)
+	if len(txs) != 3 {
+		t.Fatalf("expect 3 transactions, got %v", len(txs))
+	}
+	if txs[0].TokenID != common.PRVIDStr || len(txs[0].Payments) != maxBatchReceivers || len(txs[1].Payments) != 1 {
+		t.Fatalf("unexpected PRV transactions: %v, %v", len(txs[0].Payments), len(txs[1].Payments))
+	}
+	if txs[2].TokenID != pETH {
+		t.Fatalf("expect the last transaction to send %v, got %v", pETH, txs[2].TokenID)
+	}
+
+	// mark the first transaction as sent and resume from the result file.
+	for _, payment := range txs[0].Payments {
+		payment.TxHash = "sent"
+		payment.Status = batchConfirmed
+	}
+	resultFile := filepath.Join(dir, "results.csv")
+	err = saveBatchResults(resultFile, payments)
+	if err != nil {
+		panic(err)
+	}
+	payments, err = readBatchPayments(file)
+	if err != nil {
+		panic(err)
+	}
+	err = loadBatchResults(resultFile, payments)
+	if err != nil {
+		panic(err)
+	}
+	txs = groupBatchPayments(payments, false)
+	if len(txs) != 2 {
+		t.Fatalf("expect 2 remaining transactions, got %v", len(txs))
+	}
+
+	// a dropped transaction keeps its hash, and its payments are only grouped again on demand.
+	for _, payment := range txs[1].Payments {
+		payment.TxHash = "dropped"
+		payment.Status = batchDropped
+	}
+	if txs = groupBatchPayments(payments, false); len(txs) != 1 {
+		t.Fatalf("expect 1 remaining transaction without resend, got %v", len(txs))
+	}
+	if txs = groupBatchPayments(payments, true); len(txs) != 2 {
+		t.Fatalf("expect 2 remaining transactions with resend, got %v", len(txs))
+	}
+
+	// a result file of another payment file must be rejected.
+	payments[0].Amount++
+	err = loadBatchResults(resultFile, payments)
+	if err == nil {
+		t.Fatalf("expect an error when the result file does not match")
+	}
+
+	err = ioutil.WriteFile(file, []byte(address+",abc"), 0600)
+	if err != nil {
+		panic(err)
+	}
+	_, err = readBatchPayments(file)
+	if err == nil {
+		t.Fatalf("expect an error on an invalid amount")
+	}
+}
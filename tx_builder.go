@@ -0,0 +1,332 @@
+package main
+
+// This file holds the builders of the staking and pDEX transactions. They mirror those of the SDK, which always pay
+// the default PRV fee, but take the fee as a parameter.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	metadataCommon "github.com/incognitochain/go-incognito-sdk-v2/metadata/common"
+	metadataPdexv3 "github.com/incognitochain/go-incognito-sdk-v2/metadata/pdexv3"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// shardStakingAmount is the amount of PRV required to stake a shard validator.
+const shardStakingAmount = uint64(1750000000000)
+
+// getCommitteeKey returns the base58-encoded committee key of a candidate given its mining key.
+func getCommitteeKey(miningKey, candidateAddr string) (string, error) {
+	candidateWallet, err := wallet.Base58CheckDeserialize(candidateAddr)
+	if err != nil {
+		return "", err
+	}
+	pk := candidateWallet.KeySet.PaymentAddress.Pk
+	if len(pk) == 0 {
+		return "", fmt.Errorf("candidate payment address invalid: %v", candidateAddr)
+	}
+
+	seed, _, err := base58.Base58Check{}.Decode(miningKey)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode mining key %v: %v", miningKey, err)
+	}
+	committeePK, err := key.NewCommitteeKeyFromSeed(seed, pk)
+	if err != nil {
+		return "", fmt.Errorf("cannot create committee key: %v", err)
+	}
+	committeePKBytes, err := committeePK.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("committee to bytes error: %v", err)
+	}
+
+	return base58.Base58Check{}.Encode(committeePKBytes, common.ZeroByte), nil
+}
+
+// createShardStakingTx creates a shard staking transaction.
+func createShardStakingTx(privateKey, miningKey, candidateAddr, rewardAddr string, autoReStake bool, fee uint64) ([]byte, string, error) {
+	committeeKey, err := getCommitteeKey(miningKey, candidateAddr)
+	if err != nil {
+		return nil, "", err
+	}
+	funderAddr := incclient.PrivateKeyToPaymentAddress(privateKey, -1)
+	md, err := metadata.NewStakingMetadata(metadata.ShardStakingMeta, funderAddr, rewardAddr, shardStakingAmount,
+		committeeKey, autoReStake)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{shardStakingAmount}, common.PRVIDStr, fee, md, -1)
+}
+
+// createUnStakingTx creates an un-staking transaction.
+func createUnStakingTx(privateKey, miningKey, candidateAddr string, fee uint64) ([]byte, string, error) {
+	committeeKey, err := getCommitteeKey(miningKey, candidateAddr)
+	if err != nil {
+		return nil, "", err
+	}
+	md, err := metadata.NewUnStakingMetadata(committeeKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{0}, common.PRVIDStr, fee, md, -1)
+}
+
+// createWithdrawRewardTx creates a transaction withdrawing the committee reward of addr w.r.t a tokenID.
+func createWithdrawRewardTx(privateKey, addr, tokenIDStr string, version int8, fee uint64) ([]byte, string, error) {
+	var err error
+	if version == 1 {
+		addr, err = wallet.GetPaymentAddressV1(addr, false)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	md, err := metadata.NewWithDrawRewardRequest(tokenIDStr, addr, 0, metadata.WithDrawRewardRequestMeta)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return createRawTx(privateKey, []string{}, []uint64{}, common.PRVIDStr, fee, md, version)
+}
+
+// newOTAReceiver generates a one-time receiver for the payment address of a private key.
+func newOTAReceiver(privateKey string) (string, error) {
+	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return "", err
+	}
+	otaReceiver := coin.OTAReceiver{}
+	paymentInfo := &key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Message: []byte{}}
+	err = otaReceiver.FromCoinParams(coin.NewMintCoinParams(paymentInfo))
+	if err != nil {
+		return "", err
+	}
+
+	return otaReceiver.String()
+}
+
+// newOTAReceivers generates a one-time receiver of each of the given tokenIDs for the payment address of a private key.
+func newOTAReceivers(privateKey string, tokenIDs ...string) (map[common.Hash]coin.OTAReceiver, error) {
+	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	tokenList := make([]common.Hash, 0)
+	for _, tokenIDStr := range tokenIDs {
+		tokenID, err := common.Hash{}.NewHashFromStr(tokenIDStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tokenID %v: %v", tokenIDStr, err)
+		}
+		tokenList = append(tokenList, *tokenID)
+	}
+
+	return incclient.GenerateOTAReceivers(tokenList, senderWallet.KeySet.PaymentAddress)
+}
+
+// otaReceiversToStrings converts a map of one-time receivers to a map of strings.
+func otaReceiversToStrings(otaReceivers map[common.Hash]coin.OTAReceiver) map[string]string {
+	res := make(map[string]string)
+	for tokenID, otaReceiver := range otaReceivers {
+		res[tokenID.String()], _ = otaReceiver.String()
+	}
+
+	return res
+}
+
+// createPdexv3MintNFTTx creates a transaction minting a new pDEX NFT.
+func createPdexv3MintNFTTx(privateKey string, fee uint64) ([]byte, string, error) {
+	otaReceiver, err := newOTAReceiver(privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	prvRequired := cfg.incClient.GetMinPRVRequiredToMintNFT(0)
+	md := metadataPdexv3.NewUserMintNftRequestWithValue(otaReceiver, prvRequired)
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{prvRequired}, common.PRVIDStr, fee, md, 2)
+}
+
+// createPdexv3TradeTx creates a pDEX trading transaction.
+func createPdexv3TradeTx(privateKey string, tradePath []string, tokenIDToSellStr, tokenIDToBuyStr string,
+	amount, minAcceptableAmount, tradingFee uint64, feeInPRV bool, fee uint64) ([]byte, string, error) {
+	tokenToSell, err := common.Hash{}.NewHashFromStr(tokenIDToSellStr)
+	if err != nil {
+		return nil, "", err
+	}
+	md, err := metadataPdexv3.NewTradeRequest(tradePath, *tokenToSell, amount, minAcceptableAmount, tradingFee, nil,
+		metadataCommon.Pdexv3TradeRequestMeta)
+	if err != nil {
+		return nil, "", err
+	}
+
+	isPRV := tokenIDToSellStr == common.PRVIDStr
+	tokenIDs := []string{tokenIDToSellStr, tokenIDToBuyStr}
+	if feeInPRV && !isPRV && tokenIDToBuyStr != common.PRVIDStr {
+		tokenIDs = append(tokenIDs, common.PRVIDStr)
+	}
+	md.Receiver, err = newOTAReceivers(privateKey, tokenIDs...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if isPRV || !feeInPRV {
+		return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{amount + tradingFee}, tokenIDToSellStr, fee, md, 2)
+	}
+	tokenParam := incclient.NewTxTokenParam(tokenIDToSellStr, 1, []string{common.BurningAddress2}, []uint64{amount}, false, 0, nil)
+	txParam := incclient.NewTxParam(privateKey, []string{common.BurningAddress2}, []uint64{tradingFee}, fee, tokenParam, md, nil)
+
+	return cfg.incClient.CreateRawTokenTransaction(txParam, 2)
+}
+
+// createPdexv3AddOrderTx creates a transaction adding an order to the pDEX.
+func createPdexv3AddOrderTx(privateKey, pairID, tokenIDToSellStr, tokenIDToBuyStr, nftIDStr string,
+	sellAmount, minAcceptableAmount uint64, fee uint64) ([]byte, string, error) {
+	tokenToSell, err := common.Hash{}.NewHashFromStr(tokenIDToSellStr)
+	if err != nil {
+		return nil, "", err
+	}
+	nftID, err := common.Hash{}.NewHashFromStr(nftIDStr)
+	if err != nil {
+		return nil, "", err
+	}
+	md, err := metadataPdexv3.NewAddOrderRequest(*tokenToSell, pairID, sellAmount, minAcceptableAmount, nil, *nftID,
+		metadataCommon.Pdexv3AddOrderRequestMeta)
+	if err != nil {
+		return nil, "", err
+	}
+	md.Receiver, err = newOTAReceivers(privateKey, tokenIDToSellStr, tokenIDToBuyStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{sellAmount}, tokenIDToSellStr, fee, md, 2)
+}
+
+// createPdexv3WithdrawOrderTx creates a transaction withdrawing an order from the pDEX.
+func createPdexv3WithdrawOrderTx(privateKey, pairID, orderID, nftIDStr string, amount uint64, withdrawTokenIDs []string,
+	fee uint64) ([]byte, string, error) {
+	nftID, err := common.Hash{}.NewHashFromStr(nftIDStr)
+	if err != nil {
+		return nil, "", err
+	}
+	otaReceivers, err := newOTAReceivers(privateKey, append([]string{nftIDStr}, withdrawTokenIDs...)...)
+	if err != nil {
+		return nil, "", err
+	}
+	md, err := metadataPdexv3.NewWithdrawOrderRequest(pairID, orderID, amount, otaReceivers, *nftID,
+		metadataCommon.Pdexv3WithdrawOrderRequestMeta)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{1}, nftIDStr, fee, md, 2)
+}
+
+// createPdexv3ContributeTx creates a transaction contributing an amount of a token to the pDEX.
+func createPdexv3ContributeTx(privateKey, pairID, pairHash, tokenIDStr, nftIDStr string, amount, amplifier uint64,
+	fee uint64) ([]byte, string, error) {
+	otaReceiver, err := newOTAReceiver(privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	md := metadataPdexv3.NewAddLiquidityRequestWithValue(pairID, pairHash, otaReceiver, tokenIDStr, nftIDStr, amount,
+		uint(amplifier))
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{amount}, tokenIDStr, fee, md, 2)
+}
+
+// createPdexv3WithdrawLiquidityTx creates a transaction withdrawing an amount of share from a pDEX pool.
+func createPdexv3WithdrawLiquidityTx(privateKey, pairID, token0IDStr, token1IDStr, nftIDStr string, shareAmount uint64,
+	fee uint64) ([]byte, string, error) {
+	otaReceivers, err := newOTAReceivers(privateKey, token0IDStr, token1IDStr, nftIDStr)
+	if err != nil {
+		return nil, "", err
+	}
+	md := metadataPdexv3.NewWithdrawLiquidityRequestWithValue(pairID, nftIDStr, otaReceiversToStrings(otaReceivers), shareAmount)
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{1}, nftIDStr, fee, md, 2)
+}
+
+// createPdexv3WithdrawLPFeeTx creates a transaction withdrawing the LP fees of an nftID in a pDEX pool.
+func createPdexv3WithdrawLPFeeTx(privateKey, pairID, nftIDStr string, fee uint64) ([]byte, string, error) {
+	nftID, err := common.Hash{}.NewHashFromStr(nftIDStr)
+	if err != nil {
+		return nil, "", err
+	}
+	tokenIDs := strings.Split(pairID, "-")
+	if len(tokenIDs) != 3 {
+		return nil, "", fmt.Errorf("invalid pairID %v", pairID)
+	}
+	otaReceivers, err := newOTAReceivers(privateKey, nftIDStr, common.PRVIDStr, common.PDEXCoinID.String(), tokenIDs[0], tokenIDs[1])
+	if err != nil {
+		return nil, "", err
+	}
+	md, err := metadataPdexv3.NewPdexv3WithdrawalLPFeeRequest(metadataCommon.Pdexv3WithdrawLPFeeRequestMeta, pairID,
+		*nftID, otaReceivers)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{1}, nftIDStr, fee, md, 2)
+}
+
+// createPdexv3StakingTx creates a transaction staking an amount of a token to the pDEX.
+func createPdexv3StakingTx(privateKey, tokenIDStr, nftIDStr string, amount uint64, fee uint64) ([]byte, string, error) {
+	otaReceiver, err := newOTAReceiver(privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	md := metadataPdexv3.NewStakingRequestWithValue(tokenIDStr, nftIDStr, otaReceiver, amount)
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{amount}, tokenIDStr, fee, md, 2)
+}
+
+// createPdexv3UnStakingTx creates a transaction un-staking an amount of a token from the pDEX.
+func createPdexv3UnStakingTx(privateKey, tokenIDStr, nftIDStr string, amount uint64, fee uint64) ([]byte, string, error) {
+	otaReceivers, err := newOTAReceivers(privateKey, nftIDStr, tokenIDStr)
+	if err != nil {
+		return nil, "", err
+	}
+	md := metadataPdexv3.NewUnstakingRequestWithValue(tokenIDStr, nftIDStr, otaReceiversToStrings(otaReceivers), amount)
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{1}, nftIDStr, fee, md, 2)
+}
+
+// createPdexv3WithdrawStakeRewardTx creates a transaction withdrawing the pDEX staking rewards of an nftID.
+func createPdexv3WithdrawStakeRewardTx(privateKey, stakingPoolID, nftIDStr string, fee uint64) ([]byte, string, error) {
+	nftID, err := common.Hash{}.NewHashFromStr(nftIDStr)
+	if err != nil {
+		return nil, "", err
+	}
+	otaReceivers, err := newOTAReceivers(privateKey, nftIDStr, common.PRVIDStr, stakingPoolID)
+	if err != nil {
+		return nil, "", err
+	}
+	rewardTokenIDs, err := cfg.incClient.GetListStakingRewardTokens(0)
+	if err != nil {
+		return nil, "", err
+	}
+	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	rewardReceivers, err := incclient.GenerateOTAReceivers(rewardTokenIDs, senderWallet.KeySet.PaymentAddress)
+	if err != nil {
+		return nil, "", err
+	}
+	for tokenID, otaReceiver := range rewardReceivers {
+		otaReceivers[tokenID] = otaReceiver
+	}
+	md, err := metadataPdexv3.NewPdexv3WithdrawalStakingRewardRequest(metadataCommon.Pdexv3WithdrawStakingRewardRequestMeta,
+		stakingPoolID, *nftID, otaReceivers)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return createRawTx(privateKey, []string{common.BurningAddress2}, []uint64{1}, nftIDStr, fee, md, 2)
+}
@@ -0,0 +1,375 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/urfave/cli/v2"
+)
+
+// coin selection strategies.
+const (
+	largestFirst  = "largest-first"
+	smallestFirst = "smallest-first"
+	exactMatch    = "exact-match"
+	randomCoins   = "random"
+)
+
+// coinSelectionStrategies lists the supported values of the coinSelection flag.
+var coinSelectionStrategies = []string{largestFirst, smallestFirst, exactMatch, randomCoins}
+
+// spendableCoins is a list of UTXOs of a token and their indices.
+type spendableCoins struct {
+	coins   []coin.PlainCoin
+	indices []uint64
+}
+
+// total returns the value of the coins.
+func (s spendableCoins) total() uint64 {
+	res := uint64(0)
+	for _, c := range s.coins {
+		res += c.GetValue()
+	}
+
+	return res
+}
+
+// pick returns the coins at the given positions.
+func (s spendableCoins) pick(positions []int) spendableCoins {
+	res := spendableCoins{coins: make([]coin.PlainCoin, 0), indices: make([]uint64, 0)}
+	for _, i := range positions {
+		res.coins = append(res.coins, s.coins[i])
+		res.indices = append(res.indices, s.indices[i])
+	}
+
+	return res
+}
+
+// selectCoinPositions chooses, among coins of the given values, at most maxInputs coins whose total value covers
+// the required amount, following a strategy:
+//   - largest-first: the largest coins first, using as few inputs as possible;
+//   - smallest-first: the smallest coins first, sweeping the dust;
+//   - exact-match: a single coin, or a pair of coins, whose total is exactly the required amount, leaving no change;
+//   - random: random coins.
+func selectCoinPositions(values []uint64, required uint64, strategy string, maxInputs int) ([]int, error) {
+	positions := make([]int, len(values))
+	for i := range positions {
+		positions[i] = i
+	}
+
+	switch strategy {
+	case largestFirst:
+		sort.SliceStable(positions, func(i, j int) bool { return values[positions[i]] > values[positions[j]] })
+	case smallestFirst:
+		sort.SliceStable(positions, func(i, j int) bool { return values[positions[i]] < values[positions[j]] })
+	case randomCoins:
+		r := rand.New(rand.NewSource(time.Now().UnixNano()))
+		r.Shuffle(len(positions), func(i, j int) { positions[i], positions[j] = positions[j], positions[i] })
+	case exactMatch:
+		seen := make(map[uint64]int)
+		for i, v := range values {
+			if v == required {
+				return []int{i}, nil
+			}
+		}
+		if maxInputs >= 2 {
+			for i, v := range values {
+				if v < required {
+					if j, ok := seen[required-v]; ok {
+						return []int{j, i}, nil
+					}
+					seen[v] = i
+				}
+			}
+		}
+		return nil, fmt.Errorf("no coin, nor pair of coins, has a total of exactly %v", required)
+	default:
+		return nil, fmt.Errorf("expected coinSelection in %v, got %q", coinSelectionStrategies, strategy)
+	}
+
+	total := uint64(0)
+	for i, pos := range positions {
+		if total >= required {
+			return positions[:i], nil
+		}
+		if i == maxInputs {
+			return nil, fmt.Errorf("%v coins of the %v strategy only cover %v out of %v, try consolidating the UTXOs first",
+				maxInputs, strategy, total, required)
+		}
+		total += values[pos]
+	}
+	if total < required {
+		return nil, fmt.Errorf("the coins only cover %v out of %v", total, required)
+	}
+
+	return positions, nil
+}
+
+// selectCoins chooses coins covering the required amount with a strategy.
+func selectCoins(candidates spendableCoins, required uint64, strategy string) (spendableCoins, error) {
+	values := make([]uint64, 0)
+	for _, c := range candidates.coins {
+		values = append(values, c.GetValue())
+	}
+	positions, err := selectCoinPositions(values, required, strategy, incclient.MaxInputSize)
+	if err != nil {
+		return spendableCoins{}, err
+	}
+
+	return candidates.pick(positions), nil
+}
+
+// pickCoinsByIndex returns the coins with the given indices, as printed by the `account utxo` command.
+func pickCoinsByIndex(candidates spendableCoins, indices []int64) (spendableCoins, error) {
+	if len(indices) > incclient.MaxInputSize {
+		return spendableCoins{}, fmt.Errorf("at most %v inputs are allowed, got %v", incclient.MaxInputSize, len(indices))
+	}
+	positionByIndex := make(map[uint64]int)
+	for i, idx := range candidates.indices {
+		positionByIndex[idx] = i
+	}
+
+	positions := make([]int, 0)
+	picked := make(map[uint64]bool)
+	for _, idx := range indices {
+		pos, ok := positionByIndex[uint64(idx)]
+		if idx < 0 || !ok {
+			return spendableCoins{}, fmt.Errorf("%v is not the index of an unspent coin", idx)
+		}
+		if picked[uint64(idx)] {
+			return spendableCoins{}, fmt.Errorf("input %v is given twice", idx)
+		}
+		picked[uint64(idx)] = true
+		positions = append(positions, pos)
+	}
+
+	return candidates.pick(positions), nil
+}
+
+// getSpendableCoins returns the UTXOs of a private key w.r.t a tokenID and a version.
+func getSpendableCoins(privateKey, tokenIDStr string, version int8) (spendableCoins, error) {
+	utxos, indices, err := getUTXOsByVersion(privateKey, tokenIDStr, version)
+	if err != nil {
+		return spendableCoins{}, newAppError(GetUnspentOutputCoinsError, err)
+	}
+
+	return spendableCoins{coins: utxos, indices: indices}, nil
+}
+
+// createRawTxWithInputs is the same as createRawTx, but spends the given token coins and pays the fee with the given
+// PRV coins (for a PRV transaction, the PRV coins are not used).
+func createRawTxWithInputs(privateKey string, addresses []string, amounts []uint64, tokenIDStr string, fee uint64,
+	tokenCoins, prvCoins spendableCoins) ([]byte, string, error) {
+	if tokenIDStr == common.PRVIDStr {
+		txParam := incclient.NewTxParam(privateKey, addresses, amounts, fee, nil, nil, nil)
+		return cfg.incClient.CreateRawTransactionWithInputCoins(txParam, tokenCoins.coins, tokenCoins.indices)
+	}
+
+	tokenParam := incclient.NewTxTokenParam(tokenIDStr, 1, addresses, amounts, false, 0, nil)
+	txParam := incclient.NewTxParam(privateKey, []string{}, []uint64{}, fee, tokenParam, nil, nil)
+	return cfg.incClient.CreateRawTokenTransactionWithInputCoins(txParam, tokenCoins.coins, tokenCoins.indices,
+		prvCoins.coins, prvCoins.indices)
+}
+
+// chooseSendInputs chooses the input coins of a `send` transaction from the inputs or the coinSelection flag. For a
+// token transaction, the PRV coins paying the fee are chosen with the coinSelection strategy (default: largest-first).
+func chooseSendInputs(c *cli.Context, privateKey, tokenIDStr string, amount, fee uint64, version int8) (spendableCoins,
+	spendableCoins, error) {
+	strategy := c.String(coinSelectionFlag)
+	if strategy == "" {
+		strategy = largestFirst
+	}
+	inputs := c.Int64Slice(inputsFlag)
+	if len(inputs) != 0 && version != 2 {
+		return spendableCoins{}, spendableCoins{}, newAppError(UserInputError,
+			fmt.Errorf("choosing the inputs by index is only supported for transactions v2"))
+	}
+
+	candidates, err := getSpendableCoins(privateKey, tokenIDStr, version)
+	if err != nil {
+		return spendableCoins{}, spendableCoins{}, err
+	}
+	required := amount
+	if tokenIDStr == common.PRVIDStr {
+		required += fee
+	}
+
+	var tokenCoins spendableCoins
+	if len(inputs) != 0 {
+		tokenCoins, err = pickCoinsByIndex(candidates, inputs)
+		if err != nil {
+			return spendableCoins{}, spendableCoins{}, newAppError(UserInputError, err)
+		}
+		if tokenCoins.total() < required {
+			return spendableCoins{}, spendableCoins{}, newAppError(InsufficientBalanceError,
+				fmt.Errorf("the inputs only cover %v out of %v", tokenCoins.total(), required))
+		}
+	} else {
+		tokenCoins, err = selectCoins(candidates, required, strategy)
+		if err != nil {
+			return spendableCoins{}, spendableCoins{}, newAppError(InsufficientBalanceError, err)
+		}
+	}
+	log.Printf("Spending %v coins %v with a total of %v\n", len(tokenCoins.coins), tokenCoins.indices, tokenCoins.total())
+
+	if tokenIDStr == common.PRVIDStr {
+		return tokenCoins, spendableCoins{}, nil
+	}
+	prvCandidates, err := getSpendableCoins(privateKey, common.PRVIDStr, version)
+	if err != nil {
+		return spendableCoins{}, spendableCoins{}, err
+	}
+	if strategy == exactMatch {
+		// the fee is rarely matched exactly
+		strategy = largestFirst
+	}
+	prvCoins, err := selectCoins(prvCandidates, fee, strategy)
+	if err != nil {
+		return spendableCoins{}, spendableCoins{}, newAppError(InsufficientBalanceError, fmt.Errorf("PRV fee: %v", err))
+	}
+	log.Printf("Paying the fee with %v PRV coins %v\n", len(prvCoins.coins), prvCoins.indices)
+
+	return tokenCoins, prvCoins, nil
+}
+
+// sweepTx is a transaction of a sweep, moving some coins of a token to the receiver.
+type sweepTx struct {
+	tokenCoins spendableCoins
+	prvCoins   spendableCoins
+	amount     uint64
+}
+
+// planSweep splits the coins of a token into transactions of at most incclient.MaxInputSize inputs, largest coins
+// first. For PRV, each transaction sends the value of its coins minus the fee, and the coins that would not cover the
+// fee of their transaction are left. For a token, the fee of each transaction is paid with distinct PRV coins.
+func planSweep(tokenIDStr string, tokenCoins, prvCoins spendableCoins, fee uint64) ([]*sweepTx, error) {
+	sortByValueDesc := func(s spendableCoins) spendableCoins {
+		positions := make([]int, len(s.coins))
+		for i := range positions {
+			positions[i] = i
+		}
+		sort.SliceStable(positions, func(i, j int) bool {
+			return s.coins[positions[i]].GetValue() > s.coins[positions[j]].GetValue()
+		})
+		return s.pick(positions)
+	}
+	tokenCoins = sortByValueDesc(tokenCoins)
+	prvCoins = sortByValueDesc(prvCoins)
+
+	res := make([]*sweepTx, 0)
+	nextPRV := 0
+	for start := 0; start < len(tokenCoins.coins); start += incclient.MaxInputSize {
+		end := start + incclient.MaxInputSize
+		if end > len(tokenCoins.coins) {
+			end = len(tokenCoins.coins)
+		}
+		tx := &sweepTx{tokenCoins: spendableCoins{coins: tokenCoins.coins[start:end], indices: tokenCoins.indices[start:end]}}
+		tx.amount = tx.tokenCoins.total()
+
+		if tokenIDStr == common.PRVIDStr {
+			if tx.amount <= fee {
+				log.Printf("%v coins with a total of %v do not cover the fee, they are left\n", end-start, tx.amount)
+				break
+			}
+			tx.amount -= fee
+		} else {
+			prvStart, prvTotal := nextPRV, uint64(0)
+			for ; nextPRV < len(prvCoins.coins) && prvTotal < fee && nextPRV-prvStart < incclient.MaxInputSize; nextPRV++ {
+				prvTotal += prvCoins.coins[nextPRV].GetValue()
+			}
+			if prvTotal < fee {
+				return nil, fmt.Errorf("not enough PRV to pay the fee of %v transactions", (len(tokenCoins.coins)+incclient.MaxInputSize-1)/incclient.MaxInputSize)
+			}
+			tx.prvCoins = spendableCoins{coins: prvCoins.coins[prvStart:nextPRV], indices: prvCoins.indices[prvStart:nextPRV]}
+		}
+		res = append(res, tx)
+	}
+
+	return res, nil
+}
+
+// sendSweep moves the entire balance of a token (of a version) to another address, minus the fees.
+func sendSweep(c *cli.Context) error {
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	address := c.String(addressFlag)
+	if !isValidAddress(address) {
+		return newAppError(InvalidPaymentAddressError)
+	}
+
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	version := int8(c.Int(versionFlag))
+	if !isSupportedVersion(version) {
+		return newAppError(VersionError)
+	}
+
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	tokenCoins, err := getSpendableCoins(privateKey, tokenIDStr, version)
+	if err != nil {
+		return err
+	}
+	if len(tokenCoins.coins) == 0 {
+		return newAppError(InsufficientBalanceError, fmt.Errorf("no UTXO v%v of token %v", version, tokenIDStr))
+	}
+	prvCoins := spendableCoins{}
+	if tokenIDStr != common.PRVIDStr {
+		prvCoins, err = getSpendableCoins(privateKey, common.PRVIDStr, version)
+		if err != nil {
+			return err
+		}
+	}
+	txs, err := planSweep(tokenIDStr, tokenCoins, prvCoins, fee)
+	if err != nil {
+		return newAppError(InsufficientBalanceError, err)
+	}
+	if len(txs) == 0 {
+		return newAppError(InsufficientBalanceError, fmt.Errorf("the balance does not cover the fee"))
+	}
+
+	totalAmount := uint64(0)
+	for _, tx := range txs {
+		totalAmount += tx.amount
+	}
+	log.Printf("Sweep %v of token %v (%v UTXOs) to %v with version %v: %v txs, fee %v each\n", totalAmount,
+		tokenIDStr, len(tokenCoins.coins), address, version, len(txs), fee)
+	err = yesNoPrompt("Do you want to continue?")
+	if err != nil {
+		return err
+	}
+
+	txList := make([]string, 0)
+	for _, tx := range txs {
+		encodedTx, txHash, err := createRawTxWithInputs(privateKey, []string{address}, []uint64{tx.amount}, tokenIDStr,
+			fee, tx.tokenCoins, tx.prvCoins)
+		if err == nil {
+			err = sendRawTx(encodedTx, tokenIDStr != common.PRVIDStr)
+		}
+		if err != nil {
+			if len(txList) != 0 {
+				log.Printf("Sent %v transactions before failing: %v\n", len(txList), txList)
+			}
+			return newAppError(CreateTransferTransactionError, err)
+		}
+		log.Printf("Swept %v, TxHash: %v\n", tx.amount, txHash)
+		txList = append(txList, txHash)
+	}
+
+	return printTxListResult(txList)
+}
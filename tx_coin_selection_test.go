@@ -0,0 +1,128 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+)
+
+func newTestCoins(values ...uint64) spendableCoins {
+	res := spendableCoins{}
+	for i, v := range values {
+		c := new(coin.PlainCoinV1)
+		c.SetValue(v)
+		res.coins = append(res.coins, c)
+		res.indices = append(res.indices, uint64(100+i))
+	}
+
+	return res
+}
+
+func TestSelectCoinPositions(t *testing.T) {
+	values := []uint64{5, 50, 1, 20, 30}
+	testCases := []struct {
+		strategy  string
+		required  uint64
+		maxInputs int
+		expected  []int
+		isValid   bool
+	}{
+		{largestFirst, 60, 30, []int{1, 4}, true},
+		{largestFirst, 107, 30, nil, false},
+		{smallestFirst, 20, 30, []int{2, 0, 3}, true},
+		{smallestFirst, 20, 2, nil, false},
+		{exactMatch, 20, 30, []int{3}, true},
+		{exactMatch, 25, 30, []int{0, 3}, true},
+		{exactMatch, 25, 1, nil, false},
+		{exactMatch, 7, 30, nil, false},
+		{"oldest-first", 1, 30, nil, false},
+	}
+
+	for _, tc := range testCases {
+		res, err := selectCoinPositions(values, tc.required, tc.strategy, tc.maxInputs)
+		if (err == nil) != tc.isValid {
+			t.Fatalf("%v %v: expect valid = %v, got %v", tc.strategy, tc.required, tc.isValid, err)
+		}
+		if len(res) != len(tc.expected) {
+			t.Fatalf("%v %v: expect %v, got %v", tc.strategy, tc.required, tc.expected, res)
+		}
+		for i := range res {
+			if res[i] != tc.expected[i] {
+				t.Fatalf("%v %v: expect %v, got %v", tc.strategy, tc.required, tc.expected, res)
+			}
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		res, err := selectCoinPositions(values, 100, randomCoins, 30)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := uint64(0)
+		for _, pos := range res {
+			total += values[pos]
+		}
+		if total < 100 || total-values[res[len(res)-1]] >= 100 {
+			t.Fatalf("unexpected random selection %v", res)
+		}
+	}
+}
+
+func TestPickCoinsByIndex(t *testing.T) {
+	candidates := newTestCoins(5, 50, 1)
+	res, err := pickCoinsByIndex(candidates, []int64{102, 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.total() != 6 || res.indices[0] != 102 || res.indices[1] != 100 {
+		t.Fatalf("unexpected coins %v with a total of %v", res.indices, res.total())
+	}
+
+	for _, indices := range [][]int64{{103}, {-1}, {100, 100}} {
+		if _, err = pickCoinsByIndex(candidates, indices); err == nil {
+			t.Fatalf("expect an error for inputs %v", indices)
+		}
+	}
+}
+
+func TestPlanSweep(t *testing.T) {
+	values := make([]uint64, 0)
+	for i := 0; i < incclient.MaxInputSize+2; i++ {
+		values = append(values, 1000)
+	}
+	values = append(values, 3, 4)
+
+	// PRV: 30 coins of 1000, then 2 coins of 1000 and 2 dust coins
+	txs, err := planSweep(common.PRVIDStr, newTestCoins(values...), spendableCoins{}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].amount != 1000*incclient.MaxInputSize-10 || txs[1].amount != 2007-10 {
+		t.Fatalf("unexpected PRV sweep %+v", txs)
+	}
+
+	// PRV: the last transaction would only spend dust
+	dustValues := append(append([]uint64{}, values[:incclient.MaxInputSize]...), 3)
+	txs, err = planSweep(common.PRVIDStr, newTestCoins(dustValues...), spendableCoins{}, 10)
+	if err != nil || len(txs) != 1 {
+		t.Fatalf("expect the dust coin to be left, got %+v, %v", txs, err)
+	}
+
+	// token: each transaction pays its fee with distinct PRV coins
+	tokenID := "0000000000000000000000000000000000000000000000000000000000000100"
+	txs, err = planSweep(tokenID, newTestCoins(values...), newTestCoins(6, 15, 4), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].amount != 1000*incclient.MaxInputSize || txs[1].amount != 2007 {
+		t.Fatalf("unexpected token sweep %+v", txs)
+	}
+	if txs[0].prvCoins.total() != 15 || txs[1].prvCoins.total() != 10 {
+		t.Fatalf("unexpected fee coins %v and %v", txs[0].prvCoins.indices, txs[1].prvCoins.indices)
+	}
+	if _, err = planSweep(tokenID, newTestCoins(values...), newTestCoins(15, 4), 10); err == nil {
+		t.Fatalf("expect an error when PRV cannot pay all the fees")
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+)
+
+// maxUTXOsAfterConsolidated is the number of UTXOs under which an account is considered as consolidated.
+const maxUTXOsAfterConsolidated = 10
+
+// getUTXOsByVersion returns the UTXOs of a private key w.r.t a tokenID with the given version, and their indices.
+func getUTXOsByVersion(privateKey, tokenIDStr string, version int8) ([]coin.PlainCoin, []uint64, error) {
+	allUTXOs, allIndices, err := cfg.incClient.GetUnspentOutputCoins(privateKey, tokenIDStr, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	utxos := make([]coin.PlainCoin, 0)
+	indices := make([]uint64, 0)
+	for i, utxo := range allUTXOs {
+		if utxo.GetVersion() == uint8(version) {
+			utxos = append(utxos, utxo)
+			indices = append(indices, allIndices[i].Uint64())
+		}
+	}
+
+	return utxos, indices, nil
+}
+
+// runConsolidation consolidates the UTXOs of a private key w.r.t a tokenID and a version. Without a custom fee (i.e,
+// fee is 0), the SDK consolidates with numThreads transactions at a time; with a custom fee, transactions are sent one
+// after another.
+func runConsolidation(privateKey, tokenIDStr string, version int8, numThreads int, fee uint64) ([]string, error) {
+	if fee == 0 {
+		return cfg.incClient.Consolidate(privateKey, tokenIDStr, version, numThreads)
+	}
+
+	log.Printf("A custom fee of %v is used, transactions are sent one after another\n", fee)
+	return consolidateWithFee(privateKey, tokenIDStr, version, fee)
+}
+
+// consolidateWithFee consolidates the UTXOs of a private key the same way as the SDK does, but paying the given
+// PRV fee, also for token UTXOs v1 which the SDK consolidates by paying the fee in the token. Transactions are sent
+// one after another, each waiting for the previous one to be confirmed.
+func consolidateWithFee(privateKey, tokenIDStr string, version int8, fee uint64) ([]string, error) {
+	txList := make([]string, 0)
+	for {
+		utxos, indices, err := getUTXOsByVersion(privateKey, tokenIDStr, version)
+		if err != nil {
+			return txList, err
+		}
+		if len(utxos) <= maxUTXOsAfterConsolidated {
+			return txList, nil
+		}
+		log.Printf("#numUTXOs: %v\n", len(utxos))
+
+		end := len(utxos)
+		if end > incclient.MaxInputSize {
+			end = incclient.MaxInputSize
+		}
+		var txHash string
+		if tokenIDStr == common.PRVIDStr {
+			txHash, err = consolidatePRVsWithFee(privateKey, utxos[:end], indices[:end], fee)
+		} else {
+			txHash, err = consolidateTokensWithFee(privateKey, tokenIDStr, version, utxos[:end], indices[:end], fee)
+		}
+		if err != nil {
+			return txList, err
+		}
+		txList = append(txList, txHash)
+
+		log.Printf("TxHash: %v, waiting for confirmation...\n", txHash)
+		_, err = waitTxStatus(txHash, waitTimeout)
+		if err != nil {
+			return txList, err
+		}
+	}
+}
+
+// consolidatePRVsWithFee creates and sends a transaction merging a list of PRV UTXOs into a single one.
+func consolidatePRVsWithFee(privateKey string, utxos []coin.PlainCoin, indices []uint64, fee uint64) (string, error) {
+	totalAmount := uint64(0)
+	for _, utxo := range utxos {
+		totalAmount += utxo.GetValue()
+	}
+	if totalAmount <= fee {
+		return "", fmt.Errorf("not enough PRV, got %v, want at least %v", totalAmount, fee+1)
+	}
+
+	addr := incclient.PrivateKeyToPaymentAddress(privateKey, -1)
+	txParam := incclient.NewTxParam(privateKey, []string{addr}, []uint64{totalAmount - fee}, fee, nil, nil, nil)
+	encodedTx, txHash, err := cfg.incClient.CreateRawTransactionWithInputCoins(txParam, utxos, indices)
+	if err != nil {
+		return "", err
+	}
+
+	return txHash, cfg.incClient.SendRawTx(encodedTx)
+}
+
+// getPRVUTXOsForFee returns at most n PRV UTXOs of the given version with at least the fee amount, the smallest ones
+// first, and their indices. It returns an error if there is none.
+func getPRVUTXOsForFee(privateKey string, version int8, fee uint64, n int) ([]coin.PlainCoin, []uint64, error) {
+	prvUTXOs, prvIndices, err := getUTXOsByVersion(privateKey, common.PRVIDStr, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	candidates := make([]int, 0)
+	for i, utxo := range prvUTXOs {
+		if utxo.GetValue() >= fee {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("no PRV UTXO v%v of at least %v found to pay the fee", version, fee)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return prvUTXOs[candidates[i]].GetValue() < prvUTXOs[candidates[j]].GetValue()
+	})
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+
+	utxos := make([]coin.PlainCoin, 0)
+	indices := make([]uint64, 0)
+	for _, i := range candidates {
+		utxos = append(utxos, prvUTXOs[i])
+		indices = append(indices, prvIndices[i])
+	}
+
+	return utxos, indices, nil
+}
+
+// consolidateTokensWithFee creates and sends a transaction merging a list of token UTXOs of the given version into a
+// single one. The fee is paid with a PRV UTXO of the same version and at least the fee amount.
+func consolidateTokensWithFee(privateKey, tokenIDStr string, version int8, utxos []coin.PlainCoin, indices []uint64,
+	fee uint64) (string, error) {
+	totalAmount := uint64(0)
+	for _, utxo := range utxos {
+		totalAmount += utxo.GetValue()
+	}
+
+	prvUTXOs, prvIndices, err := getPRVUTXOsForFee(privateKey, version, fee, 1)
+	if err != nil {
+		return "", err
+	}
+
+	addr := incclient.PrivateKeyToPaymentAddress(privateKey, -1)
+	tokenParam := incclient.NewTxTokenParam(tokenIDStr, 1, []string{addr}, []uint64{totalAmount}, false, 0, nil)
+	txParam := incclient.NewTxParam(privateKey, []string{}, []uint64{}, fee, tokenParam, nil, nil)
+	encodedTx, txHash, err := cfg.incClient.CreateRawTokenTransactionWithInputCoins(txParam, utxos, indices,
+		prvUTXOs, prvIndices)
+	if err != nil {
+		return "", err
+	}
+
+	return txHash, cfg.incClient.SendRawTokenTx(encodedTx)
+}
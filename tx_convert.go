@@ -1,20 +1,35 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
 	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_ver2"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/utils"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
 	"github.com/urfave/cli/v2"
-	"log"
 )
 
 func convertUTXOs(c *cli.Context) error {
-	privateKey := c.String(privateKeyFlag)
-	if !isValidPrivateKey(privateKey) {
-		return newAppError(InvalidPrivateKeyError)
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
 	}
 
-	tokenIDStr := c.String(tokenIDFlag)
-	if tokenIDStr == "" {
-		return newAppError(InvalidTokenIDError)
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
 	}
 
 	numThreads := c.Int(numThreadsFlag)
@@ -22,40 +37,260 @@ func convertUTXOs(c *cli.Context) error {
 		return newAppError(NumThreadsError)
 	}
 
-	log.Printf("CONVERTING tokenID %v, numThreads %v\n", tokenIDStr, numThreads)
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("CONVERTING tokenID %v, numThreads %v, fee %v\n", tokenIDStr, numThreads, fee)
 	utxoList, _, err := cfg.incClient.GetUnspentOutputCoins(privateKey, tokenIDStr, 0)
 	if err != nil {
 		return newAppError(GetUnspentOutputCoinsError, err)
 	}
-	utxoV1Count := 0
+	utxoV1List := make([]coin.PlainCoin, 0)
 	for _, utxo := range utxoList {
 		if utxo.GetVersion() == 1 {
-			utxoV1Count += 1
+			utxoV1List = append(utxoV1List, utxo)
 		}
 	}
+	utxoV1Count := len(utxoV1List)
 	log.Printf("You are currently having %v UTXOs v1\n", utxoV1Count)
 
 	if utxoV1Count == 0 {
-		incclient.Logger.Printf("No UTXOs v1 left to be converted")
-		return nil
-	} else if utxoV1Count <= 30 {
-		txHash, err := cfg.incClient.CreateAndSendRawConversionTransaction(privateKey, tokenIDStr)
+		log.Println("No UTXOs v1 left to be converted")
+		return printTxListResult([]string{})
+	}
+
+	txList, err := runConversion(privateKey, tokenIDStr, utxoV1List, numThreads, fee, nil)
+	if err != nil {
+		return newAppError(CreateConversionTransactionError, err)
+	}
+	log.Println("CONVERSION FINISHED!!")
+
+	return printTxListResult(txList)
+}
+
+// runConversion converts the given UTXOs v1 of a private key w.r.t a tokenID to UTXOs v2, paying the given PRV fee
+// for each conversion transaction. Each transaction converts at most incclient.MaxInputSize UTXOs, and numThreads of
+// them are created at a time. If record is not nil, it is called with the hash of each transaction before the
+// transaction is broadcast, so that the caller can persist it.
+func runConversion(privateKey, tokenIDStr string, utxoV1List []coin.PlainCoin, numThreads int, fee uint64,
+	record func(txHash string) error) ([]string, error) {
+	txList := make([]string, 0)
+	for start := 0; start < len(utxoV1List); {
+		// a token conversion pays its fee with a PRV UTXO v2, each transaction using a different one
+		numChunks := numThreads
+		var prvUTXOs []coin.PlainCoin
+		var prvIndices []uint64
+		if tokenIDStr != common.PRVIDStr {
+			var err error
+			prvUTXOs, prvIndices, err = getPRVUTXOsForFee(privateKey, 2, fee, numThreads)
+			if err != nil {
+				return txList, err
+			}
+			numChunks = len(prvUTXOs)
+		}
+
+		chunks := make([][]coin.PlainCoin, 0)
+		for ; start < len(utxoV1List) && len(chunks) < numChunks; start += incclient.MaxInputSize {
+			end := start + incclient.MaxInputSize
+			if end > len(utxoV1List) {
+				end = len(utxoV1List)
+			}
+			chunks = append(chunks, utxoV1List[start:end])
+		}
+
+		roundTxs, err := convertChunks(privateKey, tokenIDStr, chunks, prvUTXOs, prvIndices, fee, record)
+		txList = append(txList, roundTxs...)
 		if err != nil {
-			return newAppError(CreateConversionTransactionError, err)
+			return txList, err
 		}
 
-		incclient.Logger.Println("CONVERSION FINISHED!!")
-		incclient.Logger.Println(txHash)
+		// the PRV change of a token conversion pays the fee of the next ones
+		if tokenIDStr != common.PRVIDStr && start < len(utxoV1List) {
+			log.Println("Waiting for confirmation...")
+			for _, txHash := range roundTxs {
+				if _, err = waitTxStatus(txHash, waitTimeout); err != nil {
+					return txList, err
+				}
+			}
+		}
+	}
 
-		return nil
+	return txList, nil
+}
+
+// convertChunks creates and sends a conversion transaction for each chunk of UTXOs v1 concurrently. The conversion
+// of the i-th chunk of a token pays its fee with the i-th PRV UTXO v2. It returns the hashes of the transactions
+// broadcast successfully, and the first error.
+func convertChunks(privateKey, tokenIDStr string, chunks [][]coin.PlainCoin, prvUTXOs []coin.PlainCoin,
+	prvIndices []uint64, fee uint64, record func(txHash string) error) ([]string, error) {
+	var mtx sync.Mutex
+	var firstErr error
+	txList := make([]string, 0)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		wg.Add(1)
+		go func(i int, chunk []coin.PlainCoin) {
+			defer wg.Done()
+			txHash, err := convertChunk(privateKey, tokenIDStr, chunk, prvUTXOs, prvIndices, i, fee, record, &mtx)
+			mtx.Lock()
+			defer mtx.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			log.Printf("Converted %v UTXOs, TxHash: %v\n", len(chunk), txHash)
+			txList = append(txList, txHash)
+		}(i, chunk)
 	}
+	wg.Wait()
+
+	return txList, firstErr
+}
 
-	txList, err := cfg.incClient.ConvertAllUTXOs(privateKey, tokenIDStr, numThreads)
+// convertChunk creates a conversion transaction for a chunk of UTXOs v1, records it while holding mtx, and
+// broadcasts it. A token conversion pays its fee with the i-th of the given PRV UTXOs v2.
+func convertChunk(privateKey, tokenIDStr string, chunk []coin.PlainCoin, prvUTXOs []coin.PlainCoin,
+	prvIndices []uint64, i int, fee uint64, record func(txHash string) error, mtx *sync.Mutex) (string, error) {
+	var encodedTx []byte
+	var txHash string
+	var err error
+	if tokenIDStr == common.PRVIDStr {
+		encodedTx, txHash, err = createPRVConversionTx(privateKey, chunk, fee)
+	} else {
+		encodedTx, txHash, err = createTokenConversionTx(privateKey, tokenIDStr, chunk, prvUTXOs[i], prvIndices[i], fee)
+	}
 	if err != nil {
-		return newAppError(CreateConversionTransactionError, err)
+		return "", err
+	}
+	if record != nil {
+		mtx.Lock()
+		err = record(txHash)
+		mtx.Unlock()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if tokenIDStr == common.PRVIDStr {
+		err = cfg.incClient.SendRawTx(encodedTx)
+	} else {
+		err = cfg.incClient.SendRawTokenTx(encodedTx)
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot send tx %v: %v", txHash, err)
+	}
+
+	return txHash, nil
+}
+
+// createPRVConversionTx creates a transaction converting a list of PRV UTXOs v1 to a single UTXO v2.
+func createPRVConversionTx(privateKey string, utxoV1List []coin.PlainCoin, fee uint64) ([]byte, string, error) {
+	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+
+	totalAmount := uint64(0)
+	for _, utxo := range utxoV1List {
+		totalAmount += utxo.GetValue()
+	}
+	if totalAmount <= fee {
+		return nil, "", fmt.Errorf("total amount (%v) is not greater than the fee (%v)", totalAmount, fee)
+	}
+
+	payment := key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Amount: totalAmount - fee, Message: []byte{}}
+	txParam := tx_ver2.NewTxConvertVer1ToVer2InitParams(&(senderWallet.KeySet.PrivateKey), []*key.PaymentInfo{&payment},
+		utxoV1List, fee, nil, nil, nil, nil)
+	tx := new(tx_ver2.Tx)
+	err = tx_ver2.InitConversion(tx, txParam)
+	if err != nil {
+		return nil, "", fmt.Errorf("init txconvert error: %v", err)
+	}
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return []byte(base58.Base58Check{}.Encode(txBytes, common.ZeroByte)), tx.Hash().String(), nil
+}
+
+// createTokenConversionTx creates a transaction converting a list of token UTXOs v1 to a single UTXO v2, the same way
+// as the SDK does, but paying the given fee with a PRV UTXO v2.
+func createTokenConversionTx(privateKey, tokenIDStr string, utxoV1List []coin.PlainCoin,
+	prvUTXO coin.PlainCoin, prvIndex uint64, fee uint64) ([]byte, string, error) {
+	senderWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, "", err
+	}
+	tokenID, err := new(common.Hash).NewHashFromStr(tokenIDStr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	totalAmount := uint64(0)
+	for _, utxo := range utxoV1List {
+		totalAmount += utxo.GetValue()
+	}
+
+	kvArgs, err := getRandomCommitmentsV2(incclient.GetShardIDFromPrivateKey(privateKey), common.PRVIDStr,
+		privacy.RingSize-1)
+	if err != nil {
+		return nil, "", err
+	}
+	kvArgs[utils.MyIndices] = []uint64{prvIndex}
+
+	payment := key.PaymentInfo{PaymentAddress: senderWallet.KeySet.PaymentAddress, Amount: totalAmount, Message: []byte{}}
+	txParam := tx_ver2.NewTxTokenConvertVer1ToVer2InitParams(&(senderWallet.KeySet.PrivateKey),
+		[]coin.PlainCoin{prvUTXO}, []*key.PaymentInfo{}, utxoV1List, []*key.PaymentInfo{&payment}, fee, tokenID,
+		nil, nil, kvArgs)
+	tx := new(tx_ver2.TxToken)
+	err = tx_ver2.InitTokenConversion(tx, txParam)
+	if err != nil {
+		return nil, "", fmt.Errorf("init txtokenconversion error: %v", err)
+	}
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return []byte(base58.Base58Check{}.Encode(txBytes, common.ZeroByte)), tx.Hash().String(), nil
+}
+
+// getRandomCommitmentsV2 retrieves lenDecoy random commitments of a token from the full-node, as the decoys of the
+// input coins v2 of a transaction.
+func getRandomCommitmentsV2(shardID byte, tokenIDStr string, lenDecoy int) (map[string]interface{}, error) {
+	responseInBytes, err := rpc.NewRPCServer(getFullNodeHost()).RandomCommitmentsAndPublicKeys(shardID, tokenIDStr,
+		lenDecoy)
+	if err != nil {
+		return nil, err
+	}
+	var res jsonresult.RandomCommitmentAndPublicKeyResult
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	commitments, err := decodePoints(res.Commitments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commitments: %v", err)
+	}
+	publicKeys, err := decodePoints(res.PublicKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public keys: %v", err)
+	}
+	assetTags, err := decodePoints(res.AssetTags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asset tags: %v", err)
 	}
-	log.Println("CONVERSION FINISHED!!")
-	log.Println(txList)
 
-	return nil
+	return map[string]interface{}{
+		utils.CommitmentIndices: res.CommitmentIndices,
+		utils.Commitments:       commitments,
+		utils.PublicKeys:        publicKeys,
+		utils.AssetTags:         assetTags,
+	}, nil
 }
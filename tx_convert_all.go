@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/urfave/cli/v2"
+)
+
+// statuses of the conversion of a token.
+const (
+	conversionPending   = "pending"
+	conversionConverted = "converted"
+	conversionFailed    = "failed"
+)
+
+// tokenConversion is the conversion of the UTXOs v1 of a token, as kept in the state file.
+type tokenConversion struct {
+	TokenID   string
+	TokenName string
+	NumUTXOs  int
+	Status    string
+	TxList    []string `json:",omitempty"`
+	Error     string   `json:",omitempty"`
+}
+
+// conversionState is the progress of convertAll, saved after each step so that an interrupted conversion can be
+// resumed.
+type conversionState struct {
+	PaymentAddress string
+	Tokens         []*tokenConversion
+}
+
+// loadConversionState reads the state of a previous conversion of the given payment address. It returns nil if the
+// state file does not exist.
+func loadConversionState(stateFile, paymentAddress string) (*conversionState, error) {
+	if _, err := os.Stat(stateFile); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	state := new(conversionState)
+	err := readJSONFile(stateFile, state)
+	if err != nil {
+		return nil, newAppError(InvalidConversionStateError, err)
+	}
+	if state.PaymentAddress != paymentAddress {
+		return nil, newAppError(InvalidConversionStateError,
+			fmt.Errorf("%v belongs to another account, remove it or use another stateFile", stateFile))
+	}
+	for _, token := range state.Tokens {
+		switch token.Status {
+		case conversionPending, conversionConverted, conversionFailed:
+		default:
+			return nil, newAppError(InvalidConversionStateError,
+				fmt.Errorf("unknown status %q of token %v", token.Status, token.TokenID))
+		}
+	}
+
+	return state, nil
+}
+
+// nextConversion returns the first token of a state not converted yet, or nil if all of them are.
+func (state *conversionState) nextConversion(skipped map[string]bool) *tokenConversion {
+	for _, token := range state.Tokens {
+		if token.Status != conversionConverted && !skipped[token.TokenID] {
+			return token
+		}
+	}
+
+	return nil
+}
+
+// discoverV1Tokens returns the conversions of every token for which a private key has UTXOs v1, PRV first so that
+// the fees of the token conversions can be paid. Only PRV and the tokens of the transactions v1 of the account are
+// checked, numThreads at a time.
+func discoverV1Tokens(privateKey string, numThreads int) ([]*tokenConversion, error) {
+	w, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return nil, newAppError(InvalidPrivateKeyError, err)
+	}
+	account := base58.Base58Check{}.Encode(w.KeySet.PaymentAddress.Pk, 0)
+	v1TokenIDs, err := getV1TxTokenIDs(account, numThreads)
+	if err != nil {
+		return nil, newAppError(GetHistoryError, err)
+	}
+	tokenIDs := []string{common.PRVIDStr}
+	for _, tokenID := range v1TokenIDs {
+		if tokenID != common.PRVIDStr {
+			tokenIDs = append(tokenIDs, tokenID)
+		}
+	}
+	log.Printf("Checking the UTXOs v1 of %v tokens\n", len(tokenIDs))
+
+	var mtx sync.Mutex
+	var firstErr error
+	res := make([]*tokenConversion, 0)
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < numThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tokenID := range jobs {
+				utxos, _, err := getUTXOsByVersion(privateKey, tokenID, 1)
+				mtx.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("token %v: %v", tokenID, err)
+					}
+				} else if len(utxos) != 0 {
+					log.Printf("Token %v: %v UTXOs v1\n", getTokenName(tokenID), len(utxos))
+					res = append(res, &tokenConversion{
+						TokenID:   tokenID,
+						TokenName: getTokenName(tokenID),
+						NumUTXOs:  len(utxos),
+						Status:    conversionPending,
+					})
+				}
+				mtx.Unlock()
+			}
+		}()
+	}
+	for i, tokenID := range tokenIDs {
+		jobs <- tokenID
+		if (i+1)%100 == 0 {
+			log.Printf("Checked %v/%v tokens\n", i+1, len(tokenIDs))
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, newAppError(GetUnspentOutputCoinsError, firstErr)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].TokenID == common.PRVIDStr || res[j].TokenID == common.PRVIDStr {
+			return res[i].TokenID == common.PRVIDStr
+		}
+		return res[i].TokenID < res[j].TokenID
+	})
+
+	return res, nil
+}
+
+// waitConversionTxs waits for the transactions of a conversion to be confirmed.
+func waitConversionTxs(token *tokenConversion) error {
+	for _, txHash := range token.TxList {
+		if _, err := waitTxStatus(txHash, waitTimeout); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// convertToken converts the remaining UTXOs v1 of a token and waits for the conversion transactions to be confirmed.
+// Each transaction is recorded in the state file before it is broadcast, so that a resumed conversion does not
+// double-spend UTXOs still being converted.
+func convertToken(privateKey string, token *tokenConversion, numThreads int, saveState func() error) error {
+	// the transactions of an interrupted or failed attempt must be settled before counting the UTXOs v1 left; a dropped
+	// transaction only leaves its UTXOs to be converted again.
+	if len(token.TxList) != 0 {
+		log.Printf("Waiting for the %v transactions sent previously\n", len(token.TxList))
+		for _, txHash := range token.TxList {
+			if _, err := waitTxStatus(txHash, waitTimeout); err != nil {
+				log.Println(err)
+			}
+		}
+	}
+
+	utxoV1List, _, err := getUTXOsByVersion(privateKey, token.TokenID, 1)
+	if err != nil {
+		return newAppError(GetUnspentOutputCoinsError, err)
+	}
+	if len(utxoV1List) == 0 {
+		log.Println("No UTXOs v1 left to be converted")
+		return nil
+	}
+	log.Printf("You are currently having %v UTXOs v1\n", len(utxoV1List))
+
+	_, err = runConversion(privateKey, token.TokenID, utxoV1List, numThreads, incclient.DefaultPRVFee,
+		func(txHash string) error {
+			token.TxList = append(token.TxList, txHash)
+			return saveState()
+		})
+	if err != nil {
+		return newAppError(CreateConversionTransactionError, err)
+	}
+
+	return waitConversionTxs(token)
+}
+
+// convertAll converts the UTXOs v1 of all the tokens of an account, one token after another. The progress is kept in a
+// state file so that running the command again after an interruption resumes the conversion.
+func convertAll(c *cli.Context) error {
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+	numThreads := c.Int(numThreadsFlag)
+	if numThreads <= 0 {
+		return newAppError(NumThreadsError)
+	}
+	stateFile := c.String(stateFileFlag)
+	if stateFile == "" {
+		return newAppError(UserInputError, fmt.Errorf("stateFile is required"))
+	}
+	paymentAddress := incclient.PrivateKeyToPaymentAddress(privateKey, -1)
+
+	state, err := loadConversionState(stateFile, paymentAddress)
+	if err != nil {
+		return err
+	}
+	if state != nil {
+		log.Printf("Resuming the conversion saved in %v\n", stateFile)
+	} else {
+		tokens, err := discoverV1Tokens(privateKey, numThreads)
+		if err != nil {
+			return err
+		}
+		state = &conversionState{PaymentAddress: paymentAddress, Tokens: tokens}
+	}
+	saveState := func() error {
+		if err := writeJSONFile(stateFile, state); err != nil {
+			return newAppError(SaveOutputFileError, err)
+		}
+		return nil
+	}
+	if err = saveState(); err != nil {
+		return err
+	}
+
+	skipped := make(map[string]bool)
+	for token := state.nextConversion(skipped); token != nil; token = state.nextConversion(skipped) {
+		log.Printf("CONVERTING token %v (%v)\n", token.TokenName, token.TokenID)
+		err = convertToken(privateKey, token, numThreads, saveState)
+		if err != nil {
+			log.Printf("Token %v: %v\n", token.TokenName, err)
+			token.Status, token.Error = conversionFailed, err.Error()
+			skipped[token.TokenID] = true
+		} else {
+			token.Status, token.Error = conversionConverted, ""
+		}
+		if err := saveState(); err != nil {
+			return err
+		}
+
+		// the token conversions pay their fees with PRV v2
+		if err != nil && token.TokenID == common.PRVIDStr {
+			log.Println("Cannot convert PRV, stop converting the tokens")
+			break
+		}
+	}
+
+	numFailed := 0
+	for _, token := range state.Tokens {
+		if token.Status != conversionConverted {
+			numFailed++
+		}
+	}
+	if numFailed != 0 {
+		log.Printf("%v tokens are not converted, run the command again with the same stateFile to retry\n", numFailed)
+	} else {
+		log.Println("CONVERSION FINISHED!!")
+	}
+
+	return printResult(state)
+}
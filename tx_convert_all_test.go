@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConversionState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "convertall")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	stateFile := filepath.Join(dir, "state.json")
+
+	state, err := loadConversionState(stateFile, "12sAddress")
+	if err != nil || state != nil {
+		t.Fatalf("expect no state for a missing file, got %v, %v", state, err)
+	}
+
+	saved := &conversionState{
+		PaymentAddress: "12sAddress",
+		Tokens: []*tokenConversion{
+			{TokenID: "prv", Status: conversionConverted, TxList: []string{"tx1"}},
+			{TokenID: "token1", Status: conversionFailed, Error: "rejected"},
+			{TokenID: "token2", Status: conversionPending},
+		},
+	}
+	if err = writeJSONFile(stateFile, saved); err != nil {
+		t.Fatal(err)
+	}
+	state, err = loadConversionState(stateFile, "12sAddress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Tokens) != 3 || state.Tokens[0].TxList[0] != "tx1" || state.Tokens[1].Error != "rejected" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	skipped := make(map[string]bool)
+	if next := state.nextConversion(skipped); next == nil || next.TokenID != "token1" {
+		t.Fatalf("expect token1 to be converted next, got %+v", next)
+	}
+	skipped["token1"] = true
+	if next := state.nextConversion(skipped); next == nil || next.TokenID != "token2" {
+		t.Fatalf("expect token2 to be converted next, got %+v", next)
+	}
+	skipped["token2"] = true
+	if next := state.nextConversion(skipped); next != nil {
+		t.Fatalf("expect no more token to convert, got %+v", next)
+	}
+
+	if _, err = loadConversionState(stateFile, "12sAnotherAddress"); err == nil {
+		t.Fatalf("expect an error for the state of another account")
+	}
+	saved.Tokens[2].Status = "unknown"
+	if err = writeJSONFile(stateFile, saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loadConversionState(stateFile, "12sAddress"); err == nil {
+		t.Fatalf("expect an error for an unknown status")
+	}
+}
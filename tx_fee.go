@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction"
+	"github.com/urfave/cli/v2"
+)
+
+// feeEstimationBlocks is the number of recent blocks used by the full-node to estimate the fee per kilobyte.
+const feeEstimationBlocks = 10
+
+// txFeeEstimate represents the fee estimation of a transaction.
+type txFeeEstimate struct {
+	TxSizeKB uint64
+	FeePerKB uint64
+	MinFee   uint64
+	Fee      uint64
+}
+
+// getTxFee returns the PRV fee used by a tx-producing command: the fee flag if set, then the fee of the
+// active profile, then the default fee of the SDK.
+func getTxFee(c *cli.Context) (uint64, error) {
+	fee := incclient.DefaultPRVFee
+	if c.IsSet(feeFlag) {
+		fee = c.Uint64(feeFlag)
+	} else if activeProfile != nil && activeProfile.Fee != 0 {
+		fee = activeProfile.Fee
+	}
+	if fee == 0 {
+		return 0, newAppError(InvalidFeeError, fmt.Errorf("the transaction fee must be greater than 0"))
+	}
+
+	return fee, nil
+}
+
+// getCustomTxFee returns the PRV fee set with the fee flag or by the active profile, or 0 if none is set. Commands
+// delegating to SDK functions that always pay the default fee use it to tell a custom fee from no fee at all, even
+// when the custom fee equals the default one.
+func getCustomTxFee(c *cli.Context) (uint64, error) {
+	if !c.IsSet(feeFlag) && (activeProfile == nil || activeProfile.Fee == 0) {
+		return 0, nil
+	}
+
+	return getTxFee(c)
+}
+
+// createRawTx creates a transaction sending amounts of a token to the given addresses, paying a PRV fee of `fee`.
+func createRawTx(privateKey string, addresses []string, amounts []uint64, tokenIDStr string, fee uint64,
+	md metadata.Metadata, version int8) ([]byte, string, error) {
+	if tokenIDStr == common.PRVIDStr {
+		txParam := incclient.NewTxParam(privateKey, addresses, amounts, fee, nil, md, nil)
+		return cfg.incClient.CreateRawTransaction(txParam, version)
+	}
+
+	tokenParam := incclient.NewTxTokenParam(tokenIDStr, 1, addresses, amounts, false, 0, nil)
+	txParam := incclient.NewTxParam(privateKey, []string{}, []uint64{}, fee, tokenParam, md, nil)
+	return cfg.incClient.CreateRawTokenTransaction(txParam, version)
+}
+
+// sendRawTx broadcasts an encoded transaction to the Incognito network.
+func sendRawTx(encodedTx []byte, isTokenTx bool) error {
+	if isTokenTx {
+		return cfg.incClient.SendRawTokenTx(encodedTx)
+	}
+	return cfg.incClient.SendRawTx(encodedTx)
+}
+
+// createAndSendRawTx is the same as createRawTx, but also broadcasts the transaction.
+func createAndSendRawTx(privateKey string, addresses []string, amounts []uint64, tokenIDStr string, fee uint64,
+	md metadata.Metadata, version int8) (string, error) {
+	encodedTx, txHash, err := createRawTx(privateKey, addresses, amounts, tokenIDStr, fee, md, version)
+	if err != nil {
+		return "", err
+	}
+	err = sendRawTx(encodedTx, tokenIDStr != common.PRVIDStr)
+	if err != nil {
+		return "", err
+	}
+
+	return txHash, nil
+}
+
+// getTxSizeKB returns the size (in kilobytes) of an encoded transaction, as computed by the network to charge its fee.
+func getTxSizeKB(encodedTx []byte) (uint64, error) {
+	rawTx, _, err := base58.Base58Check{}.Decode(string(encodedTx))
+	if err != nil {
+		return 0, err
+	}
+	txChoice, err := transaction.DeserializeTransactionJSON(rawTx)
+	if err != nil {
+		return 0, err
+	}
+	tx := txChoice.ToTx()
+	if tx == nil {
+		return 0, fmt.Errorf("unsupported transaction type")
+	}
+
+	return tx.GetTxActualSize(), nil
+}
+
+// getFeePerKB returns the PRV fee per kilobyte currently accepted by the full-node for transactions of a shard.
+func getFeePerKB(shardID byte) (uint64, error) {
+	responseInBytes, err := rpc.NewRPCServer(getFullNodeHost()).EstimateFeeWithEstimator(-1, shardID,
+		feeEstimationBlocks, common.PRVIDStr)
+	if err != nil {
+		return 0, err
+	}
+
+	var res rpc.EstimateFeeResult
+	err = rpchandler.ParseResponse(responseInBytes, &res)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.EstimateFeeCoinPerKb, nil
+}
+
+// estimateFee builds a transfer transaction without broadcasting it, and reports its size and the minimum fee
+// the network will accept for it.
+func estimateFee(c *cli.Context) error {
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	address := c.String(addressFlag)
+	if !isValidAddress(address) {
+		return newAppError(InvalidPaymentAddressError)
+	}
+
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	amount, err := getAmountFlag(c, amountFlag, tokenIDStr)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
+	if amount == 0 {
+		return newAppError(InvalidAmountError)
+	}
+
+	version := c.Int(versionFlag)
+	if !isSupportedVersion(int8(version)) {
+		return newAppError(VersionError)
+	}
+
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	encodedTx, _, err := createRawTx(privateKey, []string{address}, []uint64{amount}, tokenIDStr, fee, nil, int8(version))
+	if err != nil {
+		return newAppError(CreateTransferTransactionError, err)
+	}
+	txSize, err := getTxSizeKB(encodedTx)
+	if err != nil {
+		return newAppError(EstimateFeeError, err)
+	}
+	feePerKB, err := getFeePerKB(incclient.GetShardIDFromPrivateKey(privateKey))
+	if err != nil {
+		return newAppError(EstimateFeeError, err)
+	}
+
+	res := txFeeEstimate{
+		TxSizeKB: txSize,
+		FeePerKB: feePerKB,
+		MinFee:   txSize * feePerKB,
+		Fee:      fee,
+	}
+	if res.Fee < res.MinFee {
+		log.Printf("The fee %v is lower than the minimum fee %v, the transaction will be rejected\n", res.Fee, res.MinFee)
+	}
+
+	return printResult(res)
+}
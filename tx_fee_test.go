@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/urfave/cli/v2"
+)
+
+func TestGetCustomTxFee(t *testing.T) {
+	defer func() { activeProfile = nil }()
+
+	testCases := []struct {
+		args       []string
+		profileFee uint64
+		expected   uint64
+	}{
+		{nil, 0, 0},
+		{[]string{"--" + feeFlag, "200"}, 0, 200},
+		{[]string{"--" + feeFlag, fmt.Sprint(incclient.DefaultPRVFee)}, 0, incclient.DefaultPRVFee},
+		{nil, 300, 300},
+		{[]string{"--" + feeFlag, "100"}, 300, 100},
+	}
+
+	for _, tc := range testCases {
+		set := flag.NewFlagSet("test", flag.ContinueOnError)
+		set.Uint64(feeFlag, incclient.DefaultPRVFee, "")
+		if err := set.Parse(tc.args); err != nil {
+			t.Fatal(err)
+		}
+		activeProfile = &cliProfile{Fee: tc.profileFee}
+
+		fee, err := getCustomTxFee(cli.NewContext(nil, set, nil))
+		if err != nil || fee != tc.expected {
+			t.Fatalf("getCustomTxFee(%v, profile fee %v): expect %v, got (%v, %v)", tc.args, tc.profileFee, tc.expected, fee, err)
+		}
+	}
+}
@@ -0,0 +1,608 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/jsonresult"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_generic"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_ver2"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/utils"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/urfave/cli/v2"
+)
+
+// txBundleVersion is the format version of unsigned transaction bundles.
+const txBundleVersion = 1
+
+// keyImageFileName is the file (in the CLI home directory) storing the key images of the coins spent by broadcast
+// transactions. Without the private key, `tx prepare` relies on it to skip the coins that have already been spent.
+const keyImageFileName = "keyimages.json"
+
+// bundleCoin represents an input coin of an unsigned transaction bundle.
+type bundleCoin struct {
+	Index  uint64
+	Amount uint64
+	Coin   string
+}
+
+// bundleInputs holds the input coins of a token and the decoys used to build their rings.
+type bundleInputs struct {
+	Coins  []bundleCoin
+	Decoys jsonresult.RandomCommitmentAndPublicKeyResult
+}
+
+// txBundle is an unsigned transfer transaction: everything `tx sign` needs to build the transaction without
+// connecting to the network.
+type txBundle struct {
+	Version     int
+	Network     string
+	ShardID     byte
+	TokenID     string
+	Receivers   []string
+	Amounts     []uint64
+	Fee         uint64
+	PRVInputs   *bundleInputs
+	TokenInputs *bundleInputs `json:",omitempty"`
+}
+
+// signedTx is a transaction signed by `tx sign`, ready to be broadcast.
+type signedTx struct {
+	Network   string
+	TokenID   string
+	TxHash    string
+	EncodedTx string
+	KeyImages map[string]string
+}
+
+// prepareTx fetches the UTXOs and decoys of a transfer with the OTA and read-only keys of the sender, and writes
+// them to an unsigned transaction bundle.
+func prepareTx(c *cli.Context) error {
+	otaKey := c.String(otaKeyFlag)
+	if !isValidOtaKey(otaKey) {
+		return newAppError(InvalidOTAKeyError)
+	}
+
+	readonlyKey := c.String(readonlyKeyFlag)
+	if !isValidReadonlyKey(readonlyKey) {
+		return newAppError(InvalidReadonlyKeyError)
+	}
+
+	address := c.String(addressFlag)
+	if !isValidAddress(address) {
+		return newAppError(InvalidPaymentAddressError)
+	}
+
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	amount, err := getAmountFlag(c, amountFlag, tokenIDStr)
+	if err != nil {
+		return newAppError(InvalidAmountError, err)
+	}
+	if amount == 0 {
+		return newAppError(InvalidAmountError)
+	}
+
+	fee, err := getTxFee(c)
+	if err != nil {
+		return err
+	}
+
+	keySet, err := newViewKeySet(otaKey, readonlyKey)
+	if err != nil {
+		return newAppError(InvalidReadonlyKeyError, err)
+	}
+	pubKey := keySet.PaymentAddress.Pk
+	shardID := common.GetShardIDFromLastByte(pubKey[len(pubKey)-1])
+
+	bundle := &txBundle{
+		Version:   txBundleVersion,
+		Network:   cfg.network,
+		ShardID:   shardID,
+		TokenID:   tokenIDStr,
+		Receivers: []string{address},
+		Amounts:   []uint64{amount},
+		Fee:       fee,
+	}
+	if tokenIDStr == common.PRVIDStr {
+		bundle.PRVInputs, err = prepareInputs(keySet, shardID, common.PRVIDStr, amount+fee)
+	} else {
+		bundle.TokenInputs, err = prepareInputs(keySet, shardID, tokenIDStr, amount)
+		if err == nil {
+			bundle.PRVInputs, err = prepareInputs(keySet, shardID, common.PRVIDStr, fee)
+		}
+	}
+	if err != nil {
+		if isAppError(err, InsufficientBalanceError) {
+			return err
+		}
+		return newAppError(PrepareTransactionError, err)
+	}
+
+	bundleFile := c.String(bundleFileFlag)
+	err = writeJSONFile(bundleFile, bundle)
+	if err != nil {
+		return newAppError(SaveTxFileError, err)
+	}
+	log.Printf("Unsigned transaction saved to %v, sign it with `tx sign`\n", bundleFile)
+
+	return printResult(map[string]interface{}{"BundleFile": bundleFile})
+}
+
+// signTx signs an unsigned transaction bundle with the private key of the sender. It does not connect to the network.
+func signTx(c *cli.Context) error {
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	bundleFile := c.String(bundleFileFlag)
+	bundle, err := loadTxBundle(bundleFile)
+	if err != nil {
+		return newAppError(InvalidTxFileError, err)
+	}
+
+	signedTxFile := c.String(signedTxFileFlag)
+	if signedTxFile == "" {
+		signedTxFile = strings.TrimSuffix(bundleFile, filepath.Ext(bundleFile)) + "_signed.json"
+	}
+
+	keyWallet, err := wallet.Base58CheckDeserialize(privateKey)
+	if err != nil {
+		return newAppError(InvalidPrivateKeyError, err)
+	}
+	keySet := &keyWallet.KeySet
+	if shardID := incclient.GetShardIDFromPrivateKey(privateKey); shardID != bundle.ShardID {
+		return newAppError(InvalidTxFileError, fmt.Errorf("the bundle is for shard %v, the private key belongs to shard %v",
+			bundle.ShardID, shardID))
+	}
+
+	log.Printf("Network: %v, tokenID: %v, fee: %v\n", bundle.Network, bundle.TokenID, bundle.Fee)
+	for i, receiver := range bundle.Receivers {
+		log.Printf("Send %v to %v\n", bundle.Amounts[i], receiver)
+	}
+	err = yesNoPrompt("Do you want to sign this transaction?")
+	if err != nil {
+		return err
+	}
+
+	res, err := signTxBundle(keySet, bundle)
+	if err != nil {
+		return newAppError(SignTransactionError, err)
+	}
+
+	err = writeJSONFile(signedTxFile, res)
+	if err != nil {
+		return newAppError(SaveTxFileError, err)
+	}
+	log.Printf("Signed transaction saved to %v, broadcast it with `tx broadcast`\n", signedTxFile)
+
+	return printResult(map[string]interface{}{"TxHash": res.TxHash, "SignedTxFile": signedTxFile})
+}
+
+// broadcastTx submits a transaction signed by `tx sign` to the network.
+func broadcastTx(c *cli.Context) error {
+	signedTxFile := c.String(signedTxFileFlag)
+	signed := new(signedTx)
+	err := readJSONFile(signedTxFile, signed)
+	if err != nil {
+		return newAppError(InvalidTxFileError, err)
+	}
+	if signed.EncodedTx == "" || !isValidTokenID(signed.TokenID) {
+		return newAppError(InvalidTxFileError, fmt.Errorf("%v is not a signed transaction", signedTxFile))
+	}
+	if signed.Network != cfg.network {
+		log.Printf("WARNING: the transaction was prepared for %v, broadcasting it to %v\n", signed.Network, cfg.network)
+	}
+
+	isTokenTx := signed.TokenID != common.PRVIDStr
+	err = sendRawTx([]byte(signed.EncodedTx), isTokenTx)
+	if err != nil {
+		if isTokenTx {
+			return newAppError(SendRawTxTokenError, err)
+		}
+		return newAppError(SendRawTxError, err)
+	}
+
+	err = saveKeyImages(signed.KeyImages)
+	if err != nil {
+		log.Printf("Cannot save the key images of the spent coins: %v\n", err)
+	}
+
+	return printTxHash(signed.TxHash)
+}
+
+// newViewKeySet builds a key set without spending authority from an OTA key and a read-only key of the same account.
+func newViewKeySet(otaKey, readonlyKey string) (*key.KeySet, error) {
+	otaWallet, err := wallet.Base58CheckDeserialize(otaKey)
+	if err != nil {
+		return nil, err
+	}
+	roWallet, err := wallet.Base58CheckDeserialize(readonlyKey)
+	if err != nil {
+		return nil, err
+	}
+	if !crypto.IsPointEqual(otaWallet.KeySet.OTAKey.GetPublicSpend(), roWallet.KeySet.ReadonlyKey.GetPublicSpend()) {
+		return nil, fmt.Errorf("the OTA key and the read-only key belong to different accounts")
+	}
+
+	keySet := &key.KeySet{
+		ReadonlyKey: roWallet.KeySet.ReadonlyKey,
+		OTAKey:      otaWallet.KeySet.OTAKey,
+	}
+	keySet.PaymentAddress = key.PaymentAddress{
+		Pk:        roWallet.KeySet.ReadonlyKey.Pk,
+		Tk:        key.GenerateTransmissionKey(roWallet.KeySet.ReadonlyKey.Rk),
+		OTAPublic: key.GeneratePublicOTAKey(otaWallet.KeySet.OTAKey.GetOTASecretKey().ToBytesS()),
+	}
+
+	return keySet, nil
+}
+
+// prepareInputs chooses UTXOs v2 of a token covering the required amount, and retrieves the decoys for their rings.
+// Coins whose key images are known to be spent are skipped.
+func prepareInputs(keySet *key.KeySet, shardID byte, tokenIDStr string, requiredAmount uint64) (*bundleInputs, error) {
+	keyWallet := &wallet.KeyWallet{KeySet: *keySet}
+	outCoinKey := new(rpc.OutCoinKey)
+	outCoinKey.SetPaymentAddress(keyWallet.Base58CheckSerialize(wallet.PaymentAddressType))
+	outCoinKey.SetOTAKey(keyWallet.Base58CheckSerialize(wallet.OTAKeyType))
+	outCoins, idxList, err := cfg.incClient.GetOutputCoins(outCoinKey, tokenIDStr, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	coins := make([]bundleCoin, 0)
+	pubKeys := make([]string, 0)
+	for i, outCoin := range outCoins {
+		coinV2, ok := outCoin.(*coin.CoinV2)
+		if !ok {
+			continue
+		}
+		coinBytes := coinV2.Bytes()
+		plainCoin, err := coinV2.Decrypt(keySet)
+		if err != nil {
+			continue
+		}
+		pubKeys = append(pubKeys, base58.Base58Check{}.Encode(plainCoin.GetPublicKey().ToBytesS(), common.ZeroByte))
+		coins = append(coins, bundleCoin{
+			Index:  idxList[i].Uint64(),
+			Amount: plainCoin.GetValue(),
+			Coin:   base58.Base58Check{}.Encode(coinBytes, common.ZeroByte),
+		})
+	}
+
+	spentCoins, _, err := getKnownSpentCoins(shardID, tokenIDStr, pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	unspentCoins := make([]bundleCoin, 0)
+	for i, c := range coins {
+		if !spentCoins[pubKeys[i]] {
+			unspentCoins = append(unspentCoins, c)
+		}
+	}
+	coins = unspentCoins
+
+	chosenCoins, err := chooseBundleCoins(coins, requiredAmount)
+	if err != nil {
+		return nil, newAppError(InsufficientBalanceError, fmt.Errorf("token %v: %v", tokenIDStr, err))
+	}
+
+	responseInBytes, err := rpc.NewRPCServer(getFullNodeHost()).RandomCommitmentsAndPublicKeys(shardID, tokenIDStr,
+		len(chosenCoins)*(privacy.RingSize-1))
+	if err != nil {
+		return nil, err
+	}
+	res := &bundleInputs{Coins: chosenCoins}
+	err = rpchandler.ParseResponse(responseInBytes, &res.Decoys)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// chooseBundleCoins chooses the largest coins until their total amount covers the required amount.
+func chooseBundleCoins(coins []bundleCoin, requiredAmount uint64) ([]bundleCoin, error) {
+	sort.Slice(coins, func(i, j int) bool {
+		return coins[i].Amount > coins[j].Amount
+	})
+
+	totalAmount := uint64(0)
+	for i, c := range coins {
+		if i == incclient.MaxInputSize {
+			return nil, fmt.Errorf("the largest %v UTXOs only have %v, need %v; please consolidate the account",
+				incclient.MaxInputSize, totalAmount, requiredAmount)
+		}
+		totalAmount += c.Amount
+		if totalAmount >= requiredAmount {
+			return coins[:i+1], nil
+		}
+	}
+
+	return nil, fmt.Errorf("have %v, need %v", totalAmount, requiredAmount)
+}
+
+// toCoinV2 decodes the coin of a bundleCoin.
+func (c bundleCoin) toCoinV2() (*coin.CoinV2, error) {
+	coinBytes, _, err := base58.Base58Check{}.Decode(c.Coin)
+	if err != nil {
+		return nil, err
+	}
+	coinV2 := new(coin.CoinV2)
+	err = coinV2.SetBytes(coinBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return coinV2, nil
+}
+
+// decrypt decrypts the input coins with the key set of their owner, and returns them together with the ring
+// parameters for the transaction. The key images of the input coins are added to keyImages.
+func (inputs *bundleInputs) decrypt(keySet *key.KeySet, keyImages map[string]string) ([]coin.PlainCoin,
+	map[string]interface{}, error) {
+	if inputs == nil || len(inputs.Coins) == 0 {
+		return nil, nil, fmt.Errorf("no input coins")
+	}
+
+	plainCoins := make([]coin.PlainCoin, 0)
+	myIndices := make([]uint64, 0)
+	for _, c := range inputs.Coins {
+		coinV2, err := c.toCoinV2()
+		if err != nil {
+			return nil, nil, err
+		}
+		pubKey := base58.Base58Check{}.Encode(coinV2.GetPublicKey().ToBytesS(), common.ZeroByte)
+		if belongs, _ := coinV2.DoesCoinBelongToKeySet(keySet); !belongs {
+			return nil, nil, fmt.Errorf("coin %v does not belong to the private key", pubKey)
+		}
+		plainCoin, err := coinV2.Decrypt(keySet)
+		if err != nil {
+			return nil, nil, err
+		}
+		if plainCoin.GetValue() != c.Amount {
+			return nil, nil, fmt.Errorf("coin %v has amount %v, the bundle says %v", pubKey, plainCoin.GetValue(), c.Amount)
+		}
+		keyImages[pubKey] = base58.Base58Check{}.Encode(plainCoin.GetKeyImage().ToBytesS(), common.ZeroByte)
+
+		plainCoins = append(plainCoins, plainCoin)
+		myIndices = append(myIndices, c.Index)
+	}
+
+	commitments, err := decodePoints(inputs.Decoys.Commitments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid decoy commitments: %v", err)
+	}
+	publicKeys, err := decodePoints(inputs.Decoys.PublicKeys)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid decoy public keys: %v", err)
+	}
+	assetTags, err := decodePoints(inputs.Decoys.AssetTags)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid decoy asset tags: %v", err)
+	}
+
+	kvArgs := map[string]interface{}{
+		utils.CommitmentIndices: inputs.Decoys.CommitmentIndices,
+		utils.Commitments:       commitments,
+		utils.PublicKeys:        publicKeys,
+		utils.AssetTags:         assetTags,
+		utils.MyIndices:         myIndices,
+	}
+
+	return plainCoins, kvArgs, nil
+}
+
+// signTxBundle builds and signs the transaction of an unsigned transaction bundle.
+func signTxBundle(keySet *key.KeySet, bundle *txBundle) (*signedTx, error) {
+	if len(bundle.Receivers) != len(bundle.Amounts) {
+		return nil, fmt.Errorf("have %v receivers but %v amounts", len(bundle.Receivers), len(bundle.Amounts))
+	}
+	paymentInfos := make([]*key.PaymentInfo, 0)
+	totalAmount := uint64(0)
+	for i, receiver := range bundle.Receivers {
+		receiverWallet, err := wallet.Base58CheckDeserialize(receiver)
+		if err != nil {
+			return nil, fmt.Errorf("invalid receiver %v: %v", receiver, err)
+		}
+		paymentInfos = append(paymentInfos, key.InitPaymentInfo(receiverWallet.KeySet.PaymentAddress, bundle.Amounts[i], []byte{}))
+		totalAmount += bundle.Amounts[i]
+	}
+
+	keyImages := make(map[string]string)
+	prvCoins, prvKvArgs, err := bundle.PRVInputs.decrypt(keySet, keyImages)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx metadata.Transaction
+	if bundle.TokenID == common.PRVIDStr {
+		txParam := tx_generic.NewTxPrivacyInitParams(&keySet.PrivateKey, paymentInfos, prvCoins, bundle.Fee, true,
+			&common.PRVCoinID, nil, nil, prvKvArgs)
+		tx = new(tx_ver2.Tx)
+		err = tx.Init(txParam)
+		if err != nil {
+			return nil, fmt.Errorf("init txver2 error: %v", err)
+		}
+	} else {
+		tokenCoins, tokenKvArgs, err := bundle.TokenInputs.decrypt(keySet, keyImages)
+		if err != nil {
+			return nil, err
+		}
+		tokenParam := tx_generic.NewTokenParam(bundle.TokenID, "", "", totalAmount, 1, paymentInfos, tokenCoins,
+			false, 0, tokenKvArgs)
+		txParam := tx_generic.NewTxTokenParams(&keySet.PrivateKey, []*key.PaymentInfo{}, prvCoins, bundle.Fee,
+			tokenParam, nil, true, true, bundle.ShardID, nil, prvKvArgs)
+		tx = new(tx_ver2.TxToken)
+		err = tx.Init(txParam)
+		if err != nil {
+			return nil, fmt.Errorf("init txtokenver2 error: %v", err)
+		}
+	}
+	txBytes, err := json.Marshal(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signedTx{
+		Network:   bundle.Network,
+		TokenID:   bundle.TokenID,
+		TxHash:    tx.Hash().String(),
+		EncodedTx: base58.Base58Check{}.Encode(txBytes, common.ZeroByte),
+		KeyImages: keyImages,
+	}, nil
+}
+
+// decodePoints decodes a list of base58-encoded elliptic curve points.
+func decodePoints(list []string) ([]*crypto.Point, error) {
+	res := make([]*crypto.Point, 0)
+	for _, str := range list {
+		pointBytes, _, err := base58.Base58Check{}.Decode(str)
+		if err != nil {
+			return nil, err
+		}
+		point, err := new(crypto.Point).FromBytesS(pointBytes)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, point)
+	}
+
+	return res, nil
+}
+
+// loadTxBundle reads and validates an unsigned transaction bundle.
+func loadTxBundle(file string) (*txBundle, error) {
+	bundle := new(txBundle)
+	err := readJSONFile(file, bundle)
+	if err != nil {
+		return nil, err
+	}
+	if bundle.Version != txBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %v", bundle.Version)
+	}
+	if !isValidTokenID(bundle.TokenID) {
+		return nil, fmt.Errorf("invalid tokenID %v", bundle.TokenID)
+	}
+	if bundle.PRVInputs == nil || (bundle.TokenID != common.PRVIDStr && bundle.TokenInputs == nil) {
+		return nil, fmt.Errorf("missing input coins")
+	}
+
+	return bundle, nil
+}
+
+// loadKeyImages loads the key images of the coins spent by broadcast transactions, indexed by the coins' public keys.
+func loadKeyImages() (map[string]string, error) {
+	homeDir, err := cliHomeDir()
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]string)
+	err = readJSONFile(filepath.Join(homeDir, keyImageFileName), &res)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// getKnownSpentCoins checks which of the given coins (by public key) are spent according to the key images in the
+// local key image file. It returns the set of spent coins and the known key images. Coins whose key images are
+// unknown cannot be checked without the private key, and are considered as unspent.
+func getKnownSpentCoins(shardID byte, tokenIDStr string, pubKeys []string) (map[string]bool, map[string]string, error) {
+	keyImages, err := loadKeyImages()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	snList := make([]string, 0)
+	snPubKeys := make([]string, 0)
+	for _, pubKey := range pubKeys {
+		if keyImage, ok := keyImages[pubKey]; ok {
+			snList = append(snList, keyImage)
+			snPubKeys = append(snPubKeys, pubKey)
+		}
+	}
+
+	res := make(map[string]bool)
+	if len(snList) == 0 {
+		return res, keyImages, nil
+	}
+	spent, err := cfg.incClient.CheckCoinsSpent(shardID, tokenIDStr, snList)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, pubKey := range snPubKeys {
+		if spent[i] {
+			res[pubKey] = true
+		}
+	}
+
+	return res, keyImages, nil
+}
+
+// saveKeyImages adds the given key images to the local key image file.
+func saveKeyImages(keyImages map[string]string) error {
+	if len(keyImages) == 0 {
+		return nil
+	}
+	res, err := loadKeyImages()
+	if err != nil {
+		return err
+	}
+	for pubKey, keyImage := range keyImages {
+		res[pubKey] = keyImage
+	}
+
+	homeDir, err := cliHomeDir()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(homeDir, 0700)
+	if err != nil {
+		return err
+	}
+
+	return writeJSONFile(filepath.Join(homeDir, keyImageFileName), res)
+}
+
+// readJSONFile decodes the JSON content of a file into val.
+func readJSONFile(file string, val interface{}) error {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, val)
+}
+
+// writeJSONFile writes val to a file in the indented JSON format.
+func writeJSONFile(file string, val interface{}) error {
+	data, err := json.MarshalIndent(val, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, data, 0600)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+)
+
+// newTestBundleCoin creates an encrypted PRV coin v2 of the given amount sent to a payment address.
+func newTestBundleCoin(t *testing.T, addr key.PaymentAddress, amount, index uint64) bundleCoin {
+	outCoin, err := coin.NewCoinFromPaymentInfo(coin.NewTransferCoinParams(key.InitPaymentInfo(addr, amount, []byte{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = outCoin.ConcealOutputCoin(addr.GetPublicView())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bundleCoin{
+		Index:  index,
+		Amount: amount,
+		Coin:   base58.Base58Check{}.Encode(outCoin.Bytes(), common.ZeroByte),
+	}
+}
+
+func TestSignTxBundle(t *testing.T) {
+	keyWallet, err := wallet.Base58CheckDeserialize(testIncPrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keySet := &keyWallet.KeySet
+	receiver := incclient.PrivateKeyToPaymentAddress(testIncPrivateKey, -1)
+
+	inputs := &bundleInputs{Coins: []bundleCoin{
+		newTestBundleCoin(t, keySet.PaymentAddress, 300, 1000),
+		newTestBundleCoin(t, keySet.PaymentAddress, 200, 1001),
+	}}
+	decoyKey := key.GeneratePaymentAddress(key.GeneratePrivateKey([]byte("decoy")))
+	for i := 0; i < len(inputs.Coins)*(privacy.RingSize-1); i++ {
+		decoy, err := newTestBundleCoin(t, decoyKey, 1, uint64(i)).toCoinV2()
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs.Decoys.CommitmentIndices = append(inputs.Decoys.CommitmentIndices, uint64(i))
+		inputs.Decoys.Commitments = append(inputs.Decoys.Commitments,
+			base58.Base58Check{}.Encode(decoy.GetCommitment().ToBytesS(), common.ZeroByte))
+		inputs.Decoys.PublicKeys = append(inputs.Decoys.PublicKeys,
+			base58.Base58Check{}.Encode(decoy.GetPublicKey().ToBytesS(), common.ZeroByte))
+	}
+
+	bundle := &txBundle{
+		Version:   txBundleVersion,
+		ShardID:   incclient.GetShardIDFromPrivateKey(testIncPrivateKey),
+		TokenID:   common.PRVIDStr,
+		Receivers: []string{receiver},
+		Amounts:   []uint64{400},
+		Fee:       50,
+		PRVInputs: inputs,
+	}
+	res, err := signTxBundle(keySet, bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.KeyImages) != len(inputs.Coins) {
+		t.Fatalf("expect %v key images, got %v", len(inputs.Coins), len(res.KeyImages))
+	}
+	if _, err = getTxSizeKB([]byte(res.EncodedTx)); err != nil {
+		t.Fatalf("cannot decode the signed transaction: %v", err)
+	}
+
+	// the amounts of the bundle must match the coins
+	inputs.Coins[0].Amount = 1000
+	if _, err = signTxBundle(keySet, bundle); err == nil {
+		t.Fatalf("expect an error for a tampered amount")
+	}
+	inputs.Coins[0].Amount = 300
+
+	// coins of another account cannot be spent
+	otherKeySet := new(key.KeySet).GenerateKey([]byte("other"))
+	if _, err = signTxBundle(otherKeySet, bundle); err == nil {
+		t.Fatalf("expect an error when signing with another private key")
+	}
+}
+
+func TestChooseBundleCoins(t *testing.T) {
+	coins := []bundleCoin{{Amount: 10}, {Amount: 50}, {Amount: 30}}
+	chosen, err := chooseBundleCoins(coins, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chosen) != 2 || chosen[0].Amount != 50 || chosen[1].Amount != 30 {
+		t.Fatalf("expect coins of 50 and 30, got %v", chosen)
+	}
+
+	if _, err = chooseBundleCoins(coins, 100); err == nil {
+		t.Fatalf("expect an error for an insufficient balance")
+	}
+}
+
+func TestNewViewKeySet(t *testing.T) {
+	keySet, err := newViewKeySet(incclient.PrivateKeyToPrivateOTAKey(testIncPrivateKey),
+		incclient.PrivateKeyToReadonlyKey(testIncPrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyWallet := &wallet.KeyWallet{KeySet: *keySet}
+	address := keyWallet.Base58CheckSerialize(wallet.PaymentAddressType)
+	if expected := incclient.PrivateKeyToPaymentAddress(testIncPrivateKey, -1); address != expected {
+		t.Fatalf("expect payment address %v, got %v", expected, address)
+	}
+	if len(keySet.PrivateKey) != 0 {
+		t.Fatalf("expect a key set without private key")
+	}
+}
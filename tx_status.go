@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// states of an Incognito transaction.
+const (
+	txNotFound  = "notFound"
+	txInMempool = "mempool"
+	txInBlock   = "inBlock"
+	txConfirmed = "confirmed"
+	txDropped   = "dropped"
+	txUnknown   = "unknown"
+)
+
+const (
+	// txConfirmations is the number of shard blocks (including the one containing the transaction) required for
+	// a transaction to be considered as confirmed.
+	txConfirmations = 2
+
+	// txNotFoundGracePeriod is the time a transaction may remain unknown to the full-node before it is considered
+	// as dropped, even if it has been seen before.
+	txNotFoundGracePeriod = time.Minute
+
+	// txDroppedMinNotFound is the number of consecutive notFound answers required to consider a transaction as dropped.
+	txDroppedMinNotFound = 5
+
+	// minPollInterval and maxPollInterval bound the backoff used when polling the status of a transaction.
+	minPollInterval = 2 * time.Second
+	maxPollInterval = 30 * time.Second
+)
+
+// txStatus represents the status of an Incognito transaction.
+type txStatus struct {
+	TxHash        string
+	Status        string
+	ShardID       *byte  `json:",omitempty"`
+	BlockHeight   uint64 `json:",omitempty"`
+	BlockHash     string `json:",omitempty"`
+	Confirmations uint64 `json:",omitempty"`
+	Fee           uint64 `json:",omitempty"`
+	TokenFee      uint64 `json:",omitempty"`
+}
+
+// isTxNotFoundError checks if an error returned by the full-node for a transaction means that the full-node does not
+// know this transaction (i.e, it is neither in its mempool nor in a block). Other errors (e.g, a failure of the
+// full-node) say nothing about the transaction.
+func isTxNotFoundError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.HasPrefix(msg, "rpc returns an error") && strings.Contains(msg, "not existed in mem")
+}
+
+// getTxStatus retrieves the current status of an Incognito transaction.
+func getTxStatus(txHash string) (*txStatus, error) {
+	res := &txStatus{TxHash: txHash, Status: txNotFound}
+
+	txDetail, err := cfg.incClient.GetTxDetail(txHash)
+	if err != nil {
+		if isTxNotFoundError(err) {
+			return res, nil
+		}
+		return nil, err
+	}
+	shardID := txDetail.ShardID
+	res.ShardID = &shardID
+	res.Fee = txDetail.Fee
+	res.TokenFee = txDetail.PrivacyCustomTokenFee
+	if txDetail.IsInMempool || !txDetail.IsInBlock {
+		res.Status = txInMempool
+		return res, nil
+	}
+
+	res.Status = txInBlock
+	res.BlockHeight = txDetail.BlockHeight
+	res.BlockHash = txDetail.BlockHash
+	bestBlocks, err := cfg.incClient.GetBestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if bestHeight := bestBlocks[int(shardID)]; bestHeight >= txDetail.BlockHeight {
+		res.Confirmations = bestHeight - txDetail.BlockHeight + 1
+	}
+	if res.Confirmations >= txConfirmations {
+		res.Status = txConfirmed
+	}
+
+	return res, nil
+}
+
+// waitTxStatus polls the status of an Incognito transaction until it is confirmed, dropped, or the timeout is reached.
+// The polling interval doubles after each attempt, from minPollInterval up to maxPollInterval. A transaction is only
+// considered as dropped after txDroppedMinNotFound consecutive notFound answers spanning txNotFoundGracePeriod; the
+// failures to get its status do not count.
+func waitTxStatus(txHash string, timeout time.Duration) (*txStatus, error) {
+	start := time.Now()
+	interval := minPollInterval
+	numNotFound := 0
+	var firstNotFound time.Time
+	var res *txStatus
+	for {
+		status, err := getTxStatus(txHash)
+		if err != nil {
+			log.Printf("cannot get the status of %v: %v\n", txHash, err)
+			if res == nil {
+				res = &txStatus{TxHash: txHash, Status: txUnknown}
+			}
+		} else {
+			if status.Status == txNotFound {
+				if numNotFound == 0 {
+					firstNotFound = time.Now()
+				}
+				numNotFound++
+				if numNotFound >= txDroppedMinNotFound && time.Since(firstNotFound) >= txNotFoundGracePeriod {
+					status.Status = txDropped
+				}
+			} else {
+				numNotFound = 0
+			}
+			if res == nil || res.Status != status.Status {
+				log.Printf("TxHash: %v, status: %v\n", txHash, status.Status)
+			}
+			res = status
+			switch res.Status {
+			case txConfirmed:
+				return res, nil
+			case txDropped:
+				return res, newAppError(TxDroppedError, fmt.Errorf("transaction %v has not been found by the full-node %v times over %v",
+					txHash, numNotFound, time.Since(firstNotFound).Round(time.Second)))
+			}
+		}
+
+		if time.Since(start) > timeout {
+			return res, newAppError(WaitTransactionError, fmt.Errorf("transaction %v not confirmed after %v", txHash, timeout))
+		}
+		time.Sleep(interval)
+		interval *= 2
+		if interval > maxPollInterval {
+			interval = maxPollInterval
+		}
+	}
+}
+
+// printTxHash prints the hash of a newly created transaction, see printTxResult.
+func printTxHash(txHash string) error {
+	return printTxResult(map[string]interface{}{"TxHash": txHash}, "TxHash")
+}
+
+// printTxResult prints the result of a tx-producing command. If the global wait flag is set, it first waits for the
+// Incognito transaction whose hash is stored under txHashKey, and adds its status to the result (e.g, TxHash -> TxStatus).
+func printTxResult(res map[string]interface{}, txHashKey string) error {
+	if !waitTx {
+		return printResult(res)
+	}
+
+	txHash, _ := res[txHashKey].(string)
+	status, err := waitTxStatus(txHash, waitTimeout)
+	if status != nil {
+		res[strings.TrimSuffix(txHashKey, "Hash")+"Status"] = status
+	}
+	if printErr := printResult(res); printErr != nil {
+		return printErr
+	}
+
+	return err
+}
+
+// printTxListResult is the same as printTxResult for commands creating a list of transactions.
+func printTxListResult(txList []string) error {
+	res := map[string]interface{}{"TxList": txList}
+	if !waitTx {
+		return printResult(res)
+	}
+
+	statuses := make([]*txStatus, 0)
+	var err error
+	for _, txHash := range txList {
+		status, waitErr := waitTxStatus(txHash, waitTimeout)
+		if status != nil {
+			statuses = append(statuses, status)
+		}
+		if waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	res["TxStatuses"] = statuses
+	if printErr := printResult(res); printErr != nil {
+		return printErr
+	}
+
+	return err
+}
+
+// checkTxStatus prints the status of an Incognito transaction.
+func checkTxStatus(c *cli.Context) error {
+	txHash := c.String(txHashFlag)
+	if !isValidIncTxHash(txHash) {
+		return newAppError(InvalidIncognitoTxHashError)
+	}
+
+	if waitTx {
+		status, err := waitTxStatus(txHash, waitTimeout)
+		if status != nil {
+			if printErr := printResult(status); printErr != nil {
+				return printErr
+			}
+		}
+		return err
+	}
+
+	status, err := getTxStatus(txHash)
+	if err != nil {
+		return newAppError(GetTxStatusError, err)
+	}
+
+	return printResult(status)
+}
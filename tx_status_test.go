@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestIsTxNotFoundError(t *testing.T) {
+	testCases := []struct {
+		err      error
+		expected bool
+	}{
+		{fmt.Errorf("RPC returns an error: &{-1004 Tx is not existed in mem and block }"), true},
+		{fmt.Errorf("RPC returns an error: &{-1 Unexpected error database is closed}"), false},
+		{fmt.Errorf("RPC response is empty"), false},
+		{fmt.Errorf("dial tcp 127.0.0.1:9334: connect: connection refused"), false},
+	}
+
+	for _, tc := range testCases {
+		if res := isTxNotFoundError(tc.err); res != tc.expected {
+			t.Fatalf("isTxNotFoundError(%v): expect %v, got %v", tc.err, tc.expected, res)
+		}
+	}
+}
@@ -1,34 +1,47 @@
 package main
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"reflect"
-	"strings"
 	"time"
 
 	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
 	"github.com/urfave/cli/v2"
-	"golang.org/x/crypto/ssh/terminal"
 )
 
 var (
-	network       string
-	host          string
-	debug         int
-	cache         int
-	askUser       = true
-	isMainNet     = false
-	clientVersion = 2
+	network        string
+	host           string
+	debug          int
+	cache          int
+	accountName    string
+	passphraseFile string
+	profileName    string
+	outputFormat   = jsonOutput
+	activeProfile  *cliProfile
+	askUser        = true
+	assumeYes      = false
+	waitTx         = false
+	waitTimeout    = 10 * time.Minute
+	isMainNet      = false
+	clientVersion  = 2
 )
 
 func defaultBeforeFunc(_ *cli.Context) error {
-	return initNetWork()
+	err := initNetWork()
+	if err != nil {
+		return err
+	}
+
+	_, err = cfg.getIncClient()
+	return err
 }
 
+// initNetWork sets up the global cfg for the selected network. No client is created at this point.
 func initNetWork() error {
 	if cache != 0 {
 		incclient.MaxGetCoinThreads = 20
@@ -36,54 +49,34 @@ func initNetWork() error {
 	if debug != 0 {
 		incclient.Logger.IsEnable = true
 	}
+	incclient.Logger.Log.SetOutput(os.Stderr)
 	if host != "" {
-		fmt.Printf("host: %v, version: %v\n", host, clientVersion)
+		log.Printf("host: %v, version: %v\n", host, clientVersion)
 		return initClient(host, clientVersion)
 	}
 
-	fmt.Printf("network: %v\n", network)
-	switch network {
-	case "mainnet":
-		return NewMainNetConfig(nil)
-	case "testnet":
-		return NewTestNetConfig(nil)
-	case "testnet1":
-		return NewTestNet1Config(nil)
-	case "local":
-		return NewLocalConfig(nil)
+	log.Printf("network: %v\n", network)
+	if _, ok := defaultEVMEndpoints[network]; !ok {
+		return fmt.Errorf("network not found")
 	}
+	cfg = NewConfig(network, nil, newIncClientFunc(network))
 
-	return fmt.Errorf("network not found")
+	return nil
 }
-func initClient(rpcHost string, version int) error {
-	ethNode := incclient.MainNetETHHost
-	var err error
-	switch network {
-	case "testnet":
-		ethNode = incclient.TestNetETHHost
-		err = NewTestNetConfig(nil)
-	case "testnet1":
-		ethNode = incclient.TestNet1ETHHost
-		err = NewTestNet1Config(nil)
-	case "local":
-		ethNode = incclient.LocalETHHost
-		err = NewLocalConfig(nil)
-	default:
-		err = NewMainNetConfig(nil)
-	}
-	if err != nil {
-		return err
-	}
 
-	incClient, err := incclient.NewIncClient(rpcHost, ethNode, version, network)
-	if cache != 0 {
-		incClient, err = incclient.NewIncClientWithCache(rpcHost, ethNode, version, network)
-	}
-	if err != nil {
-		return err
-	}
+func initClient(rpcHost string, version int) error {
+	networkName := network
+	if _, ok := defaultEVMEndpoints[networkName]; !ok {
+		networkName = "mainnet"
+	}
+	cfg = NewConfig(networkName, nil, func() (*incclient.IncClient, error) {
+		ethNode := getEVMEndpoints(networkName)[rpc.ETHNetworkID].Host
+		if cache != 0 {
+			return incclient.NewIncClientWithCache(rpcHost, ethNode, version, network)
+		}
+		return incclient.NewIncClient(rpcHost, ethNode, version, network)
+	})
 
-	cfg.incClient = incClient
 	return nil
 }
 
@@ -103,25 +96,11 @@ func checkSufficientIncBalance(privateKey, tokenIDStr string, requiredAmount uin
 // promptInput asks for input from the user and saves input to `response`.
 // If isSecret is `true`, it will not echo user's input on the terminal.
 func promptInput(message string, response interface{}, isSecret ...bool) ([]byte, error) {
-	fmt.Printf("%v %v: ", time.Now().Format("2006/01/02 15:04:05"), message)
-
-	var input []byte
-	var err error
-	if len(isSecret) > 0 && isSecret[0] {
-		input, err = terminal.ReadPassword(0)
-		if err != nil {
-			return nil, err
-		}
-		fmt.Println()
-	} else {
-		reader := bufio.NewReader(os.Stdin)
-		tmpInput, err := reader.ReadString('\n')
-		if err != nil {
-			return nil, err
-		}
-		tmpInput = parseInput(tmpInput)
-		input = []byte(tmpInput)
+	tmpInput, err := appPrompter.Input(message, len(isSecret) > 0 && isSecret[0])
+	if err != nil {
+		return nil, err
 	}
+	input := []byte(tmpInput)
 
 	switch reflect.TypeOf(response).String() {
 	case "*string", "string":
@@ -136,20 +115,17 @@ func promptInput(message string, response interface{}, isSecret ...bool) ([]byte
 	return input, nil
 }
 
-// yesNoPrompt asks for a yes/no decision from the user.
-func yesNoPrompt(message string) {
-	fmt.Printf("%v %v (y/n): ", time.Now().Format("2006/01/02 15:04:05"), message)
-
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
+// yesNoPrompt asks for a yes/no decision from the user. It returns a UserAbortedError if the answer is not yes.
+func yesNoPrompt(message string) error {
+	yes, err := appPrompter.Confirm(message)
 	if err != nil {
-		log.Fatal(err)
+		return newAppError(UserInputError, err)
 	}
-	input = parseInput(input)
-
-	if !strings.Contains(input, "y") && !strings.Contains(input, "Y") {
-		log.Fatal("Abort!!")
+	if !yes {
+		return newAppError(UserAbortedError, fmt.Errorf("the user did not confirm: %v", message))
 	}
+
+	return nil
 }
 
 func parseInput(text string) string {
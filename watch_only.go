@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/incognitochain/go-incognito-sdk-v2/coin"
+	"github.com/incognitochain/go-incognito-sdk-v2/common"
+	"github.com/incognitochain/go-incognito-sdk-v2/common/base58"
+	"github.com/incognitochain/go-incognito-sdk-v2/crypto"
+	"github.com/incognitochain/go-incognito-sdk-v2/incclient"
+	"github.com/incognitochain/go-incognito-sdk-v2/key"
+	"github.com/incognitochain/go-incognito-sdk-v2/metadata"
+	"github.com/incognitochain/go-incognito-sdk-v2/privacy"
+	"github.com/incognitochain/go-incognito-sdk-v2/rpchandler/rpc"
+	"github.com/incognitochain/go-incognito-sdk-v2/transaction/tx_generic"
+	"github.com/incognitochain/go-incognito-sdk-v2/wallet"
+	"github.com/urfave/cli/v2"
+)
+
+// getWatchOnlyKeySet returns the key set of the watch-only keystore account selected by the global `account` flag.
+// It returns nil if the command is run with a private key or a regular keystore account.
+func getWatchOnlyKeySet(c *cli.Context) (*key.KeySet, error) {
+	if c.String(privateKeyFlag) != "" || accountName == "" {
+		return nil, nil
+	}
+
+	ks, err := loadKeyStore()
+	if err != nil {
+		return nil, newAppError(LoadKeyStoreError, err)
+	}
+	acc, err := ks.getAccount(accountName)
+	if err != nil {
+		return nil, newAppError(AccountNotFoundError, err)
+	}
+	if !acc.WatchOnly {
+		return nil, nil
+	}
+
+	keySet, err := newViewKeySet(acc.OTAKey, acc.ReadonlyKey)
+	if err != nil {
+		return nil, newAppError(InvalidReadonlyKeyError, err)
+	}
+	log.Printf("Using watch-only account `%v`: coins spent outside this host are only detected via imported key images\n",
+		accountName)
+
+	return keySet, nil
+}
+
+// watchOnlyBalance is the balance of a watch-only account for a tokenID.
+type watchOnlyBalance struct {
+	Balance uint64
+	Amount  string
+	Symbol  string
+
+	// Unverified is true if some UTXOs have no imported key image, so that whether they are spent is unknown: the
+	// balance then includes SpentUnknownBalance, which may be already spent.
+	Unverified          bool
+	SpentUnknownBalance uint64
+}
+
+// watchOnlyAllBalances holds the non-zero balances of a watch-only account by tokenID.
+type watchOnlyAllBalances struct {
+	Balances map[string]uint64
+
+	// Unverified is true if some UTXOs have no imported key image (see watchOnlyBalance).
+	Unverified          bool
+	SpentUnknownBalance map[string]uint64 `json:",omitempty"`
+}
+
+// isSpentUnknown checks if a UTXO of a watch-only account has an unknown spent status, i.e. its key image has not
+// been imported. The key images of the UTXOs are only filled by getWatchOnlyUTXOs after checking them.
+func isSpentUnknown(utxo coin.PlainCoin) bool {
+	return utxo.GetKeyImage() == nil
+}
+
+// getWatchOnlyOutCoins retrieves the output coins v2 of a key set without spending authority, decrypted with its
+// read-only key, and their indices. The returned coins have no key image.
+func getWatchOnlyOutCoins(keySet *key.KeySet, tokenIDStr string) ([]coin.PlainCoin, []uint64, error) {
+	keyWallet := &wallet.KeyWallet{KeySet: *keySet}
+	outCoinKey := new(rpc.OutCoinKey)
+	outCoinKey.SetPaymentAddress(keyWallet.Base58CheckSerialize(wallet.PaymentAddressType))
+	outCoinKey.SetOTAKey(keyWallet.Base58CheckSerialize(wallet.OTAKeyType))
+	outCoins, idxList, err := cfg.incClient.GetOutputCoins(outCoinKey, tokenIDStr, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	coins := make([]coin.PlainCoin, 0)
+	indices := make([]uint64, 0)
+	for i, outCoin := range outCoins {
+		coinV2, ok := outCoin.(*coin.CoinV2)
+		if !ok {
+			continue
+		}
+		plainCoin, err := coinV2.Decrypt(keySet)
+		if err != nil {
+			continue
+		}
+		coins = append(coins, plainCoin)
+		indices = append(indices, idxList[i].Uint64())
+	}
+
+	return coins, indices, nil
+}
+
+// getWatchOnlyUTXOs returns the output coins v2 of a key set without spending authority that are not known to be
+// spent, and their indices. The key images of the coins are filled from the local key image file when available.
+func getWatchOnlyUTXOs(keySet *key.KeySet, tokenIDStr string) ([]coin.PlainCoin, []uint64, error) {
+	outCoins, indices, err := getWatchOnlyOutCoins(keySet, tokenIDStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pubKeys := make([]string, 0)
+	for _, outCoin := range outCoins {
+		pubKeys = append(pubKeys, base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), common.ZeroByte))
+	}
+	pk := keySet.PaymentAddress.Pk
+	spentCoins, keyImages, err := getKnownSpentCoins(common.GetShardIDFromLastByte(pk[len(pk)-1]), tokenIDStr, pubKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	utxos := make([]coin.PlainCoin, 0)
+	utxoIndices := make([]uint64, 0)
+	for i, outCoin := range outCoins {
+		if spentCoins[pubKeys[i]] {
+			continue
+		}
+		if keyImage, ok := keyImages[pubKeys[i]]; ok {
+			keyImageBytes, _, err := base58.Base58Check{}.Decode(keyImage)
+			if err == nil {
+				if point, err := new(crypto.Point).FromBytesS(keyImageBytes); err == nil {
+					outCoin.SetKeyImage(point)
+				}
+			}
+		}
+		utxos = append(utxos, outCoin)
+		utxoIndices = append(utxoIndices, indices[i])
+	}
+
+	return utxos, utxoIndices, nil
+}
+
+// checkWatchOnlyBalance prints the balance of a watch-only account for a tokenID.
+func checkWatchOnlyBalance(c *cli.Context, keySet *key.KeySet) error {
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	utxos, _, err := getWatchOnlyUTXOs(keySet, tokenIDStr)
+	if err != nil {
+		return newAppError(GetBalanceError, err)
+	}
+	res := watchOnlyBalance{Symbol: getTokenSymbol(tokenIDStr)}
+	for _, utxo := range utxos {
+		res.Balance += utxo.GetValue()
+		if isSpentUnknown(utxo) {
+			res.SpentUnknownBalance += utxo.GetValue()
+		}
+	}
+	res.Amount = formatAmount(res.Balance, getTokenDecimals(tokenIDStr))
+	res.Unverified = res.SpentUnknownBalance > 0
+
+	return printResult(res)
+}
+
+// getWatchOnlyAllBalances prints all non-zero balances (calculated based on v2 UTXOs) of a watch-only account.
+func getWatchOnlyAllBalances(keySet *key.KeySet) error {
+	res := make(map[string]uint64)
+	spentUnknown := make(map[string]uint64)
+	addUTXO := func(tokenIDStr string, utxo coin.PlainCoin) {
+		res[tokenIDStr] += utxo.GetValue()
+		if isSpentUnknown(utxo) && utxo.GetValue() > 0 {
+			spentUnknown[tokenIDStr] += utxo.GetValue()
+		}
+	}
+
+	prvUTXOs, _, err := getWatchOnlyUTXOs(keySet, common.PRVIDStr)
+	if err != nil {
+		return newAppError(GetAllBalancesError, err)
+	}
+	for _, utxo := range prvUTXOs {
+		addUTXO(common.PRVIDStr, utxo)
+	}
+
+	tokenUTXOs, _, err := getWatchOnlyUTXOs(keySet, common.ConfidentialAssetID.String())
+	if err != nil {
+		return newAppError(GetAllBalancesError, err)
+	}
+	var rawAssetTags map[string]*common.Hash
+	if len(tokenUTXOs) > 0 {
+		rawAssetTags, err = cfg.incClient.GetAllAssetTags()
+		if err != nil {
+			return newAppError(GetAllBalancesError, err)
+		}
+	}
+	for _, utxo := range tokenUTXOs {
+		coinV2, ok := utxo.(*coin.CoinV2)
+		if !ok || utxo.GetValue() == 0 {
+			continue
+		}
+		tokenID, err := coinV2.GetTokenId(keySet, rawAssetTags)
+		if err != nil || tokenID == nil {
+			log.Printf("Cannot get the tokenID of UTXO %v: %v\n",
+				base58.Base58Check{}.Encode(utxo.GetPublicKey().ToBytesS(), common.ZeroByte), err)
+			continue
+		}
+		addUTXO(tokenID.String(), utxo)
+	}
+
+	for tokenID, balance := range res {
+		if balance == 0 {
+			delete(res, tokenID)
+		}
+	}
+
+	return printResult(watchOnlyAllBalances{
+		Balances:            res,
+		Unverified:          len(spentUnknown) > 0,
+		SpentUnknownBalance: spentUnknown,
+	})
+}
+
+// checkWatchOnlyUTXOs prints the UTXOs v2 of a watch-only account for a tokenID.
+func checkWatchOnlyUTXOs(c *cli.Context, keySet *key.KeySet) error {
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	utxos, indices, err := getWatchOnlyUTXOs(keySet, tokenIDStr)
+	if err != nil {
+		return newAppError(GetUnspentOutputCoinsError, err)
+	}
+	utxoInfos := newUTXOListResult(utxos, indices).UTXOs
+	for i, utxo := range utxos {
+		utxoInfos[i].SpentUnknown = isSpentUnknown(utxo)
+	}
+	pk := keySet.PaymentAddress.Pk
+
+	return inspectUTXOInfos(c, utxoInfos, common.GetShardIDFromLastByte(pk[len(pk)-1]), tokenIDStr)
+}
+
+// getWatchOnlyHistory prints the in-coming transactions of a watch-only account for a tokenID. Out-going
+// transactions cannot be listed without the private key.
+func getWatchOnlyHistory(c *cli.Context, keySet *key.KeySet) error {
+	tokenIDStr, err := getTokenIDFlag(c, tokenIDFlag)
+	if err != nil {
+		return newAppError(InvalidTokenIDError, err)
+	}
+
+	outCoins, _, err := getWatchOnlyOutCoins(keySet, tokenIDStr)
+	if err != nil {
+		return newAppError(GetHistoryError, err)
+	}
+	keyImages, err := loadKeyImages()
+	if err != nil {
+		return newAppError(GetHistoryError, err)
+	}
+	txIns, err := getWatchOnlyTxIns(outCoins, tokenIDStr, keyImages)
+	if err != nil {
+		return newAppError(GetHistoryError, err)
+	}
+
+	csvFile := c.String(csvFileFlag)
+	if len(csvFile) > 0 {
+		err = incclient.SaveTxHistory(&incclient.TxHistory{TxInList: txIns, TxOutList: []incclient.TxOut{}}, csvFile)
+		if err != nil {
+			return newAppError(SaveHistoryError, err)
+		}
+
+		return nil
+	}
+
+	totalIn := uint64(0)
+	for _, txIn := range txIns {
+		totalIn += txIn.GetAmount()
+	}
+
+	return printResult(struct {
+		TxIns   []incclient.TxIn
+		TotalIn uint64
+	}{txIns, totalIn})
+}
+
+// getWatchOnlyTxIns groups the given output coins by the transactions creating them. Transactions spending a coin
+// whose key image is known are considered as out-going (the received coins are their change) and skipped.
+func getWatchOnlyTxIns(outCoins []coin.PlainCoin, tokenIDStr string, keyImages map[string]string) ([]incclient.TxIn, error) {
+	res := make([]incclient.TxIn, 0)
+	coinMap := make(map[string]coin.PlainCoin)
+	pubKeys := make([]string, 0)
+	for _, outCoin := range outCoins {
+		pubKey := base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), common.ZeroByte)
+		coinMap[pubKey] = outCoin
+		pubKeys = append(pubKeys, pubKey)
+	}
+	if len(pubKeys) == 0 {
+		return res, nil
+	}
+
+	knownKeyImages := make(map[string]bool)
+	for _, keyImage := range keyImages {
+		knownKeyImages[keyImage] = true
+	}
+
+	txMap, err := cfg.incClient.GetTransactionsByPublicKeys(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	txIns := make(map[string]*incclient.TxIn)
+	txOuts := make(map[string]bool)
+	for pubKey, txs := range txMap {
+		outCoin, ok := coinMap[pubKey]
+		if !ok {
+			continue
+		}
+		for txHash, tx := range txs {
+			if txOuts[txHash] {
+				continue
+			}
+			txIn, ok := txIns[txHash]
+			if !ok {
+				if spendsKnownCoins(tx, tokenIDStr, knownKeyImages) {
+					txOuts[txHash] = true
+					continue
+				}
+				txIn = &incclient.TxIn{
+					Version:  tx.GetVersion(),
+					LockTime: tx.GetLockTime(),
+					TxHash:   txHash,
+					TokenID:  tokenIDStr,
+					Metadata: tx.GetMetadata(),
+					OutCoins: make(map[string]uint64),
+				}
+				txIns[txHash] = txIn
+			}
+			if _, ok = txIn.OutCoins[pubKey]; !ok {
+				txIn.OutCoins[pubKey] = outCoin.GetValue()
+				txIn.Amount += outCoin.GetValue()
+			}
+		}
+	}
+
+	for _, txIn := range txIns {
+		res = append(res, *txIn)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].LockTime > res[j].LockTime
+	})
+
+	return res, nil
+}
+
+// spendsKnownCoins checks if a transaction spends a coin (of the given tokenID) whose key image is known.
+func spendsKnownCoins(tx metadata.Transaction, tokenIDStr string, knownKeyImages map[string]bool) bool {
+	if len(knownKeyImages) == 0 {
+		return false
+	}
+
+	var proof privacy.Proof
+	if txToken, ok := tx.(tx_generic.TransactionToken); ok {
+		if tokenIDStr == common.PRVIDStr {
+			proof = txToken.GetTxBase().GetProof()
+		} else {
+			proof = txToken.GetTxNormal().GetProof()
+		}
+	} else if tokenIDStr == common.PRVIDStr {
+		proof = tx.GetProof()
+	}
+	if proof == nil {
+		return false
+	}
+
+	for _, inCoin := range proof.GetInputCoins() {
+		if inCoin.GetKeyImage() == nil {
+			continue
+		}
+		if knownKeyImages[base58.Base58Check{}.Encode(inCoin.GetKeyImage().ToBytesS(), common.ZeroByte)] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exportKeyImages writes the key images of all output coins v2 of an account to a file, so that a watch-only account
+// of the same account can detect the spent coins.
+func exportKeyImages(c *cli.Context) error {
+	privateKey, err := getPrivateKey(c)
+	if err != nil {
+		return err
+	}
+
+	res := make(map[string]string)
+	for _, tokenIDStr := range []string{common.PRVIDStr, common.ConfidentialAssetID.String()} {
+		outCoins, err := cfg.incClient.GetListDecryptedOutCoin(privateKey, tokenIDStr, 0)
+		if err != nil {
+			return newAppError(GetOutputCoinsError, err)
+		}
+		for keyImage, outCoin := range outCoins {
+			if outCoin.GetVersion() != 2 {
+				continue
+			}
+			res[base58.Base58Check{}.Encode(outCoin.GetPublicKey().ToBytesS(), common.ZeroByte)] = keyImage
+		}
+	}
+
+	keyImageFile := c.String(keyImageFileFlag)
+	err = writeJSONFile(keyImageFile, res)
+	if err != nil {
+		return newAppError(SaveKeyImagesError, err)
+	}
+
+	return printResult(map[string]interface{}{"KeyImageFile": keyImageFile, "NumKeyImages": len(res)})
+}
+
+// importKeyImages adds the key images exported by `account exportkeyimages` to the local key image file.
+func importKeyImages(c *cli.Context) error {
+	keyImageFile := c.String(keyImageFileFlag)
+	keyImages := make(map[string]string)
+	err := readJSONFile(keyImageFile, &keyImages)
+	if err != nil {
+		return newAppError(InvalidKeyImageFileError, err)
+	}
+	for pubKey, keyImage := range keyImages {
+		if _, _, err = (base58.Base58Check{}).Decode(keyImage); err != nil {
+			return newAppError(InvalidKeyImageFileError, fmt.Errorf("invalid key image for coin %v: %v", pubKey, err))
+		}
+	}
+
+	err = saveKeyImages(keyImages)
+	if err != nil {
+		return newAppError(SaveKeyImagesError, err)
+	}
+
+	return printResult(map[string]interface{}{"NumKeyImages": len(keyImages)})
+}